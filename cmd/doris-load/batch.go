@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// batch is a chunk of an input file loaded with one stream load
+type batch struct {
+	source string // File name, "-" for stdin
+	seq    int    // Position of the batch in the whole run, used to order results
+	index  int    // Position of the batch in its source
+	rows   int64
+	size   int64 // Uncompressed size
+	data   []byte
+}
+
+// splitter cuts an input into batches of about batchSize bytes at line delimiters
+// A batchSize of 0 or less keeps the whole input in one batch, which formats that cannot be
+// split (JSON arrays) rely on.
+type splitter struct {
+	reader    *bufio.Reader
+	delimiter []byte
+	batchSize int64
+	eof       bool
+}
+
+// newSplitter creates a splitter of r cutting after delimiter
+func newSplitter(r io.Reader, delimiter string, batchSize int64) *splitter {
+	if delimiter == "" {
+		delimiter = "\n"
+	}
+	return &splitter{reader: bufio.NewReaderSize(r, 1<<20), delimiter: []byte(delimiter), batchSize: batchSize}
+}
+
+// next returns the data and row count of the next batch, or io.EOF when the input is exhausted
+// A row longer than batchSize makes a batch of its own.
+func (s *splitter) next() ([]byte, int64, error) {
+	if s.eof {
+		return nil, 0, io.EOF
+	}
+
+	if s.batchSize <= 0 {
+		data, err := io.ReadAll(s.reader)
+		s.eof = true
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(data) == 0 {
+			return nil, 0, io.EOF
+		}
+		return data, countRows(data, s.delimiter), nil
+	}
+
+	var buf bytes.Buffer
+	var rows int64
+	last := s.delimiter[len(s.delimiter)-1]
+	for int64(buf.Len()) < s.batchSize {
+		row, err := s.readRow(last)
+		if len(row) > 0 {
+			buf.Write(row)
+			rows++
+		}
+		if err == io.EOF {
+			s.eof = true
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if buf.Len() == 0 {
+		return nil, 0, io.EOF
+	}
+	return buf.Bytes(), rows, nil
+}
+
+// readRow reads up to and including the next delimiter
+func (s *splitter) readRow(last byte) ([]byte, error) {
+	var row []byte
+	for {
+		chunk, err := s.reader.ReadSlice(last)
+		row = append(row, chunk...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil || bytes.HasSuffix(row, s.delimiter) {
+			return row, err
+		}
+	}
+}
+
+// countRows counts the rows of data, including an unterminated last row
+func countRows(data []byte, delimiter []byte) int64 {
+	rows := int64(bytes.Count(data, delimiter))
+	if len(data) > 0 && !bytes.HasSuffix(data, delimiter) {
+		rows++
+	}
+	return rows
+}
+
+// compress encodes data with the given compress_type; "" leaves it unchanged
+func compress(compressType string, data []byte) ([]byte, error) {
+	switch compressType {
+	case "":
+		return data, nil
+	case "gz":
+		var buf bytes.Buffer
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(data); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q, use gz", compressType)
+	}
+}
+
+// parseSize parses a size such as "64MB", "512KiB" or "1048576"
+func parseSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+		{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// formatBytes formats a byte count for humans
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
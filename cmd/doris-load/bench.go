@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+const benchUsage = `bench [flags]
+
+Loads synthetic rows for -schema until -size bytes are sent, once for every
+combination of -concurrency, -batch-size and -compress, and reports throughput,
+client latency percentiles and the average server-side phase timings of each run.
+With -mock the benchmark runs against an in-process fake Doris instead of -endpoints.
+
+Every batch of a run carries the same generated data, so the generator and the
+compression do not count in the measured time.`
+
+// benchFlags are the flags of the bench command
+type benchFlags struct {
+	connectionFlags
+
+	schema      string
+	format      string
+	size        string
+	concurrency string
+	batchSizes  string
+	compress    string
+	groupCommit string
+	seed        int64
+	mock        bool
+	output      string
+	progress    bool
+}
+
+// benchRun is one combination of the swept parameters
+type benchRun struct {
+	concurrency int
+	batchSize   int64
+	compress    string
+}
+
+// benchResult is the report of one run
+type benchResult struct {
+	Concurrency   int     `json:"concurrency"`
+	BatchBytes    int64   `json:"batch_bytes"`
+	Compress      string  `json:"compress"`
+	Batches       int     `json:"batches"`
+	Failed        int     `json:"failed"`
+	Rows          int64   `json:"rows"`
+	Bytes         int64   `json:"bytes"`
+	SentBytes     int64   `json:"sent_bytes"`
+	ElapsedMs     int64   `json:"elapsed_ms"`
+	RowsPerSecond float64 `json:"rows_per_second"`
+	MBPerSecond   float64 `json:"mb_per_second"`
+	LatencyP50Ms  float64 `json:"latency_p50_ms"`
+	LatencyP99Ms  float64 `json:"latency_p99_ms"`
+
+	// Average server-side timings reported in the stream load responses
+	LoadTimeMs             float64 `json:"load_time_ms"`
+	BeginTxnTimeMs         float64 `json:"begin_txn_time_ms"`
+	StreamLoadPutTimeMs    float64 `json:"stream_load_put_time_ms"`
+	ReadDataTimeMs         float64 `json:"read_data_time_ms"`
+	WriteDataTimeMs        float64 `json:"write_data_time_ms"`
+	CommitAndPublishTimeMs float64 `json:"commit_and_publish_time_ms"`
+}
+
+// runBench implements the bench command
+func runBench(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	f := &benchFlags{}
+	fs := newFlagSet("bench", benchUsage, stderr)
+	f.register(fs)
+	fs.StringVar(&f.schema, "schema", defaultBenchSchema, "columns as name:type, types int, bigint, double, decimal, string(N), date, datetime, bool")
+	fs.StringVar(&f.format, "format", "csv", "generated format: csv or json")
+	fs.StringVar(&f.size, "size", "256MB", "uncompressed bytes loaded by each run")
+	fs.StringVar(&f.concurrency, "concurrency", "1,4,8", "comma-separated concurrent loads to sweep")
+	fs.StringVar(&f.batchSizes, "batch-size", "16MB", "comma-separated batch sizes to sweep")
+	fs.StringVar(&f.compress, "compress", "none", "comma-separated compressions to sweep: none, gz")
+	fs.StringVar(&f.groupCommit, "group-commit", "off", "group commit mode: off, sync or async")
+	fs.Int64Var(&f.seed, "seed", 1, "seed of the data generator")
+	fs.BoolVar(&f.mock, "mock", false, "run against an in-process fake Doris")
+	fs.StringVar(&f.output, "output", "table", "report format: table, json or csv")
+	fs.BoolVar(&f.progress, "progress", isTerminal(stderr), "report each run on stderr")
+
+	set, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	runs, size, err := f.plan()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return errUsage
+	}
+	columns, err := parseSchema(f.schema)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid -schema: %v\n", err)
+		return errUsage
+	}
+	if f.output != "table" && f.output != "json" && f.output != "csv" {
+		fmt.Fprintf(stderr, "invalid -output %q, use table, json or csv\n", f.output)
+		return errUsage
+	}
+	if f.format != "csv" && f.format != "json" {
+		fmt.Fprintf(stderr, "invalid -format %q, use csv or json\n", f.format)
+		return errUsage
+	}
+	mode, err := parseGroupCommit(f.groupCommit)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return errUsage
+	}
+
+	opts := f.options(set)
+	if f.mock {
+		server := dorismock.NewServer()
+		defer server.Close()
+		server.DiscardRows(true)
+		opts = append(opts, doris.WithEndpoints(server.URL()), doris.WithAuth("root", "mock"),
+			doris.WithDatabase("bench"), doris.WithTable("bench"))
+	}
+	if f.format == "json" {
+		opts = append(opts, doris.WithJSON(doris.JSONObjectLine))
+	} else {
+		opts = append(opts, doris.WithCSV(",", "\\n"))
+	}
+	opts = append(opts, doris.WithGroupCommit(mode), doris.WithLabelPrefix("bench"))
+
+	cfg, err := f.buildConfig(opts)
+	if err != nil {
+		return err
+	}
+	f.setupLogging(stderr)
+	client, err := doris.NewLoadClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	var results []benchResult
+	for i, run := range runs {
+		if ctx.Err() != nil {
+			break
+		}
+		data, rows := newRowGenerator(columns, f.format == "json", f.seed).batch(run.batchSize)
+		result, err := runBenchmark(ctx, client, run, data, rows, size)
+		if err != nil {
+			return err
+		}
+		results = append(results, result)
+
+		if f.progress {
+			fmt.Fprintf(stderr, "run %d/%d: concurrency %d, batch %s, compress %s: %.1f MB/s, p99 %.0f ms, %d failed\n",
+				i+1, len(runs), run.concurrency, formatBytes(run.batchSize), run.compress,
+				result.MBPerSecond, result.LatencyP99Ms, result.Failed)
+		}
+	}
+
+	switch f.output {
+	case "json":
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	case "csv":
+		if err := writeBenchCSV(stdout, results); err != nil {
+			return err
+		}
+	default:
+		writeBenchTable(stdout, results)
+	}
+	return ctx.Err()
+}
+
+// plan expands the swept flags into runs and parses the size of a run
+func (f *benchFlags) plan() ([]benchRun, int64, error) {
+	size, err := parseSize(f.size)
+	if err != nil || size <= 0 {
+		return nil, 0, fmt.Errorf("invalid -size %q", f.size)
+	}
+
+	var concurrencies []int
+	for _, item := range splitList(f.concurrency) {
+		n, err := strconv.Atoi(item)
+		if err != nil || n < 1 {
+			return nil, 0, fmt.Errorf("invalid -concurrency %q", item)
+		}
+		concurrencies = append(concurrencies, n)
+	}
+
+	var batchSizes []int64
+	for _, item := range splitList(f.batchSizes) {
+		n, err := parseSize(item)
+		if err != nil || n <= 0 {
+			return nil, 0, fmt.Errorf("invalid -batch-size %q", item)
+		}
+		batchSizes = append(batchSizes, n)
+	}
+
+	var compressions []string
+	for _, item := range splitList(f.compress) {
+		switch item {
+		case "none":
+			compressions = append(compressions, "")
+		case "gz":
+			compressions = append(compressions, item)
+		default:
+			return nil, 0, fmt.Errorf("invalid -compress %q, use none or gz", item)
+		}
+	}
+
+	var runs []benchRun
+	for _, batchSize := range batchSizes {
+		for _, compress := range compressions {
+			for _, concurrency := range concurrencies {
+				runs = append(runs, benchRun{concurrency: concurrency, batchSize: batchSize, compress: compress})
+			}
+		}
+	}
+	if len(runs) == 0 {
+		return nil, 0, fmt.Errorf("nothing to run, -concurrency, -batch-size and -compress need at least one value")
+	}
+	return runs, size, nil
+}
+
+// runBenchmark loads data repeatedly until size bytes are sent and measures the run
+func runBenchmark(ctx context.Context, client *doris.DorisLoadClient, run benchRun, data []byte, rows int64, size int64) (benchResult, error) {
+	payload, err := compress(run.compress, data)
+	if err != nil {
+		return benchResult{}, err
+	}
+
+	batches := int((size + int64(len(data)) - 1) / int64(len(data)))
+	var opts []doris.LoadOption
+	if run.compress != "" {
+		opts = append(opts, doris.WithOption("compress_type", run.compress))
+	}
+
+	result := benchResult{Concurrency: run.concurrency, BatchBytes: run.batchSize, Compress: run.compress}
+	if result.Compress == "" {
+		result.Compress = "none"
+	}
+
+	var mu sync.Mutex
+	var latencies []time.Duration
+	var responses []doris.RespContent
+
+	next := make(chan struct{})
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < run.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range next {
+				loadStart := time.Now()
+				response, err := client.LoadContext(ctx, bytes.NewReader(payload), opts...)
+				latency := time.Since(loadStart)
+
+				mu.Lock()
+				if err != nil {
+					result.Failed++
+				} else {
+					latencies = append(latencies, latency)
+					responses = append(responses, response.Resp)
+					result.Rows += rows
+					result.Bytes += int64(len(data))
+					result.SentBytes += int64(len(payload))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < batches; i++ {
+		select {
+		case next <- struct{}{}:
+			result.Batches++
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(next)
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result.ElapsedMs = elapsed.Milliseconds()
+	if seconds := elapsed.Seconds(); seconds > 0 {
+		result.RowsPerSecond = float64(result.Rows) / seconds
+		result.MBPerSecond = float64(result.Bytes) / (1 << 20) / seconds
+	}
+	result.LatencyP50Ms = percentileMs(latencies, 0.50)
+	result.LatencyP99Ms = percentileMs(latencies, 0.99)
+
+	if n := float64(len(responses)); n > 0 {
+		for _, resp := range responses {
+			result.LoadTimeMs += float64(resp.LoadTimeMs) / n
+			result.BeginTxnTimeMs += float64(resp.BeginTxnTimeMs) / n
+			result.StreamLoadPutTimeMs += float64(resp.StreamLoadPutTimeMs) / n
+			result.ReadDataTimeMs += float64(resp.ReadDataTimeMs) / n
+			result.WriteDataTimeMs += float64(resp.WriteDataTimeMs) / n
+			result.CommitAndPublishTimeMs += float64(resp.CommitAndPublishTimeMs) / n
+		}
+	}
+	return result, nil
+}
+
+// percentileMs returns the q quantile of latencies in milliseconds using the nearest rank
+func percentileMs(latencies []time.Duration, q float64) float64 {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return float64(sorted[rank]) / float64(time.Millisecond)
+}
+
+// benchColumns are the CSV and table columns of a report
+var benchColumns = []string{
+	"concurrency", "batch_bytes", "compress", "batches", "failed", "rows", "bytes", "sent_bytes", "elapsed_ms",
+	"rows_per_second", "mb_per_second", "latency_p50_ms", "latency_p99_ms", "load_time_ms", "begin_txn_time_ms",
+	"stream_load_put_time_ms", "read_data_time_ms", "write_data_time_ms", "commit_and_publish_time_ms",
+}
+
+// fields returns the values of r in the order of benchColumns
+func (r benchResult) fields() []string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) }
+	return []string{
+		strconv.Itoa(r.Concurrency), strconv.FormatInt(r.BatchBytes, 10), r.Compress, strconv.Itoa(r.Batches),
+		strconv.Itoa(r.Failed), strconv.FormatInt(r.Rows, 10), strconv.FormatInt(r.Bytes, 10),
+		strconv.FormatInt(r.SentBytes, 10), strconv.FormatInt(r.ElapsedMs, 10),
+		f(r.RowsPerSecond), f(r.MBPerSecond), f(r.LatencyP50Ms), f(r.LatencyP99Ms), f(r.LoadTimeMs), f(r.BeginTxnTimeMs),
+		f(r.StreamLoadPutTimeMs), f(r.ReadDataTimeMs), f(r.WriteDataTimeMs), f(r.CommitAndPublishTimeMs),
+	}
+}
+
+// writeBenchCSV prints one CSV record per run with a header
+func writeBenchCSV(w io.Writer, results []benchResult) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(benchColumns); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := writer.Write(r.fields()); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeBenchTable prints the main figures of each run
+func writeBenchTable(w io.Writer, results []benchResult) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "CONCURRENCY\tBATCH\tCOMPRESS\tBATCHES\tFAILED\tROWS/s\tMB/s\tP50(ms)\tP99(ms)\tSERVER LOAD(ms)\tREAD(ms)\tWRITE(ms)\tCOMMIT(ms)\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%d\t%.0f\t%.1f\t%.0f\t%.0f\t%.0f\t%.0f\t%.0f\t%.0f\t\n",
+			r.Concurrency, formatBytes(r.BatchBytes), r.Compress, r.Batches, r.Failed, r.RowsPerSecond, r.MBPerSecond,
+			r.LatencyP50Ms, r.LatencyP99Ms, r.LoadTimeMs, r.ReadDataTimeMs, r.WriteDataTimeMs, r.CommitAndPublishTimeMs)
+	}
+	tw.Flush()
+}
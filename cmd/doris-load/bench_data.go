@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultBenchSchema matches the orders table of the former performance_test program
+const defaultBenchSchema = "order_id:bigint,customer:string(16),product:string(16),category:string(12)," +
+	"quantity:int,price:decimal,status:string(8),order_date:date,region:string(10)"
+
+// column is a column of a synthetic schema
+type column struct {
+	name   string
+	kind   string // int, bigint, double, decimal, string, date, datetime or bool
+	length int    // Length of string values
+}
+
+// parseSchema parses "name:type,..." where type is int, bigint, double, decimal, string(N), date, datetime or bool
+func parseSchema(schema string) ([]column, error) {
+	var columns []column
+	for _, field := range splitList(schema) {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid column %q, expected name:type", field)
+		}
+
+		col := column{name: strings.TrimSpace(parts[0]), kind: strings.ToLower(strings.TrimSpace(parts[1]))}
+		if open := strings.Index(col.kind, "("); open >= 0 && strings.HasSuffix(col.kind, ")") {
+			length, err := strconv.Atoi(col.kind[open+1 : len(col.kind)-1])
+			if err != nil || length <= 0 {
+				return nil, fmt.Errorf("invalid length in column %q", field)
+			}
+			col.kind, col.length = col.kind[:open], length
+		}
+
+		switch col.kind {
+		case "varchar", "char", "text":
+			col.kind = "string"
+		case "tinyint", "smallint":
+			col.kind = "int"
+		case "float":
+			col.kind = "double"
+		}
+		switch col.kind {
+		case "string":
+			if col.length == 0 {
+				col.length = 16
+			}
+		case "int", "bigint", "double", "decimal", "date", "datetime", "bool":
+			if col.length != 0 {
+				return nil, fmt.Errorf("column %q: only string types take a length", field)
+			}
+		default:
+			return nil, fmt.Errorf("column %q: unknown type %q", field, col.kind)
+		}
+		columns = append(columns, col)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("schema has no columns")
+	}
+	return columns, nil
+}
+
+// rowGenerator produces synthetic rows for a schema
+type rowGenerator struct {
+	columns []column
+	json    bool
+	rng     *rand.Rand
+	next    int64      // Value of the next integer key
+	words   [][]string // Values of each string column
+	epoch   time.Time
+}
+
+// stringCardinality is the number of distinct values of a string column, which keeps compression realistic
+const stringCardinality = 1000
+
+// newRowGenerator creates a generator of CSV rows, or JSON lines if json is set
+func newRowGenerator(columns []column, json bool, seed int64) *rowGenerator {
+	g := &rowGenerator{
+		columns: columns,
+		json:    json,
+		rng:     rand.New(rand.NewSource(seed)),
+		words:   make([][]string, len(columns)),
+		epoch:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	for i, col := range columns {
+		if col.kind == "string" {
+			g.words[i] = make([]string, stringCardinality)
+			for j := range g.words[i] {
+				g.words[i][j] = g.randomString(col.length)
+			}
+		}
+	}
+	return g
+}
+
+// batch generates rows until the batch reaches size bytes and returns it with its row count
+func (g *rowGenerator) batch(size int64) ([]byte, int64) {
+	var buf bytes.Buffer
+	buf.Grow(int(size) + 1024)
+
+	var rows int64
+	for int64(buf.Len()) < size {
+		g.appendRow(&buf)
+		rows++
+	}
+	return buf.Bytes(), rows
+}
+
+// appendRow writes one row terminated by "\n"
+func (g *rowGenerator) appendRow(buf *bytes.Buffer) {
+	key := g.next
+	g.next++
+
+	if g.json {
+		buf.WriteByte('{')
+	}
+	for i, col := range g.columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if g.json {
+			buf.WriteString(strconv.Quote(col.name))
+			buf.WriteByte(':')
+		}
+
+		value, quoted := g.value(i, col, key)
+		if g.json && quoted {
+			buf.WriteByte('"')
+			buf.WriteString(value)
+			buf.WriteByte('"')
+		} else {
+			buf.WriteString(value)
+		}
+	}
+	if g.json {
+		buf.WriteByte('}')
+	}
+	buf.WriteByte('\n')
+}
+
+// value returns a random value for col and whether JSON needs it quoted
+// The first column, if it is an integer, is a sequential key so rows stay distinct.
+func (g *rowGenerator) value(index int, col column, key int64) (string, bool) {
+	first := index == 0
+	switch col.kind {
+	case "int":
+		if first {
+			return strconv.FormatInt(key, 10), false
+		}
+		return strconv.Itoa(g.rng.Intn(1000)), false
+	case "bigint":
+		if first {
+			return strconv.FormatInt(key, 10), false
+		}
+		return strconv.FormatInt(g.rng.Int63(), 10), false
+	case "double":
+		return strconv.FormatFloat(g.rng.Float64()*1000, 'f', 4, 64), false
+	case "decimal":
+		return fmt.Sprintf("%d.%02d", g.rng.Intn(10000), g.rng.Intn(100)), false
+	case "date":
+		return g.epoch.AddDate(0, 0, g.rng.Intn(365)).Format("2006-01-02"), true
+	case "datetime":
+		return g.epoch.Add(time.Duration(g.rng.Int63n(int64(365 * 24 * time.Hour)))).Format("2006-01-02 15:04:05"), true
+	case "bool":
+		return strconv.FormatBool(g.rng.Intn(2) == 1), false
+	default:
+		words := g.words[index]
+		return words[g.rng.Intn(len(words))], true
+	}
+}
+
+// randomString returns n random lowercase letters and digits
+func (g *rowGenerator) randomString(n int) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[g.rng.Intn(len(alphabet))]
+	}
+	return string(b)
+}
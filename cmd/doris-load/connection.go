@@ -0,0 +1,163 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bingquanzhao/go-doris-sdk"
+)
+
+// connectionFlags are the flags shared by every command talking to Doris
+type connectionFlags struct {
+	configFile string
+	endpoints  string
+	user       string
+	password   string
+	database   string
+	table      string
+	verbose    bool
+}
+
+// register adds the connection flags to fs
+func (f *connectionFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.configFile, "config", "", "YAML or JSON configuration file")
+	fs.StringVar(&f.endpoints, "endpoints", "", "comma-separated FE HTTP endpoints, e.g. http://fe1:8030")
+	fs.StringVar(&f.user, "user", "", "user name")
+	fs.StringVar(&f.password, "password", "", "password (default $DORIS_PASSWORD)")
+	fs.StringVar(&f.database, "db", "", "target database")
+	fs.StringVar(&f.table, "table", "", "target table")
+	fs.BoolVar(&f.verbose, "v", false, "print SDK logs to stderr")
+}
+
+// options returns the config options of the connection flags set on the command line
+func (f *connectionFlags) options(set map[string]bool) []doris.Option {
+	var opts []doris.Option
+	if set["endpoints"] {
+		opts = append(opts, doris.WithEndpoints(splitList(f.endpoints)...))
+	}
+	if set["user"] || set["password"] {
+		password := f.password
+		if !set["password"] {
+			password = os.Getenv("DORIS_PASSWORD")
+		}
+		opts = append(opts, doris.WithAuth(f.user, password))
+	}
+	if set["db"] {
+		opts = append(opts, doris.WithDatabase(f.database))
+	}
+	if set["table"] {
+		opts = append(opts, doris.WithTable(f.table))
+	}
+	return opts
+}
+
+// buildConfig reads the configuration file, if any, and applies opts over it
+func (f *connectionFlags) buildConfig(opts []doris.Option) (*doris.Config, error) {
+	if f.configFile == "" {
+		return doris.NewConfig(opts...)
+	}
+
+	cfg, err := doris.LoadConfigFile(f.configFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if err := cfg.ValidateInternal(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// newAPIClient creates a client for the FE APIs working on a database, which need no target table
+func (f *connectionFlags) newAPIClient(set map[string]bool, stderr io.Writer) (*doris.DorisLoadClient, error) {
+	opts := append(f.options(set), func(c *doris.Config) {
+		if c.Table == "" {
+			// Only validation requires a table, label and transaction APIs never use it
+			c.Table = "_"
+		}
+	})
+	cfg, err := f.buildConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	f.setupLogging(stderr)
+	return doris.NewLoadClient(cfg)
+}
+
+// setupLogging hides SDK logs, which the commands summarize themselves, unless -v sends them to stderr
+// Stdout is kept for results either way.
+func (f *connectionFlags) setupLogging(stderr io.Writer) {
+	file, ok := stderr.(*os.File)
+	if !f.verbose || !ok {
+		doris.DisableLogging()
+		return
+	}
+	doris.SetLogOutput(file)
+	doris.SetLogLevel(doris.LogLevelInfo)
+}
+
+// parseFlags parses args with fs and returns the names of the flags set on the command line
+func parseFlags(fs *flag.FlagSet, args []string) (map[string]bool, error) {
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil, err
+		}
+		return nil, errUsage
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	return set, nil
+}
+
+// newFlagSet creates the flag set of a command writing errors and usage to stderr
+func newFlagSet(name, usage string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: doris-load %s\n\nFlags:\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// headerFlags collects repeated -header key=value flags
+type headerFlags map[string]string
+
+// String implements flag.Value
+func (h headerFlags) String() string {
+	pairs := make([]string, 0, len(h))
+	for k, v := range h {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set implements flag.Value
+func (h headerFlags) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	h[strings.TrimSpace(parts[0])] = parts[1]
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/bingquanzhao/go-doris-sdk"
+)
+
+const errorsUsage = `errors [flags] ERROR_URL
+
+Downloads the error log at ERROR_URL, the ErrorURL of a failed load, and prints
+the rejected rows with the reason Doris gave for each.`
+
+// runErrors implements the errors command
+func runErrors(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var limit int
+	var output string
+	var verbose bool
+	fs := newFlagSet("errors", errorsUsage, stderr)
+	fs.IntVar(&limit, "limit", 0, "maximum number of rows to print, 0 for all")
+	fs.StringVar(&output, "output", "table", "result format: table or json")
+	fs.BoolVar(&verbose, "v", false, "print SDK logs to stderr")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	(&connectionFlags{verbose: verbose}).setupLogging(stderr)
+	rows, err := doris.FetchErrorLog(fs.Arg(0), limit)
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		type rejectedRow struct {
+			Reason string `json:"reason"`
+			Row    string `json:"row"`
+		}
+		out := make([]rejectedRow, len(rows))
+		for i, row := range rows {
+			out[i] = rejectedRow{Reason: row.Reason, Row: row.Row}
+		}
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(out)
+	}
+
+	for i, row := range rows {
+		fmt.Fprintf(stdout, "#%d %s\n", i+1, row.Reason)
+		if row.Row != "" {
+			fmt.Fprintf(stdout, "   row: %s\n", row.Row)
+		}
+	}
+	fmt.Fprintf(stdout, "%d rejected rows\n", len(rows))
+	return nil
+}
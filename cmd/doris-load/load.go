@@ -0,0 +1,463 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk"
+)
+
+const loadUsage = `load [flags] [FILE|GLOB|-]...
+
+Loads each file, glob match or stdin ("-", the default without arguments) into the table.
+Inputs are cut at line delimiters into batches of about -batch-size bytes which are
+uploaded in parallel, one stream load per batch. JSON arrays are loaded whole.`
+
+// loadFlags are the flags of the load command
+type loadFlags struct {
+	connectionFlags
+
+	format          string
+	columnSeparator string
+	lineDelimiter   string
+	jsonType        string
+	columns         string
+	compress        string
+	retries         int
+	groupCommit     string
+	label           string
+	labelPrefix     string
+	headers         headerFlags
+
+	batchSize       string
+	parallel        int
+	output          string
+	progress        bool
+	continueOnError bool
+}
+
+// batchResult is the outcome of loading one batch
+type batchResult struct {
+	File     string             `json:"file"`
+	Batch    int                `json:"batch"`
+	Rows     int64              `json:"rows"`
+	Bytes    int64              `json:"bytes"`
+	Status   string             `json:"status"`
+	Error    string             `json:"error,omitempty"`
+	Response *doris.RespContent `json:"response,omitempty"`
+
+	seq int
+}
+
+// runLoad implements the load command
+func runLoad(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	f := &loadFlags{headers: headerFlags{}}
+	fs := newFlagSet("load", loadUsage, stderr)
+	f.register(fs)
+	fs.StringVar(&f.format, "format", "", "data format: csv or json (default from -config, else csv)")
+	fs.StringVar(&f.columnSeparator, "column-separator", "", `CSV column separator, raw or escaped like "\t" or "\x01" (default ",")`)
+	fs.StringVar(&f.lineDelimiter, "line-delimiter", "", `CSV line delimiter, raw or escaped (default "\n")`)
+	fs.StringVar(&f.jsonType, "json-type", "", "JSON layout: object_line or array (default object_line)")
+	fs.StringVar(&f.columns, "columns", "", "column mapping, e.g. id,name,age=age+1")
+	fs.StringVar(&f.compress, "compress", "", "compress batches before upload: gz")
+	fs.IntVar(&f.retries, "retries", 0, "maximum retries of a batch (default from -config, else 6)")
+	fs.StringVar(&f.groupCommit, "group-commit", "", "group commit mode: off, sync or async")
+	fs.StringVar(&f.label, "label", "", "label of the load; batch N is labeled LABEL_N")
+	fs.StringVar(&f.labelPrefix, "label-prefix", "", "prefix of generated labels")
+	fs.Var(f.headers, "header", "extra stream load header key=value, repeatable")
+	fs.StringVar(&f.batchSize, "batch-size", "100MB", "approximate uncompressed size of a batch, 0 for no splitting")
+	fs.IntVar(&f.parallel, "parallel", 4, "number of batches uploaded concurrently")
+	fs.StringVar(&f.output, "output", "table", "result format: table or json")
+	fs.BoolVar(&f.progress, "progress", isTerminal(stderr), "show progress on stderr")
+	fs.BoolVar(&f.continueOnError, "continue-on-error", false, "keep loading remaining batches after a failure")
+
+	set, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if f.output != "table" && f.output != "json" {
+		fmt.Fprintf(stderr, "invalid -output %q, use table or json\n", f.output)
+		return errUsage
+	}
+	if f.parallel < 1 {
+		fmt.Fprintln(stderr, "-parallel must be at least 1")
+		return errUsage
+	}
+	if f.compress != "" && f.compress != "gz" {
+		fmt.Fprintf(stderr, "invalid -compress %q, use gz\n", f.compress)
+		return errUsage
+	}
+	batchSize, err := parseSize(f.batchSize)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid -batch-size: %v\n", err)
+		return errUsage
+	}
+
+	opts, err := f.options(set)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return errUsage
+	}
+	cfg, err := f.buildConfig(opts)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := resolveInputs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	f.setupLogging(stderr)
+	client, err := doris.NewLoadClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	delimiter := "\n"
+	if csv, ok := cfg.Format.(*doris.CSVFormat); ok {
+		delimiter = doris.UnescapeDelimiter(csv.LineDelimiter)
+	} else if json, ok := cfg.Format.(*doris.JSONFormat); ok && json.Type == doris.JSONArray {
+		batchSize = 0
+	}
+
+	l := &fileLoader{
+		client:          client,
+		stdin:           stdin,
+		delimiter:       delimiter,
+		batchSize:       batchSize,
+		parallel:        f.parallel,
+		compress:        f.compress,
+		label:           cfg.Label,
+		continueOnError: f.continueOnError,
+	}
+	if f.progress {
+		l.progress = newProgress(stderr, totalSize(inputs))
+	}
+
+	start := time.Now()
+	results, err := l.run(ctx, inputs)
+	elapsed := time.Since(start)
+	if err != nil {
+		return err
+	}
+
+	summary := summarize(results, len(inputs), elapsed)
+	if f.output == "json" {
+		if err := writeJSON(stdout, results, summary); err != nil {
+			return err
+		}
+	} else {
+		writeTable(stdout, results, summary)
+	}
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d of %d batches failed", summary.Failed, summary.Batches)
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// options returns the config options of the flags set on the command line
+func (f *loadFlags) options(set map[string]bool) ([]doris.Option, error) {
+	opts := f.connectionFlags.options(set)
+
+	if set["format"] || set["column-separator"] || set["line-delimiter"] || set["json-type"] {
+		format, err := f.buildFormat(set)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, doris.WithFormat(format))
+	}
+	if set["columns"] {
+		opts = append(opts, doris.WithColumns(splitList(f.columns)...))
+	}
+	if f.compress != "" {
+		opts = append(opts, doris.WithOption("compress_type", f.compress))
+	}
+	if set["retries"] {
+		retries := f.retries
+		opts = append(opts, func(c *doris.Config) {
+			retry := doris.DefaultRetry()
+			if c.Retry != nil {
+				copied := *c.Retry
+				retry = &copied
+			}
+			retry.MaxRetryTimes = retries
+			c.Retry = retry
+		})
+	}
+	if set["group-commit"] {
+		mode, err := parseGroupCommit(f.groupCommit)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, doris.WithGroupCommit(mode))
+	}
+	if set["label"] {
+		opts = append(opts, doris.WithLabel(f.label))
+	}
+	if set["label-prefix"] {
+		opts = append(opts, doris.WithLabelPrefix(f.labelPrefix))
+	}
+	if len(f.headers) > 0 {
+		opts = append(opts, doris.WithHeaders(f.headers))
+	}
+	return opts, nil
+}
+
+// buildFormat creates the format described by the format flags
+func (f *loadFlags) buildFormat(set map[string]bool) (doris.Format, error) {
+	name := strings.ToLower(f.format)
+	if name == "" {
+		name = "csv"
+		if set["json-type"] {
+			name = "json"
+		}
+	}
+
+	switch name {
+	case "csv":
+		if set["json-type"] {
+			return nil, errors.New("-json-type only applies to -format json")
+		}
+		format := &doris.CSVFormat{ColumnSeparator: f.columnSeparator, LineDelimiter: f.lineDelimiter}
+		if format.ColumnSeparator == "" {
+			format.ColumnSeparator = ","
+		}
+		if format.LineDelimiter == "" {
+			format.LineDelimiter = "\\n"
+		}
+		return format, nil
+	case "json":
+		if set["column-separator"] || set["line-delimiter"] {
+			return nil, errors.New("-column-separator and -line-delimiter only apply to -format csv")
+		}
+		switch strings.ToLower(f.jsonType) {
+		case "", "object_line":
+			return &doris.JSONFormat{Type: doris.JSONObjectLine}, nil
+		case "array":
+			return &doris.JSONFormat{Type: doris.JSONArray}, nil
+		default:
+			return nil, fmt.Errorf("invalid -json-type %q, use object_line or array", f.jsonType)
+		}
+	default:
+		return nil, fmt.Errorf("invalid -format %q, use csv or json", f.format)
+	}
+}
+
+// parseGroupCommit parses a group commit mode flag
+func parseGroupCommit(mode string) (doris.GroupCommitMode, error) {
+	switch strings.ToLower(mode) {
+	case "off":
+		return doris.OFF, nil
+	case "sync":
+		return doris.SYNC, nil
+	case "async":
+		return doris.ASYNC, nil
+	default:
+		return doris.OFF, fmt.Errorf("invalid -group-commit %q, use off, sync or async", mode)
+	}
+}
+
+// resolveInputs expands globs; no arguments means stdin
+func resolveInputs(args []string) ([]string, error) {
+	if len(args) == 0 {
+		return []string{"-"}, nil
+	}
+
+	var inputs []string
+	for _, arg := range args {
+		if arg == "-" || !strings.ContainsAny(arg, "*?[") {
+			inputs = append(inputs, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", arg)
+		}
+		inputs = append(inputs, matches...)
+	}
+	return inputs, nil
+}
+
+// totalSize returns the combined size of the input files, 0 if unknown (stdin)
+func totalSize(inputs []string) int64 {
+	var total int64
+	for _, input := range inputs {
+		if input == "-" {
+			return 0
+		}
+		info, err := os.Stat(input)
+		if err != nil {
+			return 0
+		}
+		total += info.Size()
+	}
+	return total
+}
+
+// fileLoader splits inputs into batches and uploads them in parallel
+type fileLoader struct {
+	client          *doris.DorisLoadClient
+	stdin           io.Reader
+	delimiter       string
+	batchSize       int64
+	parallel        int
+	compress        string
+	label           string
+	continueOnError bool
+	progress        *progress
+
+	mu      sync.Mutex
+	results []batchResult
+	failed  bool
+}
+
+// run loads inputs and returns the result of every batch sent, in input order
+func (l *fileLoader) run(ctx context.Context, inputs []string) ([]batchResult, error) {
+	batches := make(chan *batch, l.parallel)
+
+	var wg sync.WaitGroup
+	for i := 0; i < l.parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for b := range batches {
+				l.record(l.loadBatch(ctx, b))
+			}
+		}()
+	}
+
+	err := l.split(ctx, inputs, batches)
+	close(batches)
+	wg.Wait()
+	if l.progress != nil {
+		l.progress.stop()
+	}
+
+	sortResults(l.results)
+	return l.results, err
+}
+
+// split reads the inputs one after another and queues their batches
+func (l *fileLoader) split(ctx context.Context, inputs []string, batches chan<- *batch) error {
+	seq := 0
+	for _, input := range inputs {
+		reader, closeInput, err := l.open(input)
+		if err != nil {
+			return err
+		}
+
+		s := newSplitter(reader, l.delimiter, l.batchSize)
+		for index := 0; ; index++ {
+			if ctx.Err() != nil || l.stopped() {
+				closeInput()
+				return nil
+			}
+
+			data, rows, err := s.next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				closeInput()
+				return fmt.Errorf("failed to read %s: %w", input, err)
+			}
+
+			b := &batch{source: input, seq: seq, index: index, rows: rows, size: int64(len(data)), data: data}
+			seq++
+			select {
+			case batches <- b:
+			case <-ctx.Done():
+				closeInput()
+				return nil
+			}
+		}
+		closeInput()
+	}
+	return nil
+}
+
+// open opens an input file, "-" being stdin
+func (l *fileLoader) open(input string) (io.Reader, func(), error) {
+	if input == "-" {
+		return l.stdin, func() {}, nil
+	}
+	file, err := os.Open(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, func() { file.Close() }, nil
+}
+
+// loadBatch uploads one batch
+func (l *fileLoader) loadBatch(ctx context.Context, b *batch) batchResult {
+	result := batchResult{File: b.source, Batch: b.index, Rows: b.rows, Bytes: b.size, seq: b.seq}
+
+	data, err := compress(l.compress, b.data)
+	if err != nil {
+		result.Status = "Fail"
+		result.Error = err.Error()
+		return result
+	}
+
+	var opts []doris.LoadOption
+	if l.label != "" {
+		opts = append(opts, doris.WithLabel(l.label+"_"+strconv.Itoa(b.seq)))
+	}
+
+	response, err := l.client.LoadContext(ctx, bytes.NewReader(data), opts...)
+	if response != nil {
+		resp := response.Resp
+		result.Response = &resp
+		result.Status = resp.Status
+	}
+	if err != nil {
+		if result.Status == "" || result.Status == "Success" {
+			result.Status = "Fail"
+		}
+		result.Error = err.Error()
+		if result.Response != nil && result.Response.Message != "" && !strings.Contains(result.Error, result.Response.Message) {
+			result.Error += ": " + result.Response.Message
+		}
+	} else if result.Status == "" {
+		result.Status = "Success"
+	}
+
+	if l.progress != nil {
+		l.progress.add(b, err == nil)
+	}
+	return result
+}
+
+// record stores the result of a batch
+func (l *fileLoader) record(result batchResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.results = append(l.results, result)
+	if result.Error != "" {
+		l.failed = true
+	}
+}
+
+// stopped reports whether no more batches should be queued after a failure
+func (l *fileLoader) stopped() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.failed && !l.continueOnError
+}
@@ -0,0 +1,115 @@
+// Command doris-load loads files and stdin into Apache Doris with stream load
+// Usage: doris-load <command> [flags] [args]
+// Run "doris-load help <command>" for the flags of a command.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+)
+
+const usage = `doris-load - Apache Doris stream load command-line tool
+
+Usage:
+  doris-load <command> [flags] [args]
+
+Commands:
+%s
+Connection settings are read from -config (YAML or JSON, see LoadConfigFile) and
+overridden by flags. The password may also be given as DORIS_PASSWORD.
+
+Run "doris-load help <command>" for the flags of a command.
+`
+
+// command is a subcommand of doris-load
+type command struct {
+	summary string
+	run     func(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+// commands lists the subcommands by name
+var commands = map[string]command{
+	"load":   {summary: "Load files, globs or stdin into a table", run: runLoad},
+	"label":  {summary: "Query the state of a load by label", run: runLabel},
+	"txn":    {summary: "Commit or abort a two-phase commit transaction", run: runTxn},
+	"errors": {summary: "Download and print the rejected rows of an ErrorURL", run: runErrors},
+	"bench":  {summary: "Benchmark stream load throughput with synthetic data", run: runBench},
+}
+
+// errUsage reports invalid arguments whose message was already printed with the usage
+var errUsage = errors.New("invalid usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	os.Exit(run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the process exit code
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+
+	name := args[0]
+	if name == "help" || isHelpFlag(name) {
+		if len(args) > 1 {
+			if cmd, ok := commands[args[1]]; ok {
+				// Flag sets print their usage on -h
+				cmd.run(ctx, []string{"-h"}, stdin, stdout, stderr)
+				return 0
+			}
+		}
+		printUsage(stdout)
+		return 0
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "doris-load: unknown command %q\n\n", name)
+		printUsage(stderr)
+		return 2
+	}
+
+	err := cmd.run(ctx, args[1:], stdin, stdout, stderr)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	default:
+		fmt.Fprintf(stderr, "doris-load %s: %v\n", name, err)
+		return 1
+	}
+}
+
+// printUsage writes the list of commands to w
+func printUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var list string
+	for _, name := range names {
+		list += fmt.Sprintf("  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintf(w, usage, list)
+}
+
+// isHelpFlag reports whether arg asks for help
+func isHelpFlag(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+// runCommand runs doris-load with args and returns the exit code, stdout and stderr
+func runCommand(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// connectionArgs returns the flags connecting to test_db.users on server
+func connectionArgs(server *dorismock.Server) []string {
+	return []string{
+		"-endpoints", server.URL(), "-user", "root", "-password", "secret", "-db", "test_db", "-table", "users",
+	}
+}
+
+// loadArgs returns the arguments of a load command against server
+func loadArgs(server *dorismock.Server, args ...string) []string {
+	return append(append([]string{"load", "-retries", "0"}, connectionArgs(server)...), args...)
+}
+
+func TestLoadSplitsFilesIntoCompressedBatches(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	var data strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&data, "%d,user_%d\n", i, i)
+	}
+	for _, name := range []string{"a.csv", "b.csv"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	code, stdout, stderr := runCommand(t, "", loadArgs(server,
+		"-batch-size", "512", "-parallel", "3", "-compress", "gz", "-output", "json", filepath.Join(dir, "*.csv"))...)
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+
+	var out struct {
+		Batches []batchResult `json:"batches"`
+		Summary loadSummary   `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if out.Summary.Files != 2 || out.Summary.LoadedRows != 200 || out.Summary.Batches < 4 {
+		t.Errorf("unexpected summary: %+v", out.Summary)
+	}
+	if first := out.Batches[0]; !strings.HasSuffix(first.File, "a.csv") || first.Batch != 0 || first.Response == nil {
+		t.Errorf("unexpected first batch: %+v", first)
+	}
+
+	if rows := server.Rows("test_db", "users"); len(rows) != 200 {
+		t.Errorf("expected 200 rows, got %d", len(rows))
+	}
+	for _, load := range server.Loads() {
+		if load.Headers.Get("compress_type") != "gz" {
+			t.Errorf("batch sent without compress_type: %v", load.Headers)
+		}
+	}
+}
+
+func TestLoadReadsStdin(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+
+	code, stdout, stderr := runCommand(t, `{"id": 1}`+"\n"+`{"id": 2}`+"\n",
+		loadArgs(server, "-format", "json", "-label", "nightly", "-columns", "id")...)
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "nightly_0") || !strings.Contains(stdout, "2 rows loaded") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+
+	load := server.Loads()[0]
+	if load.Label != "nightly_0" || load.Headers.Get("columns") != "id" || load.Headers.Get("format") != "json" {
+		t.Errorf("unexpected request: label %s, headers %v", load.Label, load.Headers)
+	}
+}
+
+func TestLoadReportsFailedBatches(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultLoadFailed, Message: "too many filtered rows", Times: 1})
+
+	code, stdout, stderr := runCommand(t, "1,Alice\n", loadArgs(server)...)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stdout, "too many filtered rows") || !strings.Contains(stderr, "1 of 1 batches failed") {
+		t.Errorf("unexpected output:\n%s\n%s", stdout, stderr)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	if code, _, _ := runCommand(t, "", "unknown"); code != 2 {
+		t.Errorf("expected exit code 2 for an unknown command, got %d", code)
+	}
+
+	server := dorismock.NewServer()
+	defer server.Close()
+	if code, _, _ := runCommand(t, "", loadArgs(server, "-output", "yaml")...); code != 2 {
+		t.Errorf("expected exit code 2 for an invalid flag value, got %d", code)
+	}
+}
+
+func TestLabelAndTransactionCommands(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+
+	code, _, stderr := runCommand(t, "1,Alice\n",
+		loadArgs(server, "-label", "nightly", "-header", "two_phase_commit=true")...)
+	if code != 0 {
+		t.Fatalf("load failed: %s", stderr)
+	}
+	txnID := server.Loads()[0].TxnID
+
+	labelStatus := append(append([]string{"label", "status"}, connectionArgs(server)...), "nightly_0")
+	if _, stdout, _ := runCommand(t, "", labelStatus...); stdout != "nightly_0\tPRECOMMITTED\n" {
+		t.Errorf("unexpected label status: %q", stdout)
+	}
+
+	commit := append(append([]string{"txn", "commit"}, connectionArgs(server)...), fmt.Sprint(txnID))
+	if code, stdout, stderr := runCommand(t, "", commit...); code != 0 || !strings.Contains(stdout, "committed") {
+		t.Fatalf("commit failed with code %d: %s%s", code, stdout, stderr)
+	}
+	if code, _, stderr := runCommand(t, "", commit...); code != 1 || !strings.Contains(stderr, "already visible") {
+		t.Errorf("expected a second commit to fail, got code %d: %s", code, stderr)
+	}
+
+	if _, stdout, _ := runCommand(t, "", labelStatus...); stdout != "nightly_0\tVISIBLE\n" {
+		t.Errorf("unexpected label status: %q", stdout)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 1 {
+		t.Errorf("expected the committed row, got %q", rows)
+	}
+}
+
+func TestErrorsCommand(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultFilterRows, FilteredRows: 2, Times: 1})
+
+	code, stdout, _ := runCommand(t, "bad,1\nbad,2\n3,Carol\n", loadArgs(server, "-output", "json")...)
+	if code != 1 {
+		t.Fatalf("expected the load to fail, got code %d", code)
+	}
+	var out struct {
+		Batches []batchResult `json:"batches"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil || out.Batches[0].Response == nil {
+		t.Fatalf("invalid output %v: %s", err, stdout)
+	}
+	errorURL := out.Batches[0].Response.ErrorURL
+
+	code, stdout, stderr := runCommand(t, "", "errors", "-limit", "1", errorURL)
+	if code != 0 {
+		t.Fatalf("errors failed: %s", stderr)
+	}
+	if !strings.Contains(stdout, "row: bad,1") || strings.Contains(stdout, "bad,2") || !strings.Contains(stdout, "1 rejected rows") {
+		t.Errorf("unexpected output:\n%s", stdout)
+	}
+}
+
+func TestSplitterCutsAtDelimiters(t *testing.T) {
+	s := newSplitter(strings.NewReader("aaa||bbb||cccccccc||d"), "||", 6)
+
+	var batches []string
+	var rows int64
+	for {
+		data, n, err := s.next()
+		if err != nil {
+			break
+		}
+		batches = append(batches, string(data))
+		rows += n
+	}
+
+	expected := []string{"aaa||bbb||", "cccccccc||", "d"}
+	if strings.Join(batches, " ") != strings.Join(expected, " ") || rows != 4 {
+		t.Errorf("unexpected batches %q (%d rows)", batches, rows)
+	}
+}
+
+func TestBenchAgainstMock(t *testing.T) {
+	code, stdout, stderr := runCommand(t, "", "bench", "-mock", "-size", "256KB", "-concurrency", "1,2",
+		"-batch-size", "64KB", "-compress", "none,gz", "-schema", "id:bigint,name:string(8),created:datetime", "-output", "json")
+	if code != 0 {
+		t.Fatalf("exit code %d: %s", code, stderr)
+	}
+
+	var results []benchResult
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if len(results) != 4 {
+		t.Fatalf("expected 4 runs, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.Batches != 4 || r.Failed != 0 || r.Rows == 0 || r.LatencyP99Ms < r.LatencyP50Ms {
+			t.Errorf("unexpected run: %+v", r)
+		}
+		if r.Compress == "gz" && r.SentBytes >= r.Bytes {
+			t.Errorf("expected gz to shrink the payload: %+v", r)
+		}
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	columns, err := parseSchema("id:bigint, name:varchar(32), price:decimal")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(columns) != 3 || columns[1].kind != "string" || columns[1].length != 32 {
+		t.Errorf("unexpected columns: %+v", columns)
+	}
+
+	for _, schema := range []string{"", "id", "id:uuid", "id:int(4)"} {
+		if _, err := parseSchema(schema); err == nil {
+			t.Errorf("expected %q to be rejected", schema)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk"
+)
+
+// loadSummary totals the results of a load command
+type loadSummary struct {
+	Files        int     `json:"files"`
+	Batches      int     `json:"batches"`
+	Failed       int     `json:"failed"`
+	LoadedRows   int64   `json:"loaded_rows"`
+	FilteredRows int64   `json:"filtered_rows"`
+	Bytes        int64   `json:"bytes"`
+	ElapsedMs    int64   `json:"elapsed_ms"`
+	MBPerSecond  float64 `json:"mb_per_second"`
+}
+
+// summarize totals results
+func summarize(results []batchResult, files int, elapsed time.Duration) loadSummary {
+	summary := loadSummary{Files: files, Batches: len(results), ElapsedMs: elapsed.Milliseconds()}
+	for _, r := range results {
+		if r.Error != "" {
+			summary.Failed++
+		}
+		summary.Bytes += r.Bytes
+		if r.Response != nil {
+			summary.LoadedRows += r.Response.NumberLoadedRows
+			summary.FilteredRows += int64(r.Response.NumberFilteredRows)
+		}
+	}
+	if elapsed > 0 {
+		summary.MBPerSecond = float64(summary.Bytes) / (1 << 20) / elapsed.Seconds()
+	}
+	return summary
+}
+
+// sortResults orders results as their batches appear in the inputs
+func sortResults(results []batchResult) {
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].seq < results[j].seq
+	})
+}
+
+// writeJSON prints the results and summary as one JSON document
+func writeJSON(w io.Writer, results []batchResult, summary loadSummary) error {
+	if results == nil {
+		results = []batchResult{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		Batches []batchResult `json:"batches"`
+		Summary loadSummary   `json:"summary"`
+	}{results, summary})
+}
+
+// writeTable prints one line per batch followed by the summary
+func writeTable(w io.Writer, results []batchResult, summary loadSummary) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tBATCH\tLABEL\tSTATUS\tTOTAL\tLOADED\tFILTERED\tBYTES\tTIME(ms)")
+	for _, r := range results {
+		resp := r.Response
+		if resp == nil {
+			// The batch never reached Doris
+			resp = &doris.RespContent{}
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\t%d\t%d\t%d\t%d\n",
+			r.File, r.Batch, orDash(resp.Label), r.Status,
+			resp.NumberTotalRows, resp.NumberLoadedRows, resp.NumberFilteredRows, r.Bytes, resp.LoadTimeMs)
+	}
+	tw.Flush()
+
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "\n%s batch %d: %s", r.File, r.Batch, r.Error)
+			if r.Response != nil && r.Response.ErrorURL != "" {
+				fmt.Fprintf(w, "\n  error log: %s", r.Response.ErrorURL)
+			}
+		}
+	}
+	if summary.Failed > 0 {
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "\n%d files, %d batches (%d failed), %d rows loaded, %d filtered, %s in %s (%.1f MB/s)\n",
+		summary.Files, summary.Batches, summary.Failed, summary.LoadedRows, summary.FilteredRows,
+		formatBytes(summary.Bytes), time.Duration(summary.ElapsedMs)*time.Millisecond, summary.MBPerSecond)
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// progress reports the upload progress on one refreshed terminal line
+type progress struct {
+	w     io.Writer
+	total int64 // Input size, 0 if unknown
+	start time.Time
+
+	mu      sync.Mutex
+	batches int
+	failed  int
+	rows    int64
+	bytes   int64
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// newProgress starts reporting to w every half second
+func newProgress(w io.Writer, total int64) *progress {
+	p := &progress{w: w, total: total, start: time.Now(), done: make(chan struct{}), stopped: make(chan struct{})}
+	go p.loop()
+	return p
+}
+
+// add counts a finished batch
+func (p *progress) add(b *batch, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batches++
+	p.bytes += b.size
+	if ok {
+		p.rows += b.rows
+	} else {
+		p.failed++
+	}
+}
+
+// stop prints the final state and ends the line
+func (p *progress) stop() {
+	close(p.done)
+	<-p.stopped
+}
+
+// loop redraws the progress line until stop
+func (p *progress) loop() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.print()
+		case <-p.done:
+			p.print()
+			fmt.Fprintln(p.w)
+			return
+		}
+	}
+}
+
+// print draws the current progress
+func (p *progress) print() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	elapsed := time.Since(p.start)
+	sent := formatBytes(p.bytes)
+	if p.total > 0 {
+		sent = fmt.Sprintf("%s / %s (%.0f%%)", sent, formatBytes(p.total), float64(p.bytes)*100/float64(p.total))
+	}
+	rate := float64(p.bytes) / (1 << 20) / elapsed.Seconds()
+	fmt.Fprintf(p.w, "\r%d batches (%d failed)  %s  %d rows  %.1f MB/s  %s   ",
+		p.batches, p.failed, sent, p.rows, rate, elapsed.Truncate(time.Second))
+}
+
+// isTerminal reports whether w is a character device such as a terminal
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const labelUsage = `label status [flags] LABEL
+
+Prints the state of the load with LABEL in the database: PREPARE, PRECOMMITTED,
+COMMITTED, VISIBLE, ABORTED, or UNKNOWN for labels Doris does not know.`
+
+const txnUsage = `txn commit|abort [flags] TXN_ID
+
+Commits or aborts a transaction precommitted by a stream load with two_phase_commit.`
+
+// runLabel implements the label command
+func runLabel(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) > 0 && isHelpFlag(args[0]) {
+		fmt.Fprintf(stderr, "Usage: doris-load %s\n", labelUsage)
+		return nil
+	}
+	if len(args) == 0 || args[0] != "status" {
+		fmt.Fprintf(stderr, "Usage: doris-load %s\n", labelUsage)
+		return errUsage
+	}
+
+	var f connectionFlags
+	var output string
+	fs := newFlagSet("label status", labelUsage, stderr)
+	f.register(fs)
+	fs.StringVar(&output, "output", "table", "result format: table or json")
+	set, err := parseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	label := fs.Arg(0)
+
+	client, err := f.newAPIClient(set, stderr)
+	if err != nil {
+		return err
+	}
+	state, err := client.GetLoadStateContext(ctx, label)
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		return json.NewEncoder(stdout).Encode(map[string]string{"label": label, "state": state})
+	}
+	fmt.Fprintf(stdout, "%s\t%s\n", label, state)
+	return nil
+}
+
+// runTxn implements the txn command
+func runTxn(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) > 0 && isHelpFlag(args[0]) {
+		fmt.Fprintf(stderr, "Usage: doris-load %s\n", txnUsage)
+		return nil
+	}
+	if len(args) == 0 || (args[0] != "commit" && args[0] != "abort") {
+		fmt.Fprintf(stderr, "Usage: doris-load %s\n", txnUsage)
+		return errUsage
+	}
+	operation := args[0]
+
+	var f connectionFlags
+	fs := newFlagSet("txn "+operation, txnUsage, stderr)
+	f.register(fs)
+	set, err := parseFlags(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+	txnID, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		fmt.Fprintf(stderr, "invalid transaction id %q\n", fs.Arg(0))
+		return errUsage
+	}
+
+	client, err := f.newAPIClient(set, stderr)
+	if err != nil {
+		return err
+	}
+	done := "committed"
+	if operation == "commit" {
+		err = client.CommitTransactionContext(ctx, txnID)
+	} else {
+		err = client.AbortTransactionContext(ctx, txnID)
+		done = "aborted"
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "transaction %d %s\n", txnID, done)
+	return nil
+}
@@ -82,7 +82,8 @@ func main() {
 	fmt.Println("=" + strings.Repeat("=", 50))
 
 	if len(os.Args) < 2 {
-		fmt.Println("❌ No example specified\n")
+		fmt.Println("❌ No example specified")
+		fmt.Println()
 		printUsage()
 		os.Exit(1)
 	}
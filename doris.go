@@ -24,6 +24,73 @@ type ContextLogger = load.ContextLogger
 // Load response aliases
 type LoadResponse = load.LoadResponse
 type LoadStatus = load.LoadStatus
+type RespContent = load.RespContent
+type RejectedRow = load.RejectedRow
+type RejectedRowsError = load.RejectedRowsError
+
+// Schema aliases
+type TableSchema = load.TableSchema
+type Column = load.Column
+type SchemaError = load.SchemaError
+
+// Table definition aliases
+type TableDefinition = load.TableDefinition
+type ColumnDefinition = load.ColumnDefinition
+type TableDistribution = load.TableDistribution
+type TablePartition = load.TablePartition
+type KeysType = load.KeysType
+type EnsureTableOptions = load.EnsureTableOptions
+
+// Dead-letter aliases
+type DeadLetterSink = load.DeadLetterSink
+type DeadLetterRecord = load.DeadLetterRecord
+type DirDeadLetterSink = load.DirDeadLetterSink
+type ReplayResult = load.ReplayResult
+
+// Async load aliases
+type WorkerPool = load.WorkerPool
+type LoadFuture = load.LoadFuture
+type LoadResult = load.LoadResult
+
+// Throttling aliases
+type RateLimit = load.RateLimit
+type ThrottleMetrics = load.ThrottleMetrics
+
+// Backpressure aliases
+type Backpressure = load.Backpressure
+type BackpressureState = load.BackpressureState
+
+// Multi-table aliases
+type TableOptions = load.TableOptions
+type TableSink = load.TableSink
+type TableMetrics = load.TableMetrics
+type MultiTableWriter = load.MultiTableWriter
+type MultiTableResult = load.MultiTableResult
+type MultiTableRows = load.MultiTableRows
+
+// Spool aliases
+type Spool = load.Spool
+type SpoolOptions = load.SpoolOptions
+type SpoolStats = load.SpoolStats
+
+// Tail loader aliases
+type TailLoader = load.TailLoader
+type TailOptions = load.TailOptions
+type TailStats = load.TailStats
+
+// Directory import aliases
+type ImportOptions = load.ImportOptions
+type ImportChunk = load.ImportChunk
+type ImportFileReport = load.ImportFileReport
+type ImportReport = load.ImportReport
+
+// SQL copy aliases
+type SQLCopyOptions = load.SQLCopyOptions
+type SQLCopyProgress = load.SQLCopyProgress
+
+// Row encoder aliases
+type Encoder = load.Encoder
+type EncoderDate = load.EncoderDate
 
 // Enum constants
 const (
@@ -40,6 +107,11 @@ const (
 	SUCCESS = load.SUCCESS
 	FAILURE = load.FAILURE
 
+	// Table data models
+	DuplicateKeys = load.DuplicateKeys
+	UniqueKeys    = load.UniqueKeys
+	AggregateKeys = load.AggregateKeys
+
 	// Log level constants
 	LogLevelDebug = load.LogLevelDebug
 	LogLevelInfo  = load.LogLevelInfo
@@ -50,12 +122,92 @@ const (
 // GroupCommitMode aliases
 type GroupCommitMode = load.GroupCommitMode
 type Retry = load.Retry
+type FieldError = load.FieldError
+type ValidationError = load.ValidationError
+type Option = load.Option
+type LoadOption = load.LoadOption
+
+// Credentials aliases
+type Credentials = load.Credentials
+type CredentialsProvider = load.CredentialsProvider
 
 // Function aliases for easy access
 var (
 	// Client functions
 	NewLoadClient = load.NewLoadClient
 
+	// Configuration functions
+	NewConfig      = load.NewConfig
+	LoadConfigFile = load.LoadConfigFile
+	ConfigFromEnv  = load.ConfigFromEnv
+
+	// Config options for NewConfig; the options of the load itself are also accepted per call by Load as LoadOption
+	WithEndpoints          = load.WithEndpoints
+	WithAuth               = load.WithAuth
+	WithCredentials        = load.WithCredentials
+	WithAllowEmptyPassword = load.WithAllowEmptyPassword
+	WithDatabase           = load.WithDatabase
+	WithTable              = load.WithTable
+	WithCSV                = load.WithCSV
+	WithJSON               = load.WithJSON
+	WithFormat             = load.WithFormat
+	WithLabel              = load.WithLabel
+	WithLabelPrefix        = load.WithLabelPrefix
+	WithGroupCommit        = load.WithGroupCommit
+	WithRetry              = load.WithRetry
+	WithOption             = load.WithOption
+	WithOptions            = load.WithOptions
+	WithHeaders            = load.WithHeaders
+	WithPartitions         = load.WithPartitions
+	WithColumns            = load.WithColumns
+	WithErrorLogRows       = load.WithErrorLogRows
+	WithSchemaValidation   = load.WithSchemaValidation
+	WithDeadLetter         = load.WithDeadLetter
+	WithWorkerPool         = load.WithWorkerPool
+	WithRateLimit          = load.WithRateLimit
+	WithBackpressure       = load.WithBackpressure
+	WithTransport          = load.WithTransport
+
+	// Separator helpers
+	EscapeDelimiter   = load.EscapeDelimiter
+	UnescapeDelimiter = load.UnescapeDelimiter
+
+	// Credentials providers
+	NewStaticCredentials   = load.NewStaticCredentials
+	NewEnvCredentials      = load.NewEnvCredentials
+	NewFileCredentials     = load.NewFileCredentials
+	NewCallbackCredentials = load.NewCallbackCredentials
+
+	// Dead-letter functions
+	NewDirDeadLetterSink = load.NewDirDeadLetterSink
+
+	// Async load errors
+	ErrQueueFull      = load.ErrQueueFull
+	ErrClientShutdown = load.ErrClientShutdown
+
+	// Error log functions
+	FetchErrorLog = load.FetchErrorLog
+
+	// Spool functions and errors
+	NewSpool       = load.NewSpool
+	ErrSpoolFull   = load.ErrSpoolFull
+	ErrSpoolClosed = load.ErrSpoolClosed
+
+	// Tail loader functions and errors
+	NewTailLoader = load.NewTailLoader
+	ErrTailClosed = load.ErrTailClosed
+
+	// SQL copy functions
+	CopyFromSQL = load.CopyFromSQL
+
+	// Table definition functions
+	TableFromStruct = load.TableFromStruct
+	TableFromJSON   = load.TableFromJSON
+
+	// Row encoder functions and errors
+	NewEncoder      = load.NewEncoder
+	ErrNeedsEnclose = load.ErrNeedsEnclose
+
 	// Data conversion helpers
 	StringReader = load.StringReader
 	BytesReader  = load.BytesReader
@@ -28,18 +28,12 @@ func LabelRemovalDemo() {
 		GroupCommit: doris.ASYNC, // 启用 group commit
 	}
 
-	client1, err := doris.NewLoadClient(configWithLabel)
-	if err != nil {
-		fmt.Printf("Failed to create client: %v\n", err)
-		return
+	// Label 与 group commit 互斥，配置校验会直接拒绝
+	if _, err := doris.NewLoadClient(configWithLabel); err != nil {
+		fmt.Printf("预期的配置错误（label 与 group commit 互斥）: %v\n", err)
 	}
 
 	testData := `{"id": 1, "name": "test"}`
-	fmt.Println("尝试加载数据，观察 label 删除日志...")
-	_, err = client1.Load(strings.NewReader(testData))
-	if err != nil {
-		fmt.Printf("预期的连接错误（测试环境）: %v\n", err)
-	}
 
 	// 演示 2: 使用 LabelPrefix + Group Commit
 	fmt.Println("\n--- 演示 2: Label Prefix + Group Commit ---")
@@ -83,17 +77,8 @@ func LabelRemovalDemo() {
 		GroupCommit: doris.ASYNC, // 启用 group commit
 	}
 
-	client3, err := doris.NewLoadClient(configWithBoth)
-	if err != nil {
-		fmt.Printf("Failed to create client: %v\n", err)
-		return
-	}
-
-	jsonData := `{"id": 3, "name": "Charlie"}`
-	fmt.Println("尝试加载数据，观察两个 label 相关配置的删除日志...")
-	_, err = client3.Load(strings.NewReader(jsonData))
-	if err != nil {
-		fmt.Printf("预期的连接错误（测试环境）: %v\n", err)
+	if _, err := doris.NewLoadClient(configWithBoth); err != nil {
+		fmt.Printf("预期的配置错误（label 与 group commit 互斥）: %v\n", err)
 	}
 
 	// 演示 4: 不启用 Group Commit 的正常情况
@@ -126,7 +111,8 @@ func LabelRemovalDemo() {
 	fmt.Println("\n=== Demo 完成 ===")
 	fmt.Println("💡 注意: 以上演示了在启用 group commit 时的 label 删除日志功能")
 	fmt.Println("📋 日志级别说明:")
-	fmt.Println("   - WARN: 用户配置的 label/label_prefix 被删除的警告")
+	fmt.Println("   - ERROR: 自定义 label 与 group commit 同时配置时创建客户端失败")
+	fmt.Println("   - WARN: 用户配置的 label_prefix 被删除的警告")
 	fmt.Println("   - INFO: Group commit 启用时的合规性删除操作")
 	fmt.Println("   - DEBUG: 正常的 label 生成过程")
 }
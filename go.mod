@@ -5,6 +5,7 @@ go 1.20
 require (
 	github.com/google/uuid v1.4.0
 	github.com/json-iterator/go v1.1.12
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
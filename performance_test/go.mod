@@ -1,14 +0,0 @@
-module performance_test
-
-go 1.24.3
-
-require github.com/bingquanzhao/go-doris-sdk v0.0.0-20250605190132-5c11ba7f3b94
-
-require (
-	github.com/google/uuid v1.4.0 // indirect
-	github.com/json-iterator/go v1.1.12 // indirect
-	github.com/modern-go/concurrent v0.0.0-20180228061459-e0a39a4cb421 // indirect
-	github.com/modern-go/reflect2 v1.0.2 // indirect
-)
-
-replace github.com/bingquanzhao/go-doris-sdk => ../
@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+const (
+	defaultPoolWorkers   = 4
+	defaultPoolQueueSize = 100
+)
+
+var (
+	// ErrQueueFull is the result of LoadAsync when the queue is full and FailFast is enabled
+	ErrQueueFull = errors.New("load queue is full")
+	// ErrClientShutdown is the result of LoadAsync after Shutdown was called
+	ErrClientShutdown = errors.New("client is shut down")
+)
+
+// LoadResult is the outcome of an asynchronous load
+type LoadResult struct {
+	Response *loader.LoadResponse
+	Err      error
+}
+
+// LoadFuture is the pending result of LoadAsync
+type LoadFuture struct {
+	done     chan struct{}
+	resultCh chan LoadResult
+	result   LoadResult
+}
+
+// newLoadFuture creates an unresolved future
+func newLoadFuture() *LoadFuture {
+	return &LoadFuture{
+		done:     make(chan struct{}),
+		resultCh: make(chan LoadResult, 1),
+	}
+}
+
+// resolve completes the future; it must be called exactly once
+func (f *LoadFuture) resolve(response *loader.LoadResponse, err error) {
+	f.result = LoadResult{Response: response, Err: err}
+	f.resultCh <- f.result
+	close(f.done)
+}
+
+// Wait blocks until the load finishes and returns its result, like Load would
+func (f *LoadFuture) Wait() (*loader.LoadResponse, error) {
+	<-f.done
+	return f.result.Response, f.result.Err
+}
+
+// Done returns a channel closed when the load finishes
+func (f *LoadFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result returns a channel receiving the result once the load finishes
+// The result is delivered a single time; use Wait to read it again
+func (f *LoadFuture) Result() <-chan LoadResult {
+	return f.resultCh
+}
+
+// loadTask is a load waiting in the pool queue
+type loadTask struct {
+	reader io.Reader
+	opts   []LoadOption
+	future *LoadFuture
+}
+
+// workerPool runs LoadAsync tasks on a fixed number of goroutines, started on first use
+type workerPool struct {
+	workers  int
+	failFast bool
+	queue    chan *loadTask
+
+	startOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+	stop      chan struct{}  // Closed by Shutdown, releases LoadAsync calls blocked on a full queue
+	senders   sync.WaitGroup // LoadAsync calls that may still send to the queue
+	drained   chan struct{}  // Closed once every queued load finished
+	wg        sync.WaitGroup
+}
+
+// newWorkerPool creates a pool from the WorkerPool configuration
+func newWorkerPool(workers, queueSize int, failFast bool) *workerPool {
+	if workers <= 0 {
+		workers = defaultPoolWorkers
+	}
+	if queueSize <= 0 {
+		queueSize = defaultPoolQueueSize
+	}
+	return &workerPool{
+		workers:  workers,
+		failFast: failFast,
+		queue:    make(chan *loadTask, queueSize),
+		stop:     make(chan struct{}),
+		drained:  make(chan struct{}),
+	}
+}
+
+// LoadAsync queues a load on the client's worker pool and returns immediately
+// The reader is consumed by a worker later, so the caller must not reuse it.
+// When the queue is full LoadAsync blocks until there is room or Shutdown is called, or fails
+// with ErrQueueFull if WorkerPool.FailFast is set.
+func (c *DorisLoadClient) LoadAsync(reader io.Reader, opts ...LoadOption) *LoadFuture {
+	future := newLoadFuture()
+	p := c.pool
+	c.startWorkers()
+
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		future.resolve(nil, ErrClientShutdown)
+		return future
+	}
+	// Shutdown closes the queue only after every registered sender returned
+	p.senders.Add(1)
+	p.mu.RUnlock()
+	defer p.senders.Done()
+
+	task := &loadTask{reader: reader, opts: opts, future: future}
+	if p.failFast {
+		select {
+		case p.queue <- task:
+		default:
+			log.Warnf("Load queue is full (%d), rejecting load", cap(p.queue))
+			future.resolve(nil, ErrQueueFull)
+		}
+		return future
+	}
+
+	select {
+	case p.queue <- task:
+	case <-p.stop:
+		future.resolve(nil, ErrClientShutdown)
+	}
+	return future
+}
+
+// startWorkers starts the pool goroutines once
+func (c *DorisLoadClient) startWorkers() {
+	p := c.pool
+	p.startOnce.Do(func() {
+		log.Infof("Starting load worker pool with %d workers, queue size %d", p.workers, cap(p.queue))
+		for i := 0; i < p.workers; i++ {
+			p.wg.Add(1)
+			go c.runWorker()
+		}
+	})
+}
+
+// runWorker executes queued loads until the queue is closed
+func (c *DorisLoadClient) runWorker() {
+	defer c.pool.wg.Done()
+	for task := range c.pool.queue {
+		response, err := c.Load(task.reader, task.opts...)
+		task.future.resolve(response, err)
+	}
+}
+
+// Shutdown stops accepting LoadAsync calls and waits for queued loads to finish
+// LoadAsync calls blocked on a full queue fail with ErrClientShutdown. It returns ctx.Err() if
+// the context ends first; the remaining loads still run to completion.
+func (c *DorisLoadClient) Shutdown(ctx context.Context) error {
+	p := c.pool
+
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.stop)
+		go func() {
+			p.senders.Wait()
+			close(p.queue)
+			p.wg.Wait()
+			close(p.drained)
+		}()
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.drained:
+		log.Infof("Load worker pool drained")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
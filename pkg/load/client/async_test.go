@@ -0,0 +1,80 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestLoadAsyncQueueAndShutdown(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+
+	// Workers are held back so the queue fills deterministically, then started by hand
+	newPoolClient := func(failFast bool) *client.DorisLoadClient {
+		c := server.NewClient(t, config.WithWorkerPool(&config.WorkerPool{Workers: 1, QueueSize: 1, FailFast: failFast}))
+		c.HoldWorkers()
+		return c
+	}
+
+	c := newPoolClient(true)
+	queued := c.LoadAsync(strings.NewReader("1,alice\n"))
+	if _, err := c.LoadAsync(strings.NewReader("2,bob\n")).Wait(); !errors.Is(err, client.ErrQueueFull) {
+		t.Errorf("expected client.ErrQueueFull, got %v", err)
+	}
+	c.StartWorker()
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := queued.Wait(); err != nil {
+		t.Errorf("queued load must be drained by Shutdown, got %v", err)
+	}
+	if _, err := c.LoadAsync(strings.NewReader("3,carol\n")).Wait(); !errors.Is(err, client.ErrClientShutdown) {
+		t.Errorf("expected client.ErrClientShutdown, got %v", err)
+	}
+
+	// A LoadAsync blocked on the full queue does not keep Shutdown from returning
+	c = newPoolClient(false)
+	c.StartWorker()
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultTimeout, Delay: 300 * time.Millisecond, Times: 1})
+	running := c.LoadAsync(strings.NewReader("4,dave\n"))
+	for c.QueuedLoads() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	queued = c.LoadAsync(strings.NewReader("5,eve\n"))
+	blocked := make(chan *client.LoadFuture, 1)
+	go func() { blocked <- c.LoadAsync(strings.NewReader("6,frank\n")) }()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected Shutdown to time out while a load runs, got %v", err)
+	}
+	select {
+	case future := <-blocked:
+		if _, err := future.Wait(); !errors.Is(err, client.ErrClientShutdown) {
+			t.Errorf("expected client.ErrClientShutdown for the blocked load, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("LoadAsync stayed blocked after Shutdown")
+	}
+
+	if err := c.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, future := range []*client.LoadFuture{running, queued} {
+		if _, err := future.Wait(); err != nil {
+			t.Errorf("queued loads must be drained by Shutdown, got %v", err)
+		}
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 3 {
+		t.Errorf("expected the 3 queued rows, got %q", rows)
+	}
+}
@@ -0,0 +1,233 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+const (
+	defaultBackpressureMaxConcurrency = 8
+	defaultBackpressureMinConcurrency = 1
+	defaultBackpressureBaseIntervalMs = 500
+	defaultBackpressureMaxIntervalMs  = 30000
+
+	// backpressurePollInterval bounds how long a waiting load sleeps before re-checking the window
+	backpressurePollInterval = 20 * time.Millisecond
+)
+
+var (
+	// overloadPatterns are messages Doris returns when BEs cannot keep up with the load rate, matched
+	// lowercase; error code -235 (too many tablet versions) is reported as "[E-235]" or "err=-235"
+	overloadPatterns = []string{
+		"[e-235]",
+		"err=-235",
+		"too many versions",
+		"too many tablet versions",
+		"publish timeout",
+		"mem_limit_exceeded",
+		"memory limit exceeded",
+	}
+)
+
+// BackpressureState is a snapshot of the adaptive throttling state
+type BackpressureState struct {
+	Concurrency     int           // Current concurrency window
+	Interval        time.Duration // Current minimum delay between load starts
+	InFlight        int           // Loads currently running under the window
+	Throttled       bool          // Whether the client is below full speed
+	OverloadSignals int64         // Overload signals seen since the client was created
+	LastSignal      string        // Message of the last overload signal
+	LastSignalTime  time.Time     // When the last overload signal was seen
+}
+
+// backpressureController implements AIMD control of load concurrency and frequency:
+// every overload signal halves the concurrency window and doubles the delay between load starts,
+// every success grows the window by 1/window and shrinks the delay by one base interval.
+type backpressureController struct {
+	mu sync.Mutex
+
+	minLimit float64
+	maxLimit float64
+	limit    float64
+
+	baseInterval time.Duration
+	maxInterval  time.Duration
+	interval     time.Duration
+	nextStart    time.Time
+	inFlight     int
+
+	signals        int64
+	lastSignal     string
+	lastSignalTime time.Time
+}
+
+// newBackpressureController creates a controller for cfg, or nil when disabled
+func newBackpressureController(cfg *config.Backpressure) *backpressureController {
+	if cfg == nil {
+		return nil
+	}
+
+	maxLimit := cfg.MaxConcurrency
+	if maxLimit <= 0 {
+		maxLimit = defaultBackpressureMaxConcurrency
+	}
+	minLimit := cfg.MinConcurrency
+	if minLimit <= 0 {
+		minLimit = defaultBackpressureMinConcurrency
+	}
+	if minLimit > maxLimit {
+		minLimit = maxLimit
+	}
+	baseIntervalMs := cfg.BaseIntervalMs
+	if baseIntervalMs <= 0 {
+		baseIntervalMs = defaultBackpressureBaseIntervalMs
+	}
+	maxIntervalMs := cfg.MaxIntervalMs
+	if maxIntervalMs <= 0 {
+		maxIntervalMs = defaultBackpressureMaxIntervalMs
+	}
+
+	return &backpressureController{
+		minLimit:     float64(minLimit),
+		maxLimit:     float64(maxLimit),
+		limit:        float64(maxLimit),
+		baseInterval: time.Duration(baseIntervalMs) * time.Millisecond,
+		maxInterval:  time.Duration(maxIntervalMs) * time.Millisecond,
+	}
+}
+
+// acquire waits until the window allows another load to start and reports whether it had to wait
+func (b *backpressureController) acquire(ctx context.Context) (bool, error) {
+	for waited := false; ; waited = true {
+		b.mu.Lock()
+		now := time.Now()
+		if b.inFlight < int(b.limit) && !now.Before(b.nextStart) {
+			b.inFlight++
+			b.nextStart = now.Add(b.interval)
+			b.mu.Unlock()
+			return waited, nil
+		}
+
+		wait := backpressurePollInterval
+		if b.inFlight < int(b.limit) {
+			if untilStart := b.nextStart.Sub(now); untilStart < wait {
+				wait = untilStart
+			}
+		}
+		b.mu.Unlock()
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return true, err
+		}
+	}
+}
+
+// release frees the slot of a finished load and adapts the window to its outcome
+func (b *backpressureController) release(response *loader.LoadResponse, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.inFlight--
+
+	if signal := overloadSignal(response, err); signal != "" {
+		b.signals++
+		b.lastSignal = signal
+		b.lastSignalTime = time.Now()
+
+		b.limit /= 2
+		if b.limit < b.minLimit {
+			b.limit = b.minLimit
+		}
+		if b.interval == 0 {
+			b.interval = b.baseInterval
+		} else {
+			b.interval *= 2
+		}
+		if b.interval > b.maxInterval {
+			b.interval = b.maxInterval
+		}
+		b.nextStart = time.Now().Add(b.interval)
+
+		log.Warnf("Doris overload signal detected (%s), backing off: concurrency=%d, interval=%v", signal, int(b.limit), b.interval)
+		return
+	}
+
+	if err != nil || response == nil || response.Status != loader.SUCCESS {
+		// Failures unrelated to overload do not change the window
+		return
+	}
+
+	if b.limit < b.maxLimit {
+		b.limit += 1 / b.limit
+		if b.limit > b.maxLimit {
+			b.limit = b.maxLimit
+		}
+	}
+	if b.interval > 0 {
+		b.interval -= b.baseInterval
+		if b.interval < 0 {
+			b.interval = 0
+		}
+		if b.interval == 0 {
+			log.Infof("Doris overload cleared, load frequency fully recovered")
+		}
+	}
+}
+
+// state returns a snapshot of the controller
+func (b *backpressureController) state() BackpressureState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return BackpressureState{
+		Concurrency:     int(b.limit),
+		Interval:        b.interval,
+		InFlight:        b.inFlight,
+		Throttled:       b.limit < b.maxLimit || b.interval > 0,
+		OverloadSignals: b.signals,
+		LastSignal:      b.lastSignal,
+		LastSignalTime:  b.lastSignalTime,
+	}
+}
+
+// overloadSignal returns the overload message carried by a load outcome, or "" if there is none
+func overloadSignal(response *loader.LoadResponse, err error) string {
+	var candidates []string
+	if response != nil {
+		candidates = append(candidates, response.Resp.Status, response.Resp.Message, response.ErrorMessage)
+	}
+	if err != nil {
+		candidates = append(candidates, err.Error())
+	}
+
+	for _, candidate := range candidates {
+		lower := strings.ToLower(candidate)
+		for _, pattern := range overloadPatterns {
+			if strings.Contains(lower, pattern) {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// isRetryableOverload reports whether a failed load hit an overload signal that is safe to retry
+// A publish timeout is excluded: the transaction is already committed and a retry would duplicate data.
+func isRetryableOverload(response *loader.LoadResponse, err error) bool {
+	signal := overloadSignal(response, err)
+	return signal != "" && !strings.Contains(strings.ToLower(signal), "publish timeout")
+}
+
+// BackpressureState returns the current adaptive throttling state
+// The zero value is returned when Backpressure is not configured.
+func (c *DorisLoadClient) BackpressureState() BackpressureState {
+	if c.backpressure == nil {
+		return BackpressureState{}
+	}
+	return c.backpressure.state()
+}
@@ -0,0 +1,69 @@
+package client_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+)
+
+func TestBackpressureWindow(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithRetry(&config.Retry{}),
+		config.WithBackpressure(&config.Backpressure{MaxConcurrency: 4, BaseIntervalMs: 10, MaxIntervalMs: 15}))
+
+	overload := dorismock.Fault{Type: dorismock.FaultLoadFailed, Message: "[E-235]failed to init rowset builder. version count: 2001, exceed limit: 2000", Times: 1}
+	expectState := func(concurrency int, interval time.Duration, signals int64) {
+		t.Helper()
+		state := c.BackpressureState()
+		if state.Concurrency != concurrency || state.Interval != interval || state.OverloadSignals != signals || state.InFlight != 0 {
+			t.Errorf("expected window %d, interval %v and %d signals, got %+v", concurrency, interval, signals, state)
+		}
+	}
+
+	// Every overload signal halves the window and doubles the interval, up to MaxIntervalMs
+	for i, want := range []struct {
+		concurrency int
+		interval    time.Duration
+	}{{2, 10 * time.Millisecond}, {1, 15 * time.Millisecond}} {
+		server.InjectFault(overload)
+		if _, err := c.Load(strings.NewReader("1,alice\n")); err == nil {
+			t.Fatal("expected the overloaded load to fail")
+		}
+		expectState(want.concurrency, want.interval, int64(i+1))
+	}
+
+	// Other failures leave the window alone
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultLoadFailed, Message: "column count mismatch", Times: 1})
+	c.Load(strings.NewReader("1,alice\n"))
+	expectState(1, 15*time.Millisecond, 2)
+
+	// Successes grow the window by 1/window and shrink the interval by one base interval
+	c.Load(strings.NewReader("1,alice\n"))
+	expectState(2, 5*time.Millisecond, 2)
+	c.Load(strings.NewReader("2,bob\n"))
+	expectState(2, 0, 2)
+	if state := c.BackpressureState(); !state.Throttled || !strings.Contains(state.LastSignal, "[E-235]") {
+		t.Errorf("unexpected state %+v", state)
+	}
+}
+
+func TestOverloadSignalPatterns(t *testing.T) {
+	for message, overload := range map[string]bool{
+		"[E-235]failed to init rowset builder":         true,
+		"tablet writer write failed, err=-235":         true,
+		"PUBLISH TIMEOUT, txn_id=12":                   true,
+		"too many filtered rows, row 12-235 is bad":    false,
+		"[DATA_QUALITY_ERROR]value -2350 out of range": false,
+	} {
+		response := &loader.LoadResponse{Status: loader.FAILURE, ErrorMessage: message}
+		if got := client.OverloadSignal(response, nil) != ""; got != overload {
+			t.Errorf("overloadSignal(%q) = %v, expected %v", message, got, overload)
+		}
+	}
+}
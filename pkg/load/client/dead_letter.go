@@ -0,0 +1,176 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/deadletter"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+const (
+	// replayLabelSuffix is appended to the stored label so replays are idempotent
+	replayLabelSuffix = "_replay"
+
+	// labelAlreadyExistsStatus is the response status Doris returns for a duplicated label
+	labelAlreadyExistsStatus = "Label Already Exists"
+
+	// Statuses of the existing job of a duplicated label under which its data counts as loaded
+	existingJobVisible  = "VISIBLE"
+	existingJobFinished = "FINISHED"
+)
+
+// ReplayResult summarizes a Replay run
+type ReplayResult struct {
+	Replayed int      // Entries loaded successfully and removed from the directory
+	Failed   int      // Entries that failed again and were kept
+	Errors   []error  // One error per failed entry
+	Skipped  []string // Paths of filtered entries, kept for inspection
+}
+
+// sendToDeadLetter hands the batch of a finished load to the configured dead-letter sink
+func (c *DorisLoadClient) sendToDeadLetter(cfg *config.Config, opts *loadOptions, reason deadletter.Reason, getBody func() (io.Reader, error),
+	req *http.Request, attempts int, response *loader.LoadResponse, loadErr error) {
+	sink := cfg.DeadLetter
+	if sink == nil {
+		return
+	}
+
+	body, err := getBody()
+	if err != nil {
+		log.Errorf("Failed to read batch for dead-letter sink: %v", err)
+		return
+	}
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		log.Errorf("Failed to read batch for dead-letter sink: %v", err)
+		return
+	}
+
+	record := &deadletter.Record{
+		Reason:     reason,
+		Database:   cfg.Database,
+		Table:      cfg.Table,
+		MultiTable: opts.multiTable,
+		Label:      req.Header.Get("label"),
+		Headers:    make(map[string]string),
+		Attempts:   attempts,
+		Payload:    payload,
+	}
+	for key := range req.Header {
+		// Credentials are never persisted, they are added again on replay. The label is kept in
+		// Label only, replays load under a label derived from it.
+		if strings.EqualFold(key, "Authorization") || strings.EqualFold(key, "label") {
+			continue
+		}
+		record.Headers[key] = req.Header.Get(key)
+	}
+	if loadErr != nil {
+		record.Error = loadErr.Error()
+	} else if response != nil && response.ErrorMessage != "" {
+		record.Error = response.ErrorMessage
+	}
+	if response != nil {
+		record.Response = response.Resp.String()
+	}
+
+	if err := sink.Write(record); err != nil {
+		log.Errorf("Failed to write batch with label %s to dead-letter sink: %v", record.Label, err)
+		return
+	}
+	log.Warnf("Batch with label %s sent to dead-letter sink (reason: %s)", record.Label, reason)
+}
+
+// Replay re-submits every failed batch stored in dir by a deadletter.DirSink
+// Stored headers are reused and the label gets a fixed suffix, so replaying the same entry twice
+// does not load it twice. Entries captured without a label, e.g. from group commit loads, are
+// replayed without one and may be loaded twice if a replay is interrupted before the entry is
+// removed. Entries are removed after a successful load and kept otherwise.
+// Filtered entries are not replayed: Doris committed their other rows, so loading the batch again
+// would duplicate them. They are reported in Skipped, their response names the ErrorURL of the rejected rows.
+func (c *DorisLoadClient) Replay(dir string) (*ReplayResult, error) {
+	entries, err := deadletter.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplayResult{}
+	for _, entry := range entries {
+		if entry.Record.Reason == deadletter.ReasonFiltered {
+			log.Warnf("Skipping dead-letter entry %s: its batch was loaded with filtered rows", entry.Path)
+			result.Skipped = append(result.Skipped, entry.Path)
+			continue
+		}
+		if err := c.replayEntry(entry); err != nil {
+			log.Errorf("Failed to replay dead-letter entry %s: %v", entry.Path, err)
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", entry.Path, err))
+			continue
+		}
+
+		if err := entry.Remove(); err != nil {
+			return result, fmt.Errorf("failed to remove replayed entry %s: %w", entry.Path, err)
+		}
+		result.Replayed++
+	}
+
+	log.Infof("Dead-letter replay finished: %d replayed, %d failed, %d skipped", result.Replayed, result.Failed, len(result.Skipped))
+	return result, nil
+}
+
+// replayEntry loads a single dead-letter entry
+func (c *DorisLoadClient) replayEntry(entry *deadletter.Entry) error {
+	payload, err := entry.ReadPayload()
+	if err != nil {
+		return fmt.Errorf("failed to read payload: %w", err)
+	}
+
+	headers := make(map[string]string, len(entry.Record.Headers))
+	for key, value := range entry.Record.Headers {
+		headers[key] = value
+	}
+	if entry.Record.Label != "" {
+		headers["label"] = replayLabel(entry.Record.Label)
+	}
+
+	// Entries are loaded into the table they were captured for, which may differ from the client's;
+	// multi-table entries are routed by the table prefix of their lines again
+	cfg, err := c.tableConfig(entry.Record.Database, entry.Record.Table, nil)
+	if err != nil {
+		return err
+	}
+	opts := &loadOptions{headers: headers, skipDeadLetter: true, multiTable: entry.Record.MultiTable}
+	response, err := c.load(context.Background(), cfg, bytes.NewReader(payload), opts)
+	if err != nil && response != nil && response.Resp.Status == labelAlreadyExistsStatus {
+		if !existingJobLoaded(response) {
+			// The job of a previous replay may still abort, the entry is kept until it is visible
+			return fmt.Errorf("label %s already exists with job status %s: %w", response.Resp.Label, response.Resp.ExistingJobStatus, err)
+		}
+		log.Infof("Label %s already exists (job status: %s), treating entry as replayed", response.Resp.Label, response.Resp.ExistingJobStatus)
+		return nil
+	}
+	return err
+}
+
+// existingJobLoaded reports whether the existing job of a duplicated label loaded its data
+func existingJobLoaded(response *loader.LoadResponse) bool {
+	switch strings.ToUpper(response.Resp.ExistingJobStatus) {
+	case existingJobVisible, existingJobFinished:
+		return true
+	}
+	return false
+}
+
+// replayLabel returns the label used when replaying a batch stored with label
+func replayLabel(label string) string {
+	if strings.HasSuffix(label, replayLabelSuffix) {
+		return label
+	}
+	return label + replayLabelSuffix
+}
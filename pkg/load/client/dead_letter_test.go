@@ -0,0 +1,125 @@
+package client_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/deadletter"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestReplayDeadLetterEntries(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	dir := t.TempDir()
+	sink, err := deadletter.NewDirSink(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := server.NewClient(t, config.WithDeadLetter(sink), config.WithRetry(&config.Retry{}))
+
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultLoadFailed, Message: "bad batch", Times: 1})
+	if _, err := c.Load(strings.NewReader("1,alice\n"), config.WithLabel("batch_1")); err == nil {
+		t.Fatal("expected the load to fail")
+	}
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultFilterRows, FilteredRows: 1, Times: 1})
+	if _, err := c.Load(strings.NewReader("x,bad\n2,bob\n"), config.WithOption("max_filter_ratio", "0.5")); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := deadletter.ReadDir(dir)
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d: %v", len(entries), err)
+	}
+	failed := entries[0].Record
+	if failed.Reason != deadletter.ReasonFailed || failed.Label != "batch_1" || failed.Table != "users" {
+		t.Errorf("unexpected entry %+v", failed)
+	}
+	for key := range failed.Headers {
+		if strings.EqualFold(key, "label") || strings.EqualFold(key, "Authorization") {
+			t.Errorf("header %s must not be stored", key)
+		}
+	}
+
+	// The failed batch is loaded under the replay label, the filtered one is only reported
+	result, err := c.Replay(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Replayed != 1 || result.Failed != 0 || len(result.Skipped) != 1 || result.Skipped[0] != entries[1].Path {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if rows := server.Rows("test_db", "users"); strings.Join(rows, " ") != "2,bob 1,alice" {
+		t.Errorf("unexpected rows %q", rows)
+	}
+	if state := server.LoadState("test_db", "batch_1_replay"); state != dorismock.StateVisible {
+		t.Errorf("expected the replay label to be visible, got %s", state)
+	}
+	if remaining, _ := deadletter.ReadDir(dir); len(remaining) != 1 || remaining[0].Record.Reason != deadletter.ReasonFiltered {
+		t.Errorf("only the filtered entry must remain, got %d entries", len(remaining))
+	}
+
+	// An entry whose replay label already exists was loaded by an earlier replay and is removed
+	failed.Payload = []byte("1,alice\n")
+	if err := sink.Write(&failed); err != nil {
+		t.Fatal(err)
+	}
+	result, err = c.Replay(dir)
+	if err != nil || result.Replayed != 1 || result.Failed != 0 {
+		t.Errorf("unexpected result %+v: %v", result, err)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 2 {
+		t.Errorf("the batch must not be loaded twice, got %q", rows)
+	}
+
+	// A replay label whose job is still running may abort, the entry is kept
+	if err := sink.Write(&failed); err != nil {
+		t.Fatal(err)
+	}
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultLabelExists, ExistingJobStatus: "RUNNING", Times: 1})
+	result, err = c.Replay(dir)
+	if err != nil || result.Replayed != 0 || result.Failed != 1 {
+		t.Errorf("unexpected result %+v: %v", result, err)
+	}
+	if remaining, _ := deadletter.ReadDir(dir); len(remaining) != 2 {
+		t.Errorf("the entry must be kept, got %d entries", len(remaining))
+	}
+}
+
+func TestReplayMultiTableDeadLetter(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	dir := t.TempDir()
+	sink, err := deadletter.NewDirSink(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := server.NewClient(t, config.WithDeadLetter(sink), config.WithRetry(&config.Retry{}))
+
+	w, err := c.NewMultiTableWriter("test_db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("users", "1,Alice")
+	w.WriteString("orders", "100,1")
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultLoadFailed, Message: "bad batch", Times: 1})
+	if _, err := w.Flush(); err == nil {
+		t.Fatal("expected the flush to fail")
+	}
+
+	entries, err := deadletter.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].Record.MultiTable {
+		t.Fatalf("expected one multi-table entry, got %d: %v", len(entries), err)
+	}
+	result, err := c.Replay(dir)
+	if err != nil || result.Replayed != 1 {
+		t.Fatalf("unexpected result %+v: %v", result, err)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 1 || rows[0] != "1,Alice" {
+		t.Errorf("unexpected users rows: %q", rows)
+	}
+	if rows := server.Rows("test_db", "orders"); len(rows) != 1 || rows[0] != "100,1" {
+		t.Errorf("unexpected orders rows: %q", rows)
+	}
+}
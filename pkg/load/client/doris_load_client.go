@@ -3,16 +3,23 @@ package client
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/deadletter"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/exception"
 	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
 	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/util"
 )
 
 // Pre-compiled error patterns for efficient matching
@@ -52,8 +59,17 @@ var (
 
 // DorisLoadClient is the main client interface for loading data into Doris
 type DorisLoadClient struct {
-	streamLoader *loader.StreamLoader
-	config       *config.Config
+	streamLoader   *loader.StreamLoader
+	config         atomic.Pointer[config.Config] // Immutable snapshot, replaced by Reconfigure
+	pool           *workerPool
+	limiter        *loadLimiter      // Limiter of the client's own table, or of every load when not shared by table
+	limits         *config.RateLimit // RateLimit the client was created with, Reconfigure does not change it
+	tableStats     sync.Map          // "database.table" -> *tableCounters
+	sharedLimiters sync.Map          // "database.table" -> *loadLimiter shared with other clients, released by Close
+	schemas        sync.Map          // "database.table" -> *loader.TableSchema, cached for ValidateSchema
+	backpressure   *backpressureController
+
+	throttleStats throttleCounters
 }
 
 // NewDorisClient creates a new DorisLoadClient instance with the given configuration
@@ -63,10 +79,55 @@ func NewDorisClient(cfg *config.Config) (*DorisLoadClient, error) {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	return &DorisLoadClient{
-		streamLoader: loader.NewStreamLoader(),
-		config:       cfg,
-	}, nil
+	poolCfg := cfg.WorkerPool
+	if poolCfg == nil {
+		poolCfg = &config.WorkerPool{}
+	}
+
+	// Keep a private copy so callers can reuse or mutate their Config without racing with loads
+	cfg = cfg.Clone()
+
+	streamLoader := loader.NewStreamLoader()
+	if cfg.Transport != nil {
+		streamLoader = loader.NewStreamLoaderWithClient(util.NewHttpClient(cfg.Transport))
+	}
+
+	c := &DorisLoadClient{
+		streamLoader: streamLoader,
+		pool:         newWorkerPool(poolCfg.Workers, poolCfg.QueueSize, poolCfg.FailFast),
+		limits:       cfg.RateLimit,
+		backpressure: newBackpressureController(cfg.Backpressure),
+	}
+	c.limiter = c.newClientLimiter(cfg)
+	c.config.Store(cfg)
+	return c, nil
+}
+
+// Close shuts down the worker pool, waiting for queued loads, and releases the rate limits the
+// client shares with other clients; the client must not be used afterwards
+func (c *DorisLoadClient) Close() error {
+	err := c.Shutdown(context.Background())
+	c.releaseLimiters()
+	return err
+}
+
+// Reconfigure replaces the configuration used by subsequent loads
+// Loads already running finish with the configuration they started with. Endpoints, credentials,
+// target table, format, retry and options take effect immediately; WorkerPool, RateLimit,
+// Backpressure and Transport keep the settings the client was created with.
+func (c *DorisLoadClient) Reconfigure(cfg *config.Config) error {
+	if err := cfg.ValidateInternal(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	c.config.Store(cfg.Clone())
+	log.Infof("Client reconfigured: target %s.%s, %d endpoints", cfg.Database, cfg.Table, len(cfg.Endpoints))
+	return nil
+}
+
+// Config returns a copy of the configuration used by new loads
+func (c *DorisLoadClient) Config() *config.Config {
+	return c.config.Load().Clone()
 }
 
 // isRetryableError determines if an error should trigger a retry
@@ -108,6 +169,12 @@ func isRetryableError(err error, response *loader.LoadResponse) bool {
 	return false
 }
 
+// isUnauthorized reports whether err is a stream load rejected with HTTP 401
+func isUnauthorized(err error) bool {
+	var loadErr *exception.StreamLoadError
+	return errors.As(err, &loadErr) && loadErr.StatusCode == http.StatusUnauthorized
+}
+
 // calculateBackoffInterval calculates exponential backoff interval with dynamic maximum
 // The maximum interval is constrained to ensure total retry time stays within limits
 func calculateBackoffInterval(attempt int, baseIntervalMs int64, maxTotalTimeMs int64, currentRetryTimeMs int64) time.Duration {
@@ -144,12 +211,34 @@ func calculateBackoffInterval(attempt int, baseIntervalMs int64, maxTotalTimeMs
 	return time.Duration(intervalMs) * time.Millisecond
 }
 
+// loadOptions holds per-call settings of the internal load path
+type loadOptions struct {
+	headers        map[string]string // Headers set over the generated ones, e.g. a fixed label
+	skipDeadLetter bool              // Do not hand the batch to the dead-letter sink
+	multiTable     bool              // Body lines are routed to tables by their "table|" prefix
+}
+
 // Load sends data to Doris via HTTP stream load with retry logic
-func (c *DorisLoadClient) Load(reader io.Reader) (*loader.LoadResponse, error) {
+// Options override the client configuration for this call only, e.g. Load(r, config.WithLabel("batch_42")).
+func (c *DorisLoadClient) Load(reader io.Reader, opts ...LoadOption) (*loader.LoadResponse, error) {
+	return c.LoadContext(context.Background(), reader, opts...)
+}
+
+// LoadContext is like Load but stops waiting for throttling, retries and the HTTP request when ctx ends
+func (c *DorisLoadClient) LoadContext(ctx context.Context, reader io.Reader, opts ...LoadOption) (*loader.LoadResponse, error) {
+	cfg, err := withLoadOptions(c.config.Load(), opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.load(ctx, cfg, reader, &loadOptions{})
+}
+
+// load implements Load against the target described by cfg with the given per-call options
+func (c *DorisLoadClient) load(ctx context.Context, cfg *config.Config, reader io.Reader, opts *loadOptions) (*loader.LoadResponse, error) {
 	operationStartTime := time.Now()
 
 	// Step 1: Configuration preparation
-	retry := c.config.Retry
+	retry := cfg.Retry
 	if retry == nil {
 		retry = &config.Retry{MaxRetryTimes: 6, BaseIntervalMs: 1000, MaxTotalTimeMs: 60000}
 	}
@@ -159,7 +248,14 @@ func (c *DorisLoadClient) Load(reader io.Reader) (*loader.LoadResponse, error) {
 	maxTotalTimeMs := retry.MaxTotalTimeMs
 
 	log.Infof("Starting stream load operation")
-	log.Infof("Target: %s.%s", c.config.Database, c.config.Table)
+	log.Infof("Target: %s.%s", cfg.Database, cfg.Table)
+
+	// Catch column mismatches before sending data rather than with "too many filtered rows"
+	if cfg.ValidateSchema && !opts.multiTable {
+		if err := c.validateColumns(ctx, cfg); err != nil {
+			return nil, err
+		}
+	}
 
 	// Show the actual retry strategy to avoid confusion
 	if maxRetries > 0 {
@@ -181,10 +277,17 @@ func (c *DorisLoadClient) Load(reader io.Reader) (*loader.LoadResponse, error) {
 
 	// Prepare for retries by handling reader consumption
 	var getBodyFunc func() (io.Reader, error)
+	var bodySize int64
 
 	// Check if reader supports seeking
 	if seeker, ok := reader.(io.Seeker); ok {
 		// Reader supports seeking, we can reuse it
+		size, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seek to end: %w", err)
+		}
+		bodySize = size
+
 		getBodyFunc = func() (io.Reader, error) {
 			if _, err := seeker.Seek(0, io.SeekStart); err != nil {
 				return nil, fmt.Errorf("failed to seek to start: %w", err)
@@ -197,6 +300,7 @@ func (c *DorisLoadClient) Load(reader io.Reader) (*loader.LoadResponse, error) {
 		if _, err := buf.ReadFrom(reader); err != nil {
 			return nil, fmt.Errorf("failed to buffer reader content: %w", err)
 		}
+		bodySize = int64(buf.Len())
 
 		getBodyFunc = func() (io.Reader, error) {
 			// Return a copy of the buffer so it's not consumed
@@ -205,20 +309,26 @@ func (c *DorisLoadClient) Load(reader io.Reader) (*loader.LoadResponse, error) {
 	}
 
 	var lastErr error
+	var lastReq *http.Request
 	var response *loader.LoadResponse
+	attempts := 0
 	startTime := time.Now()
 	totalRetryTime := int64(0)
+	credentialsRefreshed := false
+	resendAttempt := false
 
 	// Try the operation with retries
 	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
+		if resendAttempt {
+			log.Infof("Resending attempt %d with refreshed credentials", attempt+1)
+		} else if attempt > 0 {
 			log.Infof("Retry attempt %d/%d", attempt, maxRetries)
 		} else {
 			log.Infof("Initial load attempt")
 		}
 
 		// Calculate and apply backoff delay for retries
-		if attempt > 0 {
+		if attempt > 0 && !resendAttempt {
 			backoffInterval := calculateBackoffInterval(attempt, baseIntervalMs, maxTotalTimeMs, totalRetryTime)
 
 			// Check if this delay would exceed the total time limit
@@ -229,7 +339,11 @@ func (c *DorisLoadClient) Load(reader io.Reader) (*loader.LoadResponse, error) {
 			}
 
 			log.Infof("Waiting %v before retry attempt (total retry time so far: %dms)", backoffInterval, totalRetryTime)
-			time.Sleep(backoffInterval)
+			if err := sleepContext(ctx, backoffInterval); err != nil {
+				log.Warnf("Load canceled while waiting for retry: %v", err)
+				lastErr = err
+				break
+			}
 			totalRetryTime += backoffInterval.Milliseconds()
 		}
 
@@ -242,25 +356,69 @@ func (c *DorisLoadClient) Load(reader io.Reader) (*loader.LoadResponse, error) {
 		}
 
 		// Create the HTTP request
-		req, err := loader.CreateStreamLoadRequest(c.config, currentReader, attempt)
+		createRequest := loader.CreateStreamLoadRequest
+		if opts.multiTable {
+			createRequest = loader.CreateMultiTableStreamLoadRequest
+		}
+		req, err := createRequest(cfg, currentReader, attempt)
 		if err != nil {
 			log.Errorf("Failed to create HTTP request: %v", err)
 			lastErr = fmt.Errorf("failed to create request: %w", err)
 			// Request creation failure is usually not retryable (config issue)
 			break
 		}
+		for key, value := range opts.headers {
+			req.Header.Set(key, value)
+		}
+		req = req.WithContext(ctx)
+
+		// Wait for client-side rate limits before hitting the BE
+		release, err := c.throttle(ctx, cfg, bodySize)
+		if err != nil {
+			log.Warnf("Load canceled while throttled: %v", err)
+			lastErr = err
+			break
+		}
+		lastReq = req
+		attempts++
 
 		// Execute the actual load operation
 		response, lastErr = c.streamLoader.Load(req)
+		release(response, lastErr)
+
+		// Credentials may have been rotated: refresh them once and resend the same attempt
+		resendAttempt = false
+		if isUnauthorized(lastErr) && cfg.Credentials != nil && !credentialsRefreshed {
+			credentialsRefreshed = true
+			if err := cfg.Credentials.Refresh(); err != nil {
+				log.Errorf("Failed to refresh credentials after 401 Unauthorized: %v", err)
+			} else {
+				log.Warnf("Stream load rejected with 401 Unauthorized, credentials refreshed")
+				resendAttempt = true
+				attempt--
+				continue
+			}
+		}
 
 		// If successful, return immediately
 		if lastErr == nil && response != nil && response.Status == loader.SUCCESS {
 			log.Infof("Stream load operation completed successfully on attempt %d", attempt+1)
+			if response.Resp.NumberFilteredRows > 0 && !opts.skipDeadLetter {
+				c.sendToDeadLetter(cfg, opts, deadletter.ReasonFiltered, getBodyFunc, lastReq, attempts, response, nil)
+			}
+			if !opts.multiTable {
+				c.recordTableLoad(cfg, bodySize, time.Since(operationStartTime), response, nil)
+			}
 			return response, nil
 		}
 
 		// Check if this error/response should be retried
 		shouldRetry := isRetryableError(lastErr, response)
+		if c.backpressure != nil && isRetryableOverload(response, lastErr) {
+			// The backpressure window already slowed down, retrying is safe
+			shouldRetry = true
+		}
+		shouldRetry = shouldRetry && ctx.Err() == nil
 
 		if lastErr != nil {
 			log.Errorf("Attempt %d failed with error: %v (retryable: %t)", attempt+1, lastErr, shouldRetry)
@@ -295,6 +453,13 @@ func (c *DorisLoadClient) Load(reader io.Reader) (*loader.LoadResponse, error) {
 	totalOperationTime := time.Since(operationStartTime)
 	log.Debugf("[TIMING] Total operation time: %v", totalOperationTime)
 
+	if lastReq != nil && !opts.skipDeadLetter {
+		c.sendToDeadLetter(cfg, opts, deadletter.ReasonFailed, getBodyFunc, lastReq, attempts, response, lastErr)
+	}
+	if !opts.multiTable {
+		c.recordTableLoad(cfg, bodySize, totalOperationTime, response, lastErr)
+	}
+
 	if lastErr != nil {
 		log.Errorf("Stream load operation failed after %d attempts: %v", maxRetries+1, lastErr)
 		return response, lastErr
@@ -302,9 +467,53 @@ func (c *DorisLoadClient) Load(reader io.Reader) (*loader.LoadResponse, error) {
 
 	if response != nil {
 		log.Errorf("Stream load operation failed with final status: %v", response.Status)
+		if cfg.ErrorLogRows > 0 && response.Resp.ErrorURL != "" {
+			return response, c.rejectedRowsError(response, cfg.ErrorLogRows)
+		}
 		return response, fmt.Errorf("load failed with status: %v", response.Status)
 	}
 
 	log.Errorf("Stream load operation failed with unknown error after %d attempts (total time: %v)", maxRetries+1)
 	return nil, fmt.Errorf("load failed: unknown error")
 }
+
+// FetchErrorLog downloads the error log referenced by resp.Resp.ErrorURL and parses
+// every rejected row it contains
+func (c *DorisLoadClient) FetchErrorLog(resp *loader.LoadResponse) ([]loader.RejectedRow, error) {
+	if resp == nil || resp.Resp.ErrorURL == "" {
+		return nil, fmt.Errorf("response has no error url")
+	}
+	return c.streamLoader.FetchErrorLog(resp.Resp.ErrorURL, 0)
+}
+
+// rejectedRowsError builds the error returned for a failed load when ErrorLogRows is enabled
+// If the error log cannot be fetched, the error still carries the response status and message
+func (c *DorisLoadClient) rejectedRowsError(resp *loader.LoadResponse, maxRows int) error {
+	rows, err := c.streamLoader.FetchErrorLog(resp.Resp.ErrorURL, maxRows)
+	if err != nil {
+		log.Warnf("Failed to fetch error log from %s: %v", resp.Resp.ErrorURL, err)
+	}
+
+	return &loader.RejectedRowsError{
+		Status:   resp.Resp.Status,
+		Message:  resp.Resp.Message,
+		ErrorURL: resp.Resp.ErrorURL,
+		Rows:     rows,
+	}
+}
+
+// sleepContext sleeps for d or until ctx ends, returning ctx.Err() in the latter case
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,210 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/faultinject"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+)
+
+func TestLoadFollowsRedirect(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t)
+
+	response, err := c.Load(strings.NewReader("1,Alice\n2,Bob\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Status != loader.SUCCESS || response.Resp.NumberLoadedRows != 2 {
+		t.Errorf("unexpected response: %+v", response.Resp)
+	}
+
+	rows := server.Rows("test_db", "users")
+	if len(rows) != 2 || rows[0] != "1,Alice" || rows[1] != "2,Bob" {
+		t.Errorf("unexpected rows: %q", rows)
+	}
+	if state := server.LoadState("test_db", response.Resp.Label); state != dorismock.StateVisible {
+		t.Errorf("unexpected load state %s", state)
+	}
+}
+
+func TestLoadRetriesServerErrors(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultHTTPStatus, StatusCode: 503, Times: 1})
+	c := server.NewClient(t)
+
+	// 503 is not retried by default, a timeout is
+	if _, err := c.Load(strings.NewReader("1,Alice\n")); err == nil {
+		t.Fatal("expected 503 to fail the load")
+	}
+
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultLoadFailed, Message: "connect to BE timeout", Times: 1})
+	if _, err := c.Load(strings.NewReader("2,Bob\n")); err != nil {
+		t.Fatalf("expected retry to succeed: %v", err)
+	}
+
+	if loads := server.Loads(); len(loads) != 3 {
+		t.Errorf("expected 3 requests, got %d", len(loads))
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 1 || rows[0] != "2,Bob" {
+		t.Errorf("unexpected rows: %q", rows)
+	}
+}
+
+func TestLoadRefreshesCredentialsOnUnauthorized(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	server.SetAuth("root", "rotated")
+
+	var fetches int32
+	provider := config.NewCallbackCredentials(func() (config.Credentials, error) {
+		if atomic.AddInt32(&fetches, 1) == 1 {
+			return config.Credentials{User: "root", Password: "expired"}, nil
+		}
+		return config.Credentials{User: "root", Password: "rotated"}, nil
+	})
+	c := server.NewClient(t, config.WithCredentials(provider))
+
+	if _, err := c.Load(strings.NewReader("1,Alice\n")); err != nil {
+		t.Fatalf("expected load to succeed after refresh: %v", err)
+	}
+	if fetches != 2 {
+		t.Errorf("expected credentials to be fetched twice, got %d", fetches)
+	}
+}
+
+func TestLoadAttachesRejectedRows(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultFilterRows, FilteredRows: 1, Times: 1})
+	c := server.NewClient(t, config.WithErrorLogRows(10))
+
+	_, err := c.Load(strings.NewReader("bad,row\n2,Bob\n"))
+	var rejected *loader.RejectedRowsError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("expected RejectedRowsError, got %v", err)
+	}
+	if len(rejected.Rows) != 1 || rejected.Rows[0].Row != "bad,row" {
+		t.Errorf("unexpected rejected rows: %+v", rejected.Rows)
+	}
+}
+
+func TestReconfigureKeepsInFlightSnapshot(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithRateLimit(&config.RateLimit{MaxConcurrentLoads: 1}))
+	limiter := c.Limiter()
+
+	// The load takes its snapshot before reading the body, which blocks until the pipe is closed
+	body, writer := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.Load(body)
+		done <- err
+	}()
+	if _, err := writer.Write([]byte("1,alice\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := c.Config()
+	cfg.Table = "orders"
+	cfg.Options = map[string]string{"strict_mode": "true"}
+	cfg.RateLimit = &config.RateLimit{MaxConcurrentLoads: 8, SharedByTable: true}
+	if err := c.Reconfigure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Load(strings.NewReader("100,1\n")); err != nil {
+		t.Fatal(err)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 1 || rows[0] != "1,alice" {
+		t.Errorf("the in-flight load must finish with its snapshot, got users rows %q", rows)
+	}
+	if rows := server.Rows("test_db", "orders"); len(rows) != 1 || rows[0] != "100,1" {
+		t.Errorf("new loads must use the new config, got orders rows %q", rows)
+	}
+	loads := server.Loads()
+	if loads[0].Headers.Get("strict_mode") != "" || loads[1].Headers.Get("strict_mode") != "true" {
+		t.Errorf("unexpected headers %v and %v", loads[0].Headers, loads[1].Headers)
+	}
+	if c.LimiterFor(c.Config()) != limiter {
+		t.Error("Reconfigure must not change the rate limits")
+	}
+}
+
+// backendHost returns the host of the fake BE stream loads are redirected to
+func backendHost(t *testing.T, server *dorismock.Server) string {
+	t.Helper()
+	u, err := url.Parse(server.BackendURL())
+	if err != nil {
+		t.Fatalf("invalid backend URL: %v", err)
+	}
+	return u.Host
+}
+
+func TestLoadRetriesConnectionResetMidBody(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+
+	transport := faultinject.New(nil, faultinject.Rule{
+		Kind:  faultinject.FaultResetBody,
+		Match: faultinject.MatchHost(backendHost(t, server)),
+		Nth:   1,
+		Bytes: 4,
+	})
+	c := server.NewClient(t, config.WithTransport(transport))
+
+	if _, err := c.Load(strings.NewReader("1,Alice\n2,Bob\n")); err != nil {
+		t.Fatalf("expected retry to succeed: %v", err)
+	}
+
+	if rows := server.Rows("test_db", "users"); len(rows) != 2 {
+		t.Errorf("expected rows loaded once, got %q", rows)
+	}
+	if stats := transport.Stats(); stats.Faults != 1 {
+		t.Errorf("expected one injected fault, got %+v", stats)
+	}
+
+	loads := server.Loads()
+	last := loads[len(loads)-1]
+	if last.Status != "Success" || !strings.Contains(last.Label, "_retry_1_") {
+		t.Errorf("expected the retry to succeed with a retry label, got %+v", last)
+	}
+}
+
+func TestLoadContextGivesUpOnSlowResponses(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+
+	transport := faultinject.New(nil, faultinject.Rule{Kind: faultinject.FaultLatency, Latency: time.Minute})
+	c := server.NewClient(t, config.WithTransport(transport))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.LoadContext(ctx, strings.NewReader("1,Alice\n")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("load did not stop at the deadline: %v", elapsed)
+	}
+	if len(server.Loads()) != 0 {
+		t.Errorf("no request should reach the BE")
+	}
+}
@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+)
+
+// Internals exposed to the tests of package client_test
+
+var (
+	OverloadSignal    = overloadSignal
+	ChunkBoundaries   = chunkBoundaries
+	EncodeSpoolRecord = encodeSpoolRecord
+)
+
+const (
+	DefaultImportManifest = defaultImportManifest
+	ImportLogSuffix       = importLogSuffix
+	SpoolCursorFile       = spoolCursorFile
+	SpoolSegmentPrefix    = spoolSegmentPrefix
+	SpoolSegmentSuffix    = spoolSegmentSuffix
+	SpoolRejectedDir      = spoolRejectedDir
+)
+
+type (
+	TailCheckpoint = tailCheckpoint
+	TailPending    = tailPending
+	TailRange      = tailRange
+	TailPosition   = tailPosition
+)
+
+// NewTailRange returns the range of the file at pos starting at start
+func NewTailRange(pos TailPosition, start int64) TailRange {
+	return tailRange{tailPosition: pos, Start: start}
+}
+
+// TokenBucket is a tokenBucket with exported methods
+type TokenBucket struct {
+	bucket *tokenBucket
+}
+
+func NewTokenBucket(rate float64) *TokenBucket {
+	return &TokenBucket{bucket: newTokenBucket(rate)}
+}
+
+func (b *TokenBucket) Reserve(n float64) time.Duration { return b.bucket.reserve(n) }
+
+func (b *TokenBucket) Cancel(n float64) { b.bucket.cancel(n) }
+
+func (b *TokenBucket) Wait(ctx context.Context, n float64) (time.Duration, error) {
+	return b.bucket.wait(ctx, n)
+}
+
+// HoldWorkers keeps the worker pool from starting on the first asynchronous load
+func (c *DorisLoadClient) HoldWorkers() {
+	c.pool.startOnce.Do(func() {})
+}
+
+// StartWorker starts one worker of the pool by hand
+func (c *DorisLoadClient) StartWorker() {
+	c.pool.wg.Add(1)
+	go c.runWorker()
+}
+
+// QueuedLoads returns the number of loads waiting in the queue
+func (c *DorisLoadClient) QueuedLoads() int {
+	return len(c.pool.queue)
+}
+
+func (c *DorisLoadClient) Limiter() *loadLimiter {
+	return c.limiter
+}
+
+func (c *DorisLoadClient) LimiterFor(cfg *config.Config) *loadLimiter {
+	return c.limiterFor(cfg)
+}
+
+func (c *DorisLoadClient) TableConfig(database, table string, opts *TableOptions) (*config.Config, error) {
+	return c.tableConfig(database, table, opts)
+}
+
+// SharedLimiterRefs returns the number of clients using the limiter shared by key, and the
+// number of shared limiters left
+func SharedLimiterRefs(key string) (refs, limiters int) {
+	limiterRegistryMu.Lock()
+	defer limiterRegistryMu.Unlock()
+	if shared, ok := limiterRegistry[key]; ok {
+		refs = shared.refs
+	}
+	return refs, len(limiterRegistry)
+}
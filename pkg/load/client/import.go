@@ -0,0 +1,613 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/util"
+)
+
+const (
+	defaultImportChunkBytes = 64 * 1024 * 1024
+	defaultImportParallel   = 4
+	defaultImportManifest   = ".doris-import-manifest.json"
+	importLogSuffix         = ".log"
+)
+
+// ImportOptions configures ImportDirectory
+type ImportOptions struct {
+	ChunkBytes  int64  // Size at which files are cut on a line boundary (default 64MB, or the size an existing manifest was written with)
+	Parallel    int    // Chunks loaded at the same time (default 4)
+	Recursive   bool   // Also import matching files in subdirectories
+	Manifest    string // File recording completed chunks (default .doris-import-manifest.json in the directory), with a ".log" beside it during a run
+	LabelPrefix string // Prefix of the chunk labels (default the client label prefix, or "import")
+
+	// OnChunk is called after each chunk is loaded, skipped or failed, e.g. to report progress
+	// It is called from the loading goroutines.
+	OnChunk func(ImportChunk)
+}
+
+// ImportChunk is a part of a file loaded as one stream load
+type ImportChunk struct {
+	File         string `json:"file"` // Path relative to the imported directory
+	Index        int    `json:"index"`
+	Start        int64  `json:"start"`
+	End          int64  `json:"end"`
+	Label        string `json:"label"`
+	LoadedRows   int64  `json:"loaded_rows"`
+	FilteredRows int64  `json:"filtered_rows"`
+	Skipped      bool   `json:"-"` // Completed by an earlier run, according to the manifest
+	Err          error  `json:"-"`
+}
+
+// ImportFileReport summarizes the import of one file
+// Rows of chunks skipped because an earlier run completed them are included.
+type ImportFileReport struct {
+	File         string
+	Size         int64
+	Chunks       int
+	Skipped      int
+	Failed       int
+	LoadedRows   int64
+	FilteredRows int64
+	Errors       []string
+}
+
+// ImportReport summarizes an ImportDirectory run
+type ImportReport struct {
+	Files        []ImportFileReport
+	Chunks       int
+	Skipped      int
+	Failed       int
+	LoadedRows   int64
+	FilteredRows int64
+	Duration     time.Duration
+}
+
+// String formats the report as a table with one line per file and a total
+func (r *ImportReport) String() string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tSIZE\tCHUNKS\tSKIPPED\tFAILED\tLOADED ROWS\tFILTERED ROWS")
+	for _, f := range r.Files {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%d\n", f.File, f.Size, f.Chunks, f.Skipped, f.Failed, f.LoadedRows, f.FilteredRows)
+	}
+	fmt.Fprintf(w, "TOTAL\t\t%d\t%d\t%d\t%d\t%d\n", r.Chunks, r.Skipped, r.Failed, r.LoadedRows, r.FilteredRows)
+	w.Flush()
+	return sb.String()
+}
+
+// importManifest is the content of the manifest file
+type importManifest struct {
+	ChunkBytes int64                          `json:"chunk_bytes"`
+	Files      map[string]*importManifestFile `json:"files"`
+}
+
+// importManifestFile records the completed chunks of a file as it was when they were loaded
+type importManifestFile struct {
+	Size    int64         `json:"size"`
+	ModTime time.Time     `json:"mod_time"`
+	Chunks  []ImportChunk `json:"chunks"`
+}
+
+// importLogEntry is a line of the manifest log, a chunk completed since the manifest was written
+type importLogEntry struct {
+	Size    int64       `json:"size"`
+	ModTime time.Time   `json:"mod_time"`
+	Chunk   ImportChunk `json:"chunk"`
+}
+
+// importFile is a file selected for import
+type importFile struct {
+	path string // Path on disk
+	rel  string // Path relative to the imported directory
+	info fs.FileInfo
+}
+
+// ImportDirectory loads the files of dir whose names match pattern, e.g. "*.csv"
+// Files are cut on line boundaries into chunks of about opts.ChunkBytes, loaded in parallel.
+// Each chunk gets a label derived from its file, position and content, so Doris rejects a
+// chunk loaded twice, and completed chunks are recorded in a manifest that later runs skip:
+// running the import again after a failure only loads what is missing. Files in JSON array
+// format cannot be cut and are loaded whole. Group commit must be OFF because it does not
+// allow labels.
+func (c *DorisLoadClient) ImportDirectory(dir, pattern string, opts ImportOptions) (*ImportReport, error) {
+	return c.ImportDirectoryContext(context.Background(), dir, pattern, opts)
+}
+
+// ImportDirectoryContext is like ImportDirectory but stops starting chunks when ctx ends
+func (c *DorisLoadClient) ImportDirectoryContext(ctx context.Context, dir, pattern string, opts ImportOptions) (*ImportReport, error) {
+	startTime := time.Now()
+
+	cfg := c.config.Load()
+	if cfg.GroupCommit != config.OFF {
+		return nil, fmt.Errorf("directory import requires group commit OFF, labels are needed for idempotent reruns")
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+	chunkBytesSet := opts.ChunkBytes > 0
+	if !chunkBytesSet {
+		opts.ChunkBytes = defaultImportChunkBytes
+	}
+	if opts.Parallel <= 0 {
+		opts.Parallel = defaultImportParallel
+	}
+	if opts.Manifest == "" {
+		opts.Manifest = filepath.Join(dir, defaultImportManifest)
+	}
+	if opts.LabelPrefix == "" {
+		opts.LabelPrefix = cfg.LabelPrefix
+	}
+	if opts.LabelPrefix == "" {
+		opts.LabelPrefix = "import"
+	}
+
+	manifest, err := readImportManifest(opts.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.ChunkBytes > 0 && manifest.ChunkBytes != opts.ChunkBytes {
+		// Chunks are only recognized if files are cut the same way as before
+		if chunkBytesSet {
+			return nil, fmt.Errorf("chunk size %d differs from the chunk size %d of manifest %s, use the same size or another manifest",
+				opts.ChunkBytes, manifest.ChunkBytes, opts.Manifest)
+		}
+		log.Infof("Using chunk size %d of the existing manifest instead of the default %d", manifest.ChunkBytes, opts.ChunkBytes)
+		opts.ChunkBytes = manifest.ChunkBytes
+	}
+	manifest.ChunkBytes = opts.ChunkBytes
+
+	files, err := listImportFiles(dir, pattern, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	delimiter := []byte("\n")
+	splittable := true
+	switch format := cfg.Format.(type) {
+	case *config.CSVFormat:
+		delimiter = []byte(config.UnescapeDelimiter(format.LineDelimiter))
+	case *config.JSONFormat:
+		splittable = format.Type != config.JSONArray
+	}
+
+	// Cut every file first, so the report lists all chunks even if the import is canceled
+	var chunks []ImportChunk
+	reports := make(map[string]*ImportFileReport)
+	for _, f := range files {
+		done := manifest.completed(f)
+		ends := []int64{f.info.Size()}
+		if splittable {
+			if ends, err = chunkBoundaries(f.path, f.info.Size(), opts.ChunkBytes, delimiter); err != nil {
+				return nil, err
+			}
+		}
+
+		reports[f.rel] = &ImportFileReport{File: f.rel, Size: f.info.Size(), Chunks: len(ends)}
+		var start int64
+		for i, end := range ends {
+			chunk := ImportChunk{File: f.rel, Index: i, Start: start, End: end}
+			if previous, ok := done[start]; ok && previous.End == end {
+				chunk = previous
+				chunk.Skipped = true
+			}
+			chunks = append(chunks, chunk)
+			start = end
+		}
+	}
+
+	importer := &directoryImport{
+		client:   c,
+		opts:     opts,
+		manifest: manifest,
+		files:    make(map[string]*importFile),
+		reports:  reports,
+	}
+	for i := range files {
+		importer.files[files[i].rel] = &files[i]
+	}
+	if err := importer.openLog(); err != nil {
+		return nil, err
+	}
+	importer.run(ctx, chunks)
+	importer.closeLog()
+
+	report := &ImportReport{Duration: time.Since(startTime)}
+	for _, f := range files {
+		r := reports[f.rel]
+		report.Files = append(report.Files, *r)
+		report.Chunks += r.Chunks
+		report.Skipped += r.Skipped
+		report.Failed += r.Failed
+		report.LoadedRows += r.LoadedRows
+		report.FilteredRows += r.FilteredRows
+	}
+
+	log.Infof("Imported %d files from %s: %d chunks, %d skipped, %d failed, %d rows loaded, %d filtered in %v",
+		len(files), dir, report.Chunks, report.Skipped, report.Failed, report.LoadedRows, report.FilteredRows, report.Duration)
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	if report.Failed > 0 {
+		return report, fmt.Errorf("%d of %d chunks failed to load", report.Failed, report.Chunks)
+	}
+	return report, nil
+}
+
+// directoryImport is the state of an ImportDirectory run
+type directoryImport struct {
+	client *DorisLoadClient
+	opts   ImportOptions
+	files  map[string]*importFile
+
+	mu       sync.Mutex
+	manifest *importManifest
+	journal  *os.File // Manifest log, completed chunks are appended to it instead of rewriting the manifest
+	reports  map[string]*ImportFileReport
+}
+
+// openLog writes the manifest, including the log of an earlier run, and starts an empty log
+func (d *directoryImport) openLog() error {
+	if err := d.manifest.save(d.opts.Manifest); err != nil {
+		return fmt.Errorf("failed to write import manifest: %w", err)
+	}
+	f, err := os.OpenFile(d.opts.Manifest+importLogSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open import manifest log: %w", err)
+	}
+	d.journal = f
+	return nil
+}
+
+// closeLog writes the manifest with the chunks completed by this run and removes the log
+// If the manifest cannot be written the log is kept, the next run reads it.
+func (d *directoryImport) closeLog() {
+	if err := d.journal.Close(); err != nil {
+		log.Warnf("Failed to close import manifest log: %v", err)
+	}
+	if err := d.manifest.save(d.opts.Manifest); err != nil {
+		log.Errorf("Failed to write import manifest: %v", err)
+		return
+	}
+	if err := os.Remove(d.opts.Manifest + importLogSuffix); err != nil {
+		log.Warnf("Failed to remove import manifest log: %v", err)
+	}
+}
+
+// appendLog durably records a completed chunk of f in the manifest log
+func (d *directoryImport) appendLog(f *importFile, chunk ImportChunk) error {
+	line, err := json.Marshal(importLogEntry{Size: f.info.Size(), ModTime: f.info.ModTime(), Chunk: chunk})
+	if err != nil {
+		return err
+	}
+	if _, err := d.journal.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return d.journal.Sync()
+}
+
+// run loads the chunks with opts.Parallel workers
+func (d *directoryImport) run(ctx context.Context, chunks []ImportChunk) {
+	jobs := make(chan ImportChunk)
+	var wg sync.WaitGroup
+	for i := 0; i < d.opts.Parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range jobs {
+				if !chunk.Skipped {
+					chunk = d.load(ctx, chunk)
+				}
+				d.record(chunk)
+			}
+		}()
+	}
+
+	for _, chunk := range chunks {
+		if !chunk.Skipped && ctx.Err() != nil {
+			chunk.Err = ctx.Err()
+		}
+		if chunk.Err != nil {
+			d.record(chunk)
+			continue
+		}
+		jobs <- chunk
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// load reads a chunk and loads it under its label
+func (d *directoryImport) load(ctx context.Context, chunk ImportChunk) ImportChunk {
+	f := d.files[chunk.File]
+	data, err := readChunk(f.path, chunk.Start, chunk.End)
+	if err != nil {
+		chunk.Err = err
+		return chunk
+	}
+	chunk.Label = importLabel(d.opts.LabelPrefix, chunk, data)
+
+	cfg := d.client.config.Load()
+	// Failed chunks are reported and loaded again by the next run, not dead-lettered
+	opts := &loadOptions{headers: map[string]string{"label": chunk.Label}, skipDeadLetter: true}
+	response, err := d.client.load(ctx, cfg, bytes.NewReader(data), opts)
+	if err != nil {
+		if response != nil && response.Resp.Status == labelAlreadyExistsStatus && existingJobLoaded(response) {
+			// Loaded by a run whose manifest update was lost, Doris does not return the row counts
+			log.Infof("Chunk %d of %s was already loaded as %s", chunk.Index, chunk.File, chunk.Label)
+			return chunk
+		}
+		if response != nil && response.Resp.Message != "" && !strings.Contains(err.Error(), response.Resp.Message) {
+			err = fmt.Errorf("%w: %s", err, response.Resp.Message)
+		}
+		chunk.Err = err
+		return chunk
+	}
+
+	chunk.LoadedRows = response.Resp.NumberLoadedRows
+	chunk.FilteredRows = int64(response.Resp.NumberFilteredRows)
+	return chunk
+}
+
+// record adds a chunk outcome to the report and the manifest
+func (d *directoryImport) record(chunk ImportChunk) {
+	d.mu.Lock()
+	r := d.reports[chunk.File]
+	switch {
+	case chunk.Err != nil:
+		r.Failed++
+		r.Errors = append(r.Errors, fmt.Sprintf("chunk %d [%d, %d): %v", chunk.Index, chunk.Start, chunk.End, chunk.Err))
+	case chunk.Skipped:
+		r.Skipped++
+	}
+	if chunk.Err == nil {
+		r.LoadedRows += chunk.LoadedRows
+		r.FilteredRows += chunk.FilteredRows
+	}
+
+	if chunk.Err == nil && !chunk.Skipped {
+		f := d.files[chunk.File]
+		d.manifest.add(f.rel, f.info.Size(), f.info.ModTime(), chunk)
+		if err := d.appendLog(f, chunk); err != nil {
+			log.Errorf("Failed to record chunk %d of %s in the import manifest log: %v", chunk.Index, chunk.File, err)
+		}
+	}
+	d.mu.Unlock()
+
+	if chunk.Err != nil {
+		log.Warnf("Failed to import chunk %d of %s: %v", chunk.Index, chunk.File, chunk.Err)
+	}
+	if d.opts.OnChunk != nil {
+		d.opts.OnChunk(chunk)
+	}
+}
+
+// listImportFiles returns the regular files of dir matching pattern, sorted by path
+func listImportFiles(dir, pattern string, opts ImportOptions) ([]importFile, error) {
+	manifest, err := filepath.Abs(opts.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []importFile
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && !opts.Recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if matched, _ := filepath.Match(pattern, entry.Name()); !matched || !entry.Type().IsRegular() {
+			return nil
+		}
+		if abs, err := filepath.Abs(path); err == nil && (abs == manifest || abs == manifest+".tmp" || abs == manifest+importLogSuffix) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, importFile{path: path, rel: filepath.ToSlash(rel), info: info})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].rel < files[j].rel })
+	return files, nil
+}
+
+// chunkBoundaries returns the end offsets of the chunks of a file
+// A chunk ends after the first delimiter found once it reached chunkBytes.
+func chunkBoundaries(path string, size, chunkBytes int64, delimiter []byte) ([]int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ends []int64
+	var start int64
+	for start < size {
+		end := start + chunkBytes
+		if end >= size {
+			ends = append(ends, size)
+			break
+		}
+
+		// Start looking before the cut so a delimiter spanning it is found
+		from := end - int64(len(delimiter)) + 1
+		if from <= start {
+			from = start + 1
+		}
+		if end, err = findDelimiter(f, from, delimiter); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if end < 0 {
+			end = size
+		}
+		ends = append(ends, end)
+		start = end
+	}
+	return ends, nil
+}
+
+// findDelimiter returns the offset following the first delimiter at or after from, or -1
+func findDelimiter(f *os.File, from int64, delimiter []byte) (int64, error) {
+	buf := make([]byte, 64*1024+len(delimiter)-1)
+	kept := 0      // Bytes kept from the previous window, a delimiter may start in them
+	offset := from // File offset of buf[0]
+	for {
+		n, err := f.ReadAt(buf[kept:], offset+int64(kept))
+		window := buf[:kept+n]
+		if i := bytes.Index(window, delimiter); i >= 0 {
+			return offset + int64(i+len(delimiter)), nil
+		}
+		if err == io.EOF {
+			return -1, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		kept = len(delimiter) - 1
+		if kept > len(window) {
+			kept = len(window)
+		}
+		copy(buf, window[len(window)-kept:])
+		offset += int64(len(window) - kept)
+	}
+}
+
+// readChunk reads the bytes [start, end) of a file
+func readChunk(path string, start, end int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data := make([]byte, end-start)
+	if _, err := f.ReadAt(data, start); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// importLabel derives the label of a chunk from its file, position and content
+func importLabel(prefix string, chunk ImportChunk, data []byte) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%d|%d|%08x", chunk.File, chunk.Start, chunk.End, crc32.ChecksumIEEE(data))
+	return fmt.Sprintf("%s_%016x_%d", prefix, h.Sum64(), chunk.Index)
+}
+
+// readImportManifest reads the manifest at path and the chunks its log recorded since, neither may exist yet
+func readImportManifest(path string) (*importManifest, error) {
+	manifest := &importManifest{Files: make(map[string]*importManifestFile)}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read import manifest: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse import manifest: %w", err)
+		}
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]*importManifestFile)
+	}
+
+	data, err = os.ReadFile(path + importLogSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import manifest log: %w", err)
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry importLogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			// A torn write of a run that crashed, the chunk is loaded again and Doris rejects its label
+			log.Warnf("Ignoring invalid import manifest log entry: %v", err)
+			continue
+		}
+		manifest.add(entry.Chunk.File, entry.Size, entry.ModTime, entry.Chunk)
+	}
+	return manifest, nil
+}
+
+// completed returns the chunks of f completed by earlier runs by start offset
+// Chunks recorded for an earlier version of the file are forgotten.
+func (m *importManifest) completed(f importFile) map[int64]ImportChunk {
+	entry, ok := m.Files[f.rel]
+	if !ok {
+		return nil
+	}
+	if entry.Size != f.info.Size() || !entry.ModTime.Equal(f.info.ModTime()) {
+		log.Infof("%s changed since it was imported, importing it again", f.rel)
+		delete(m.Files, f.rel)
+		return nil
+	}
+
+	done := make(map[int64]ImportChunk, len(entry.Chunks))
+	for _, chunk := range entry.Chunks {
+		done[chunk.Start] = chunk
+	}
+	return done
+}
+
+// add records a completed chunk of the file rel as it was with size and modTime
+// Chunks recorded for another version of the file are forgotten.
+func (m *importManifest) add(rel string, size int64, modTime time.Time, chunk ImportChunk) {
+	entry, ok := m.Files[rel]
+	if !ok || entry.Size != size || !entry.ModTime.Equal(modTime) {
+		entry = &importManifestFile{Size: size, ModTime: modTime}
+		m.Files[rel] = entry
+	}
+	entry.Chunks = append(entry.Chunks, chunk)
+}
+
+// save atomically writes the manifest to path
+func (m *importManifest) save(path string) error {
+	for _, entry := range m.Files {
+		sort.Slice(entry.Chunks, func(i, j int) bool { return entry.Chunks[i].Start < entry.Chunks[j].Start })
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(path, data)
+}
@@ -0,0 +1,154 @@
+package client_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestImportDirectoryIsIdempotent(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithRetry(&config.Retry{MaxRetryTimes: 0}))
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.csv", "b.csv", "skip.txt"} {
+		var data strings.Builder
+		for i := 0; i < 20; i++ {
+			fmt.Fprintf(&data, "%d,%s_%d\n", i, name, i)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data.String()), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first run fails one chunk
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultLoadFailed, Message: "disk full", Times: 1})
+	opts := client.ImportOptions{ChunkBytes: 100, Parallel: 1}
+	report, err := c.ImportDirectory(dir, "*.csv", opts)
+	if err == nil || report.Failed != 1 || len(report.Files) != 2 || report.Files[0].Failed != 1 {
+		t.Fatalf("expected one failed chunk, got %v: %+v", err, report)
+	}
+	if report.Chunks < 4 || !strings.Contains(report.Files[0].Errors[0], "disk full") {
+		t.Errorf("unexpected report: %q\n%s", report.Files[0].Errors, report)
+	}
+	loads := len(server.Loads())
+
+	// A rerun only loads the failed chunk, with the label it had
+	report, err = c.ImportDirectory(dir, "*.csv", opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Skipped != report.Chunks-1 || report.LoadedRows != 40 || report.Files[1].LoadedRows != 20 {
+		t.Errorf("unexpected report:\n%s", report)
+	}
+	if len(server.Loads()) != loads+1 {
+		t.Errorf("expected a single load on rerun, got %d", len(server.Loads())-loads)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 40 {
+		t.Errorf("expected 40 rows, got %d", len(rows))
+	}
+
+	// Resuming with another chunk size would cut the files differently
+	if _, err := c.ImportDirectory(dir, "*.csv", client.ImportOptions{ChunkBytes: 200}); err == nil || !strings.Contains(err.Error(), "chunk size") {
+		t.Errorf("expected a chunk size mismatch error, got %v", err)
+	}
+	report, err = c.ImportDirectory(dir, "*.csv", client.ImportOptions{})
+	if err != nil || report.Skipped != report.Chunks {
+		t.Errorf("expected the manifest chunk size to be used, got %v: %+v", err, report)
+	}
+
+	// Losing the manifest is harmless, Doris rejects the labels it already has
+	if err := os.Remove(filepath.Join(dir, client.DefaultImportManifest)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ImportDirectory(dir, "*.csv", opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 40 {
+		t.Errorf("expected 40 rows after losing the manifest, got %d", len(rows))
+	}
+}
+
+func TestImportDirectoryRecoversManifestLog(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t)
+
+	dir := t.TempDir()
+	var data strings.Builder
+	for i := 0; i < 20; i++ {
+		fmt.Fprintf(&data, "%d,name_%d\n", i, i)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a.csv"), []byte(data.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A directory in place of the temporary manifest makes the final manifest write fail
+	manifest := filepath.Join(dir, client.DefaultImportManifest)
+	opts := client.ImportOptions{ChunkBytes: 100, Parallel: 2, OnChunk: func(client.ImportChunk) {
+		os.Mkdir(manifest+".tmp", 0o755)
+	}}
+	report, err := c.ImportDirectory(dir, "*.csv", opts)
+	if err != nil || report.Chunks < 2 {
+		t.Fatalf("unexpected result %v: %+v", err, report)
+	}
+	if _, err := os.Stat(manifest + client.ImportLogSuffix); err != nil {
+		t.Fatalf("expected the manifest log to be kept: %v", err)
+	}
+
+	// The next run reads the completed chunks from the log
+	if err := os.Remove(manifest + ".tmp"); err != nil {
+		t.Fatal(err)
+	}
+	loads := len(server.Loads())
+	report, err = c.ImportDirectory(dir, "*.csv", client.ImportOptions{ChunkBytes: 100})
+	if err != nil || report.Skipped != report.Chunks || len(server.Loads()) != loads {
+		t.Errorf("expected every chunk to be skipped, got %v: %+v", err, report)
+	}
+	if _, err := os.Stat(manifest + client.ImportLogSuffix); !os.IsNotExist(err) {
+		t.Errorf("expected the manifest log to be removed: %v", err)
+	}
+}
+
+func TestChunkBoundariesFollowDelimiters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, []byte("aaa||bbb||cccccccc||d"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ends, err := client.ChunkBoundaries(path, 21, 6, []byte("||"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ends) != "[10 20 21]" {
+		t.Errorf("unexpected chunk ends %v", ends)
+	}
+
+	// Self-overlapping delimiters are found after a partial match, also across read windows
+	padding := strings.Repeat("x", 64*1024-2)
+	for _, c := range []struct {
+		data, delimiter, expected string
+	}{
+		{"ab|||\ncd||\ne", "||\n", "[6 11 12]"},
+		{"aaaabaaabaa", "aab", "[5 9 11]"},
+		{padding + "|||\nyz||\nw", "||\n", fmt.Sprintf("[%d %d %d]", len(padding)+4, len(padding)+9, len(padding)+10)},
+	} {
+		if err := os.WriteFile(path, []byte(c.data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		ends, err := client.ChunkBoundaries(path, int64(len(c.data)), 2, []byte(c.delimiter))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(ends) != c.expected {
+			t.Errorf("delimiter %q: expected chunk ends %s, got %v", c.delimiter, c.expected, ends)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/deadletter"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+// labeledResult is the outcome of loadLabeled
+type labeledResult int
+
+const (
+	labeledRetry        labeledResult = iota // Not loaded yet, the caller retries
+	labeledLoaded                            // Visible in Doris
+	labeledDeadLettered                      // Rejected by Doris and written to the configured dead-letter sink
+	labeledParked                            // Rejected by Doris and written to the caller's parking sink
+)
+
+// loadLabeled loads payload under a label fixed by the caller. what names the batch in logs.
+// A label that already exists and is visible counts as loaded, which makes replaying a batch
+// after a crash safe. Batches Doris rejects are handed to the dead-letter sink if one is
+// configured, otherwise to park, so a rejected batch never blocks the caller; they are only
+// retried if writing them out fails.
+func (c *DorisLoadClient) loadLabeled(ctx context.Context, what, label string, payload []byte, park deadletter.Sink) labeledResult {
+	cfg := c.config.Load()
+	if cfg.GroupCommit != config.OFF {
+		// The client was reconfigured for group commit, labeled batches still need their labels
+		labeledCfg := *cfg
+		labeledCfg.GroupCommit = config.OFF
+		cfg = &labeledCfg
+	}
+
+	opts := &loadOptions{headers: map[string]string{"label": label}, skipDeadLetter: true}
+	response, err := c.load(ctx, cfg, bytes.NewReader(payload), opts)
+	if err == nil {
+		return labeledLoaded
+	}
+
+	if response != nil && response.Resp.Status == labelAlreadyExistsStatus {
+		if existingJobLoaded(response) {
+			log.Infof("%s %s was already loaded, skipping", what, label)
+			return labeledLoaded
+		}
+		log.Infof("%s %s exists with status %s, waiting until visible", what, label, response.Resp.ExistingJobStatus)
+		return labeledRetry
+	}
+
+	if ctx.Err() != nil {
+		return labeledRetry
+	}
+
+	if !isRetryableError(err, response) && response != nil {
+		// The data itself is rejected, retrying forever would block the caller
+		sink, result := cfg.DeadLetter, labeledDeadLettered
+		if sink == nil {
+			sink, result = park, labeledParked
+		}
+		dl := &deadletter.Record{
+			Reason:   deadletter.ReasonFailed,
+			Database: cfg.Database,
+			Table:    cfg.Table,
+			Label:    label,
+			Headers:  map[string]string{},
+			Error:    err.Error(),
+			Response: response.Resp.String(),
+			Payload:  payload,
+		}
+		if err := sink.Write(dl); err != nil {
+			log.Errorf("Failed to write rejected %s %s, will retry: %v", strings.ToLower(what), label, err)
+			return labeledRetry
+		}
+		if result == labeledParked {
+			log.Errorf("%s %s rejected by Doris and no dead-letter sink is configured, parked it: %v", what, label, err)
+		} else {
+			log.Warnf("%s %s rejected by Doris, sent to dead-letter sink", what, label)
+		}
+		return result
+	}
+
+	log.Warnf("Failed to upload %s %s, will retry: %v", strings.ToLower(what), label, err)
+	return labeledRetry
+}
+
+// parkingSink is a DirSink whose directory is only created when the first batch is parked
+type parkingSink struct {
+	dir  string
+	mu   sync.Mutex
+	sink *deadletter.DirSink
+}
+
+// Write stores record in the parking directory
+func (p *parkingSink) Write(record *deadletter.Record) error {
+	p.mu.Lock()
+	if p.sink == nil {
+		sink, err := deadletter.NewDirSink(p.dir)
+		if err != nil {
+			p.mu.Unlock()
+			return err
+		}
+		p.sink = sink
+	}
+	p.mu.Unlock()
+	return p.sink.Write(record)
+}
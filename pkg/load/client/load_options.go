@@ -0,0 +1,33 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+)
+
+// LoadOption overrides the client configuration for a single load
+// Options of the load itself can be used, e.g. config.WithLabel, config.WithFormat,
+// config.WithColumns or config.WithGroupCommit. Options of the client, i.e. endpoints,
+// credentials, WorkerPool, RateLimit, Backpressure and Transport, are rejected.
+type LoadOption = config.Option
+
+// withLoadOptions returns cfg with opts applied to a private copy, or cfg itself when there are none
+func withLoadOptions(cfg *config.Config, opts []LoadOption) (*config.Config, error) {
+	if len(opts) == 0 {
+		return cfg, nil
+	}
+
+	loadCfg := cfg.Clone()
+	for _, opt := range opts {
+		if setting := config.ClientSetting(opt); setting != "" {
+			return nil, fmt.Errorf("invalid load options: %s cannot be set per load, configure the client instead", setting)
+		}
+		opt(loadCfg)
+	}
+
+	if err := loadCfg.ValidateInternal(); err != nil {
+		return nil, fmt.Errorf("invalid load options: %w", err)
+	}
+	return loadCfg, nil
+}
@@ -0,0 +1,57 @@
+package client_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestLoadOptionsOverrideConfig(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithOption("timeout", "60"))
+
+	_, err := c.Load(strings.NewReader(`{"id": 1}`+"\n"),
+		config.WithLabel("backfill_1"),
+		config.WithPartitions("p1", "p2"),
+		config.WithJSON(config.JSONObjectLine),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	load := server.Loads()[0]
+	if load.Label != "backfill_1" || load.Headers.Get("partitions") != "p1,p2" || load.Headers.Get("timeout") != "60" {
+		t.Errorf("unexpected request: label %s, headers %v", load.Label, load.Headers)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 1 || rows[0] != `{"id":1}` {
+		t.Errorf("unexpected rows: %q", rows)
+	}
+
+	// The same label is rejected by Doris and not retried
+	_, err = c.Load(strings.NewReader(`{"id": 2}`+"\n"), config.WithLabel("backfill_1"), config.WithJSON(config.JSONObjectLine))
+	if err == nil {
+		t.Fatal("expected duplicate label to fail")
+	}
+
+	// Settings of the client cannot be changed by a single load
+	for _, opt := range []client.LoadOption{
+		config.WithEndpoints("http://other:8030"),
+		config.WithEndpoints(),
+		config.WithAuth("admin", "secret"),
+		config.WithAuth("", ""),
+		config.WithRateLimit(&config.RateLimit{MaxConcurrentLoads: 1}),
+		config.WithBackpressure(&config.Backpressure{}),
+		config.WithWorkerPool(&config.WorkerPool{Workers: 1}),
+	} {
+		if _, err := c.Load(strings.NewReader("3,carol\n"), opt); err == nil || !strings.Contains(err.Error(), "cannot be set per load") {
+			t.Errorf("expected the client option to be rejected, got %v", err)
+		}
+	}
+	if loads := server.Loads(); len(loads) != 2 {
+		t.Errorf("rejected options must not send a load, got %d loads", len(loads))
+	}
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+)
+
+const (
+	// multiTableLabelName replaces the table name in labels of multi-table loads
+	multiTableLabelName = "multi_table"
+)
+
+// MultiTableRows is what a multi-table stream carried for one table
+type MultiTableRows struct {
+	Rows  int64 // Rows written for the table
+	Bytes int64 // Row bytes written for the table, excluding the table prefix
+}
+
+// MultiTableResult is the outcome of flushing a MultiTableWriter
+type MultiTableResult struct {
+	Response *loader.LoadResponse      // Response of the single stream load
+	Tables   map[string]MultiTableRows // Rows sent per table in this stream
+}
+
+// MultiTableWriter buffers rows tagged with their target table and sends them to Doris
+// in a single stream load, without a table in the request path. Each line is written as
+// "table|row" followed by the line delimiter of the client format.
+// A MultiTableWriter is safe for concurrent use.
+type MultiTableWriter struct {
+	client    *DorisLoadClient
+	database  string
+	format    config.Format
+	delimiter []byte
+
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	tables map[string]*MultiTableRows
+}
+
+// NewMultiTableWriter creates a writer loading into tables of database
+// The client format must be CSV or JSON lines; group commit is supported.
+func (c *DorisLoadClient) NewMultiTableWriter(database string) (*MultiTableWriter, error) {
+	cfg, err := c.tableConfig(database, multiTableLabelName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	delimiter := "\n"
+	switch f := cfg.Format.(type) {
+	case *config.CSVFormat:
+		if f.LineDelimiter != "" {
+			delimiter = config.UnescapeDelimiter(f.LineDelimiter)
+		}
+	case *config.JSONFormat:
+		if f.Type != config.JSONObjectLine {
+			return nil, fmt.Errorf("multi-table load requires JSON object lines, got %s", f.Type)
+		}
+	}
+
+	return &MultiTableWriter{
+		client:    c,
+		database:  database,
+		format:    cfg.Format,
+		delimiter: []byte(delimiter),
+		tables:    make(map[string]*MultiTableRows),
+	}, nil
+}
+
+// Write appends one row for table; row must not contain the line delimiter
+func (w *MultiTableWriter) Write(table string, row []byte) error {
+	if table == "" || strings.Contains(table, loader.MultiTableSeparator) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.WriteString(table)
+	w.buf.WriteString(loader.MultiTableSeparator)
+	w.buf.Write(row)
+	w.buf.Write(w.delimiter)
+
+	rows, ok := w.tables[table]
+	if !ok {
+		rows = &MultiTableRows{}
+		w.tables[table] = rows
+	}
+	rows.Rows++
+	rows.Bytes += int64(len(row))
+	return nil
+}
+
+// WriteString appends one row for table
+func (w *MultiTableWriter) WriteString(table, row string) error {
+	return w.Write(table, []byte(row))
+}
+
+// Buffered returns the number of bytes waiting to be flushed
+func (w *MultiTableWriter) Buffered() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Len()
+}
+
+// Flush sends all buffered rows in one stream load and resets the writer
+// Rows of a failed flush are not kept; the returned error and response describe the failure.
+func (w *MultiTableWriter) Flush() (*MultiTableResult, error) {
+	return w.FlushContext(context.Background())
+}
+
+// FlushContext is like Flush but gives up when ctx ends
+func (w *MultiTableWriter) FlushContext(ctx context.Context) (*MultiTableResult, error) {
+	w.mu.Lock()
+	payload := make([]byte, w.buf.Len())
+	copy(payload, w.buf.Bytes())
+	tables := w.tables
+	w.buf.Reset()
+	w.tables = make(map[string]*MultiTableRows)
+	w.mu.Unlock()
+
+	result := &MultiTableResult{Tables: make(map[string]MultiTableRows, len(tables))}
+	for table, rows := range tables {
+		result.Tables[table] = *rows
+	}
+	if len(payload) == 0 {
+		return result, nil
+	}
+
+	// Follow client reconfiguration, but keep the format the buffered rows were written in
+	cfg, err := w.client.tableConfig(w.database, multiTableLabelName, &TableOptions{Format: w.format})
+	if err != nil {
+		return result, err
+	}
+
+	start := time.Now()
+	response, err := w.client.load(ctx, cfg, bytes.NewReader(payload), &loadOptions{multiTable: true})
+	result.Response = response
+
+	w.recordTables(result.Tables, time.Since(start), response, err)
+	return result, err
+}
+
+// recordTables adds the rows of a multi-table stream to the per-table metrics of the client
+// Doris only reports filtered rows for the whole stream, so when it filtered some the loaded and
+// filtered rows cannot be attributed to tables; they are then recorded under the "multi_table"
+// placeholder table instead.
+func (w *MultiTableWriter) recordTables(tables map[string]MultiTableRows, elapsed time.Duration, response *loader.LoadResponse, err error) {
+	failed := err != nil || response == nil || response.Status != loader.SUCCESS
+	filtered := response != nil && response.Resp.NumberFilteredRows > 0
+	for table, rows := range tables {
+		counters := w.client.tableCounters(w.database, table)
+		atomic.AddInt64(&counters.loads, 1)
+		atomic.AddInt64(&counters.bytes, rows.Bytes)
+		atomic.AddInt64(&counters.totalNanos, int64(elapsed))
+		if failed {
+			atomic.AddInt64(&counters.failures, 1)
+		} else if !filtered {
+			atomic.AddInt64(&counters.loadedRows, rows.Rows)
+		}
+	}
+
+	if filtered {
+		counters := w.client.tableCounters(w.database, multiTableLabelName)
+		atomic.AddInt64(&counters.loadedRows, response.Resp.NumberLoadedRows)
+		atomic.AddInt64(&counters.filteredRows, int64(response.Resp.NumberFilteredRows))
+	}
+}
@@ -0,0 +1,70 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestMultiTableWriter(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithOption("max_filter_ratio", "0.5"))
+
+	w, err := c.NewMultiTableWriter("test_db")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w.WriteString("users", "1,Alice")
+	w.WriteString("orders", "100,1")
+	w.WriteString("users", "2,Bob")
+
+	result, err := w.Flush()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Tables["users"].Rows != 2 || result.Tables["orders"].Rows != 1 {
+		t.Errorf("unexpected result: %+v", result.Tables)
+	}
+	if rows := server.Rows("test_db", "orders"); len(rows) != 1 || rows[0] != "100,1" {
+		t.Errorf("unexpected orders rows: %q", rows)
+	}
+
+	// Filtered rows cannot be attributed to a table
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultFilterRows, FilteredRows: 1, Times: 1})
+	w.WriteString("users", "x")
+	w.WriteString("orders", "101,2")
+	if _, err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	metrics := c.TableMetrics()
+	if users := metrics["test_db.users"]; users.Loads != 2 || users.LoadedRows != 2 {
+		t.Errorf("unexpected users metrics: %+v", users)
+	}
+	if stream := metrics["test_db.multi_table"]; stream.LoadedRows != 1 || stream.FilteredRows != 1 {
+		t.Errorf("unexpected multi-table metrics: %+v", stream)
+	}
+}
+
+func TestMultiTableWriterJSON(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithJSON(config.JSONObjectLine))
+
+	w, err := c.NewMultiTableWriter("test_db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.WriteString("users", `{"id": 1, "name": "a|b"}`)
+	w.WriteString("orders", `{"id": 100}`)
+	if _, err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 1 || rows[0] != `{"id":1,"name":"a|b"}` {
+		t.Errorf("unexpected users rows: %q", rows)
+	}
+	if rows := server.Rows("test_db", "orders"); len(rows) != 1 || rows[0] != `{"id":100}` {
+		t.Errorf("unexpected orders rows: %q", rows)
+	}
+}
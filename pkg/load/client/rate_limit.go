@@ -0,0 +1,285 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+var (
+	// limiterRegistry holds the limiters shared by clients loading the same table
+	limiterRegistry   = make(map[string]*sharedLimiter)
+	limiterRegistryMu sync.Mutex
+)
+
+// sharedLimiter is a limiter shared per table, removed when the last client using it is closed
+type sharedLimiter struct {
+	limiter *loadLimiter
+	refs    int
+}
+
+// ThrottleMetrics reports how much time loads of a client spent waiting for rate limits
+type ThrottleMetrics struct {
+	ThrottledLoads int64         // Load requests that had to wait
+	ThrottledTime  time.Duration // Total time spent waiting
+	InFlightLoads  int64         // Load requests currently holding a concurrency slot
+}
+
+// throttleCounters are the atomic counters behind ThrottleMetrics
+type throttleCounters struct {
+	throttledLoads int64
+	throttledNanos int64
+	inFlight       int64
+}
+
+// tokenBucket is a token bucket allowing one second of burst
+// Tokens may go negative so a request larger than the burst waits proportionally instead of forever
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full bucket refilled with rate tokens per second
+func newTokenBucket(rate float64) *tokenBucket {
+	return &tokenBucket{rate: rate, tokens: rate, last: time.Now()}
+}
+
+// reserve takes n tokens and returns how long the caller must wait before using them
+func (b *tokenBucket) reserve(n float64) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+
+	b.tokens -= n
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back n tokens of a reservation that was not used
+func (b *tokenBucket) cancel(n float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens += n
+}
+
+// wait blocks until n tokens are available or ctx ends
+func (b *tokenBucket) wait(ctx context.Context, n float64) (time.Duration, error) {
+	delay := b.reserve(n)
+	if err := sleepContext(ctx, delay); err != nil {
+		b.cancel(n)
+		return delay, err
+	}
+	return delay, nil
+}
+
+// loadLimiter enforces a RateLimit configuration
+type loadLimiter struct {
+	bytes *tokenBucket
+	loads *tokenBucket
+	slots chan struct{}
+}
+
+// newLoadLimiter creates a limiter for cfg, or nil when nothing is limited
+func newLoadLimiter(cfg *config.RateLimit) *loadLimiter {
+	if cfg == nil || (cfg.BytesPerSecond == 0 && cfg.LoadsPerSecond == 0 && cfg.MaxConcurrentLoads == 0) {
+		return nil
+	}
+
+	l := &loadLimiter{}
+	if cfg.BytesPerSecond > 0 {
+		l.bytes = newTokenBucket(float64(cfg.BytesPerSecond))
+	}
+	if cfg.LoadsPerSecond > 0 {
+		l.loads = newTokenBucket(cfg.LoadsPerSecond)
+	}
+	if cfg.MaxConcurrentLoads > 0 {
+		l.slots = make(chan struct{}, cfg.MaxConcurrentLoads)
+	}
+	return l
+}
+
+// acquireSharedLimiter returns the limiter shared by loads into key and takes a reference on it
+func acquireSharedLimiter(key string, cfg *config.RateLimit) *loadLimiter {
+	limiterRegistryMu.Lock()
+	defer limiterRegistryMu.Unlock()
+
+	shared, ok := limiterRegistry[key]
+	if !ok {
+		shared = &sharedLimiter{limiter: newLoadLimiter(cfg)}
+		limiterRegistry[key] = shared
+	}
+	shared.refs++
+	return shared.limiter
+}
+
+// releaseSharedLimiter drops a reference taken by acquireSharedLimiter
+func releaseSharedLimiter(key string) {
+	limiterRegistryMu.Lock()
+	defer limiterRegistryMu.Unlock()
+
+	shared, ok := limiterRegistry[key]
+	if !ok {
+		return
+	}
+	shared.refs--
+	if shared.refs <= 0 {
+		delete(limiterRegistry, key)
+	}
+}
+
+// newClientLimiter returns the limiter of the client's own table, shared per table when configured
+func (c *DorisLoadClient) newClientLimiter(cfg *config.Config) *loadLimiter {
+	if c.limits == nil || !c.limits.SharedByTable {
+		return newLoadLimiter(c.limits)
+	}
+	return c.sharedLimiter(cfg.Database, cfg.Table)
+}
+
+// sharedLimiter returns the limiter shared by clients loading database.table
+// The client takes one reference per table and keeps it until Close.
+func (c *DorisLoadClient) sharedLimiter(database, table string) *loadLimiter {
+	key := tableKey(database, table)
+	if l, ok := c.sharedLimiters.Load(key); ok {
+		return l.(*loadLimiter)
+	}
+	l, loaded := c.sharedLimiters.LoadOrStore(key, acquireSharedLimiter(key, c.limits))
+	if loaded {
+		releaseSharedLimiter(key)
+	}
+	return l.(*loadLimiter)
+}
+
+// releaseLimiters drops the references of the client on shared limiters
+func (c *DorisLoadClient) releaseLimiters() {
+	c.sharedLimiters.Range(func(key, _ interface{}) bool {
+		c.sharedLimiters.Delete(key)
+		releaseSharedLimiter(key.(string))
+		return true
+	})
+}
+
+// throttle waits until a request of size bytes may be sent, honoring the rate limits and the
+// backpressure window, and returns the function to call with the outcome of the request
+func (c *DorisLoadClient) throttle(ctx context.Context, cfg *config.Config, size int64) (func(*loader.LoadResponse, error), error) {
+	release, err := c.rateLimit(ctx, c.limiterFor(cfg), size)
+	if err != nil {
+		return nil, err
+	}
+
+	bp := c.backpressure
+	if bp == nil {
+		return func(*loader.LoadResponse, error) { release() }, nil
+	}
+
+	start := time.Now()
+	waited, err := bp.acquire(ctx)
+	c.recordThrottle(start, waited)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	return func(response *loader.LoadResponse, err error) {
+		bp.release(response, err)
+		release()
+	}, nil
+}
+
+// limiterFor returns the limiter applying to a load into the table of cfg
+// Limits are the ones the client was created with. Shared limits are per table, so loads routed
+// to another table, or into the table set by Reconfigure, use that table's limiter.
+func (c *DorisLoadClient) limiterFor(cfg *config.Config) *loadLimiter {
+	if c.limits == nil || !c.limits.SharedByTable {
+		return c.limiter
+	}
+	return c.sharedLimiter(cfg.Database, cfg.Table)
+}
+
+// rateLimit waits for the limits of l and returns the function releasing the concurrency slot
+func (c *DorisLoadClient) rateLimit(ctx context.Context, l *loadLimiter, size int64) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	start := time.Now()
+	waited := false
+
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			waited = true
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				c.recordThrottle(start, true)
+				return nil, ctx.Err()
+			}
+		}
+	}
+	release := func() {
+		atomic.AddInt64(&c.throttleStats.inFlight, -1)
+		if l.slots != nil {
+			<-l.slots
+		}
+	}
+	atomic.AddInt64(&c.throttleStats.inFlight, 1)
+
+	if l.loads != nil {
+		delay, err := l.loads.wait(ctx, 1)
+		waited = waited || delay > 0
+		if err != nil {
+			release()
+			c.recordThrottle(start, waited)
+			return nil, err
+		}
+	}
+
+	if l.bytes != nil && size > 0 {
+		delay, err := l.bytes.wait(ctx, float64(size))
+		waited = waited || delay > 0
+		if err != nil {
+			release()
+			c.recordThrottle(start, waited)
+			return nil, err
+		}
+	}
+
+	c.recordThrottle(start, waited)
+	return release, nil
+}
+
+// recordThrottle accounts the time a throttled request waited
+func (c *DorisLoadClient) recordThrottle(start time.Time, waited bool) {
+	if !waited {
+		return
+	}
+	elapsed := time.Since(start)
+	atomic.AddInt64(&c.throttleStats.throttledLoads, 1)
+	atomic.AddInt64(&c.throttleStats.throttledNanos, int64(elapsed))
+	log.Debugf("Load throttled for %v", elapsed)
+}
+
+// ThrottleMetrics returns the time loads of this client spent waiting for rate limits
+func (c *DorisLoadClient) ThrottleMetrics() ThrottleMetrics {
+	return ThrottleMetrics{
+		ThrottledLoads: atomic.LoadInt64(&c.throttleStats.throttledLoads),
+		ThrottledTime:  time.Duration(atomic.LoadInt64(&c.throttleStats.throttledNanos)),
+		InFlightLoads:  atomic.LoadInt64(&c.throttleStats.inFlight),
+	}
+}
@@ -0,0 +1,82 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := client.NewTokenBucket(100)
+	if delay := b.Reserve(100); delay != 0 {
+		t.Errorf("a full bucket must not wait, got %v", delay)
+	}
+	if delay := b.Reserve(50); delay < 400*time.Millisecond || delay > 500*time.Millisecond {
+		t.Errorf("expected to wait about 500ms for 50 tokens at 100/s, got %v", delay)
+	}
+
+	// A canceled wait gives its tokens back
+	b.Cancel(50)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Wait(ctx, 100); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if delay := b.Reserve(1); delay > 20*time.Millisecond {
+		t.Errorf("tokens of the canceled wait were not returned, got %v", delay)
+	}
+}
+
+func TestRateLimitSharedByTable(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	limit := config.WithRateLimit(&config.RateLimit{MaxConcurrentLoads: 1, SharedByTable: true})
+	c1 := server.NewClient(t, limit)
+	c2 := server.NewClient(t, limit)
+	if c1.Limiter() == nil || c1.Limiter() != c2.Limiter() {
+		t.Fatal("clients loading the same table must share the limiter")
+	}
+	orders, err := c1.TableConfig("test_db", "orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c1.LimiterFor(orders) == c1.Limiter() {
+		t.Error("loads routed to another table must use that table's limiter")
+	}
+
+	// The second client waits for the concurrency slot held by the first
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultTimeout, Delay: 200 * time.Millisecond, Times: 1})
+	first := make(chan error, 1)
+	go func() {
+		_, err := c1.Load(strings.NewReader("1,alice\n"))
+		first <- err
+	}()
+	for c1.ThrottleMetrics().InFlightLoads == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := c2.Load(strings.NewReader("2,bob\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+	if metrics := c2.ThrottleMetrics(); metrics.ThrottledLoads != 1 || metrics.ThrottledTime < 100*time.Millisecond || metrics.InFlightLoads != 0 {
+		t.Errorf("unexpected throttle metrics: %+v", metrics)
+	}
+
+	// Shared limiters are dropped once every client using them is closed
+	c1.Close()
+	if refs, _ := client.SharedLimiterRefs("test_db.users"); refs != 1 {
+		t.Errorf("expected 1 reference left, got %d", refs)
+	}
+	c2.Close()
+	if _, limiters := client.SharedLimiterRefs("test_db.users"); limiters != 0 {
+		t.Errorf("expected no shared limiters after Close, got %d", limiters)
+	}
+}
@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+)
+
+// TableOptions overrides client settings for loads routed to a specific table
+// Endpoints, credentials, retry, throttling and the HTTP transport are always shared with the client.
+type TableOptions struct {
+	Format      config.Format           // nil keeps the client format
+	Options     map[string]string       // Merged over the client options
+	GroupCommit *config.GroupCommitMode // nil keeps the client group commit mode
+	LabelPrefix string                  // Empty keeps the client label prefix
+}
+
+// TableMetrics are the load statistics of one table
+type TableMetrics struct {
+	Loads        int64         // Load operations started
+	Failures     int64         // Load operations that returned an error
+	LoadedRows   int64         // Rows reported loaded by Doris
+	FilteredRows int64         // Rows reported filtered by Doris
+	Bytes        int64         // Payload bytes of all load operations
+	TotalTime    time.Duration // Total time spent in load operations, including retries
+}
+
+// tableCounters are the atomic counters behind TableMetrics
+type tableCounters struct {
+	loads        int64
+	failures     int64
+	loadedRows   int64
+	filteredRows int64
+	bytes        int64
+	totalNanos   int64
+}
+
+// TableSink loads into one table through a shared client
+// Settings not overridden by its TableOptions follow the client, including Reconfigure.
+type TableSink struct {
+	client   *DorisLoadClient
+	database string
+	table    string
+	opts     *TableOptions
+}
+
+// Table returns a sink loading into database.table with the client's endpoints, auth, transport and retry
+// The sink is cheap to create and safe for concurrent use.
+func (c *DorisLoadClient) Table(database, table string, opts *TableOptions) *TableSink {
+	if opts != nil {
+		copied := *opts
+		if opts.Options != nil {
+			copied.Options = make(map[string]string, len(opts.Options))
+			for k, v := range opts.Options {
+				copied.Options[k] = v
+			}
+		}
+		opts = &copied
+	}
+	return &TableSink{client: c, database: database, table: table, opts: opts}
+}
+
+// Load sends data to the sink's table
+func (s *TableSink) Load(reader io.Reader, opts ...LoadOption) (*loader.LoadResponse, error) {
+	return s.LoadContext(context.Background(), reader, opts...)
+}
+
+// LoadContext sends data to the sink's table, giving up when ctx ends
+func (s *TableSink) LoadContext(ctx context.Context, reader io.Reader, opts ...LoadOption) (*loader.LoadResponse, error) {
+	cfg, err := s.client.tableConfig(s.database, s.table, s.opts)
+	if err != nil {
+		return nil, err
+	}
+	if cfg, err = withLoadOptions(cfg, opts); err != nil {
+		return nil, err
+	}
+	return s.client.load(ctx, cfg, reader, &loadOptions{})
+}
+
+// Database returns the database of the sink
+func (s *TableSink) Database() string {
+	return s.database
+}
+
+// TableName returns the table of the sink
+func (s *TableSink) TableName() string {
+	return s.table
+}
+
+// LoadTo sends data to database.table instead of the client's configured table
+func (c *DorisLoadClient) LoadTo(database, table string, reader io.Reader, opts *TableOptions, loadOpts ...LoadOption) (*loader.LoadResponse, error) {
+	return c.LoadToContext(context.Background(), database, table, reader, opts, loadOpts...)
+}
+
+// LoadToContext is like LoadTo but gives up when ctx ends
+func (c *DorisLoadClient) LoadToContext(ctx context.Context, database, table string, reader io.Reader, opts *TableOptions, loadOpts ...LoadOption) (*loader.LoadResponse, error) {
+	cfg, err := c.tableConfig(database, table, opts)
+	if err != nil {
+		return nil, err
+	}
+	if cfg, err = withLoadOptions(cfg, loadOpts); err != nil {
+		return nil, err
+	}
+	return c.load(ctx, cfg, reader, &loadOptions{})
+}
+
+// tableConfig derives the configuration for loading into database.table from the client configuration
+// The derived configuration is validated, e.g. an empty table or a group commit override
+// conflicting with the client label are rejected.
+func (c *DorisLoadClient) tableConfig(database, table string, opts *TableOptions) (*config.Config, error) {
+	base := c.config.Load()
+	if database == base.Database && table == base.Table && opts == nil {
+		return base, nil
+	}
+
+	// A deep copy, the Options of the derived config must not alias the client's
+	cfg := base.Clone()
+	cfg.Database = database
+	cfg.Table = table
+	if database != base.Database || table != base.Table {
+		// A fixed label belongs to the client's own table
+		cfg.Label = ""
+	}
+
+	if opts != nil {
+		if opts.Format != nil {
+			cfg.Format = opts.Format
+		}
+		if opts.GroupCommit != nil {
+			cfg.GroupCommit = *opts.GroupCommit
+		}
+		if opts.LabelPrefix != "" {
+			cfg.LabelPrefix = opts.LabelPrefix
+		}
+		if len(opts.Options) > 0 && cfg.Options == nil {
+			cfg.Options = make(map[string]string, len(opts.Options))
+		}
+		for k, v := range opts.Options {
+			cfg.Options[k] = v
+		}
+	}
+
+	if err := cfg.ValidateInternal(); err != nil {
+		return nil, fmt.Errorf("invalid configuration for %s: %w", tableKey(database, table), err)
+	}
+	return cfg, nil
+}
+
+// recordTableLoad updates the metrics of the table of cfg with the outcome of a load operation
+func (c *DorisLoadClient) recordTableLoad(cfg *config.Config, size int64, elapsed time.Duration, response *loader.LoadResponse, err error) {
+	counters := c.tableCounters(cfg.Database, cfg.Table)
+	atomic.AddInt64(&counters.loads, 1)
+	atomic.AddInt64(&counters.bytes, size)
+	atomic.AddInt64(&counters.totalNanos, int64(elapsed))
+	if err != nil || response == nil || response.Status != loader.SUCCESS {
+		atomic.AddInt64(&counters.failures, 1)
+	}
+	if response != nil {
+		atomic.AddInt64(&counters.loadedRows, response.Resp.NumberLoadedRows)
+		atomic.AddInt64(&counters.filteredRows, int64(response.Resp.NumberFilteredRows))
+	}
+}
+
+// tableCounters returns the counters of database.table, creating them on first use
+func (c *DorisLoadClient) tableCounters(database, table string) *tableCounters {
+	value, _ := c.tableStats.LoadOrStore(tableKey(database, table), &tableCounters{})
+	return value.(*tableCounters)
+}
+
+// TableMetrics returns the load statistics of every table this client loaded into, keyed by "database.table"
+// Rows of multi-table loads in which Doris filtered rows are counted under "database.multi_table",
+// Doris does not report which tables they belonged to.
+func (c *DorisLoadClient) TableMetrics() map[string]TableMetrics {
+	result := make(map[string]TableMetrics)
+	c.tableStats.Range(func(key, value interface{}) bool {
+		counters := value.(*tableCounters)
+		result[key.(string)] = TableMetrics{
+			Loads:        atomic.LoadInt64(&counters.loads),
+			Failures:     atomic.LoadInt64(&counters.failures),
+			LoadedRows:   atomic.LoadInt64(&counters.loadedRows),
+			FilteredRows: atomic.LoadInt64(&counters.filteredRows),
+			Bytes:        atomic.LoadInt64(&counters.bytes),
+			TotalTime:    time.Duration(atomic.LoadInt64(&counters.totalNanos)),
+		}
+		return true
+	})
+	return result
+}
+
+// tableKey returns the key of a table in TableMetrics
+func tableKey(database, table string) string {
+	return fmt.Sprintf("%s.%s", database, table)
+}
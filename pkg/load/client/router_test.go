@@ -0,0 +1,72 @@
+package client_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestLoadRoutesToOtherTables(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithRetry(&config.Retry{}), config.WithOption("strict_mode", "true"))
+
+	opts := &client.TableOptions{Options: map[string]string{"max_filter_ratio": "0.5"}, LabelPrefix: "orders"}
+	sink := c.Table("test_db", "orders", opts)
+	opts.Options["max_filter_ratio"] = "1"
+	if _, err := sink.Load(strings.NewReader("100,1\n")); err != nil {
+		t.Fatal(err)
+	}
+	loads := server.Loads()
+	headers := loads[len(loads)-1].Headers
+	if headers.Get("max_filter_ratio") != "0.5" || headers.Get("strict_mode") != "true" || !strings.HasPrefix(headers.Get("label"), "orders") {
+		t.Errorf("sink options must be merged over the client's, got %v", headers)
+	}
+	if _, ok := c.Config().Options["max_filter_ratio"]; ok {
+		t.Error("table options leaked into the client configuration")
+	}
+
+	if _, err := c.LoadTo("test_db", "events", strings.NewReader("1,click\n2,view\n"), nil); err != nil {
+		t.Fatal(err)
+	}
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultLoadFailed, Table: "events", Times: 1})
+	if _, err := c.LoadTo("test_db", "events", strings.NewReader("3,click\n"), nil); err == nil {
+		t.Fatal("expected the load to fail")
+	}
+	if _, err := c.Load(strings.NewReader("1,alice\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	for table, count := range map[string]int{"orders": 1, "events": 2, "users": 1} {
+		if rows := server.Rows("test_db", table); len(rows) != count {
+			t.Errorf("expected %d rows in %s, got %q", count, table, rows)
+		}
+	}
+	metrics := c.TableMetrics()
+	if events := metrics["test_db.events"]; events.Loads != 2 || events.Failures != 1 || events.LoadedRows != 2 || events.Bytes == 0 {
+		t.Errorf("unexpected events metrics: %+v", events)
+	}
+	if orders := metrics["test_db.orders"]; orders.Loads != 1 || orders.Failures != 0 || orders.LoadedRows != 1 {
+		t.Errorf("unexpected orders metrics: %+v", orders)
+	}
+	if users := metrics["test_db.users"]; users.Loads != 1 || users.LoadedRows != 1 {
+		t.Errorf("unexpected users metrics: %+v", users)
+	}
+
+	// Derived configurations are validated before anything is sent
+	loads = server.Loads()
+	if _, err := c.LoadTo("", "", strings.NewReader("4,click\n"), nil); err == nil || !strings.Contains(err.Error(), "cannot be empty") {
+		t.Errorf("expected an empty table to be rejected, got %v", err)
+	}
+	labeled := server.NewClient(t, config.WithLabel("fixed"))
+	async := config.ASYNC
+	if _, err := labeled.Table("test_db", "users", &client.TableOptions{GroupCommit: &async}).Load(strings.NewReader("5,eve\n")); err == nil {
+		t.Error("expected group commit to be rejected with the client label")
+	}
+	if len(server.Loads()) != len(loads) {
+		t.Error("invalid table configurations must not send a load")
+	}
+}
@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+// FetchSchema returns the columns of the configured table
+func (c *DorisLoadClient) FetchSchema() (*loader.TableSchema, error) {
+	return c.FetchSchemaContext(context.Background())
+}
+
+// FetchSchemaContext is FetchSchema with a context
+func (c *DorisLoadClient) FetchSchemaContext(ctx context.Context) (*loader.TableSchema, error) {
+	return c.fetchSchema(ctx, c.config.Load())
+}
+
+// ValidateStruct checks that the json-tagged fields of v, a struct, a pointer to one or a slice of
+// them, are columns of the configured table
+func (c *DorisLoadClient) ValidateStruct(v any) error {
+	return c.ValidateStructContext(context.Background(), v)
+}
+
+// ValidateStructContext is ValidateStruct with a context
+func (c *DorisLoadClient) ValidateStructContext(ctx context.Context, v any) error {
+	cfg := c.config.Load()
+	return c.validateWithSchema(ctx, cfg, func(schema *loader.TableSchema) error {
+		return schema.ValidateStruct(v)
+	})
+}
+
+// ValidateCSVHeader checks the names of a CSV header line, separated by the configured column
+// separator, against the configured table
+func (c *DorisLoadClient) ValidateCSVHeader(header string) error {
+	return c.ValidateCSVHeaderContext(context.Background(), header)
+}
+
+// ValidateCSVHeaderContext is ValidateCSVHeader with a context
+func (c *DorisLoadClient) ValidateCSVHeaderContext(ctx context.Context, header string) error {
+	cfg := c.config.Load()
+	separator := ","
+	if format, ok := cfg.Format.(*config.CSVFormat); ok {
+		separator = config.UnescapeDelimiter(format.ColumnSeparator)
+	}
+	return c.validateWithSchema(ctx, cfg, func(schema *loader.TableSchema) error {
+		return schema.ValidateHeader(header, separator)
+	})
+}
+
+// validateColumns checks the columns option of cfg, if any, against the table
+func (c *DorisLoadClient) validateColumns(ctx context.Context, cfg *config.Config) error {
+	columns := cfg.Options["columns"]
+	if strings.TrimSpace(columns) == "" {
+		return nil
+	}
+	return c.validateWithSchema(ctx, cfg, func(schema *loader.TableSchema) error {
+		return schema.ValidateColumns(columns)
+	})
+}
+
+// validateWithSchema runs validate against the cached schema of the table of cfg
+// A mismatch against a cached schema fetches it again first, the table may have changed since.
+func (c *DorisLoadClient) validateWithSchema(ctx context.Context, cfg *config.Config, validate func(*loader.TableSchema) error) error {
+	key := cfg.Database + "." + cfg.Table
+	if cached, ok := c.schemas.Load(key); ok {
+		err := validate(cached.(*loader.TableSchema))
+		var schemaErr *loader.SchemaError
+		if !errors.As(err, &schemaErr) {
+			return err
+		}
+		log.Debugf("Columns do not match the cached schema of %s, fetching it again", key)
+	}
+
+	schema, err := c.fetchSchema(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	return validate(schema)
+}
+
+// fetchSchema gets the schema of the table of cfg and caches it
+func (c *DorisLoadClient) fetchSchema(ctx context.Context, cfg *config.Config) (*loader.TableSchema, error) {
+	var schema *loader.TableSchema
+	err := c.doWithCredentials(ctx, cfg, func() (*http.Request, error) {
+		return loader.CreateSchemaRequest(cfg, cfg.Database, cfg.Table)
+	}, func(req *http.Request) error {
+		var err error
+		schema, err = c.streamLoader.GetSchema(req, cfg.Database, cfg.Table)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.schemas.Store(cfg.Database+"."+cfg.Table, schema)
+	return schema, nil
+}
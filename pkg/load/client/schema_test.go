@@ -0,0 +1,64 @@
+package client_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+)
+
+func TestSchemaValidationBeforeLoad(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	server.SetSchema("test_db", "users", dorismock.UniqueKeys,
+		dorismock.Column{Name: "id", Type: "BIGINT", Key: true},
+		dorismock.Column{Name: "name", Type: "VARCHAR", Nullable: true, Aggregation: "REPLACE"},
+		dorismock.Column{Name: "balance", Type: "DECIMAL128", Precision: 18, Scale: 2, Nullable: true, Aggregation: "REPLACE"},
+	)
+	c := server.NewClient(t, config.WithSchemaValidation())
+
+	schema, err := c.FetchSchema()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	balance, ok := schema.Column("BALANCE")
+	if schema.KeysType != "UNIQUE_KEYS" || len(schema.Columns) != 3 || !schema.Columns[0].Key ||
+		!ok || balance.Precision != 18 || balance.Scale != 2 || !balance.Nullable {
+		t.Errorf("unexpected schema %+v", schema)
+	}
+
+	if _, err := c.Load(strings.NewReader("1,alice,3\n"), config.WithColumns("id", "tmp", "name=upper(tmp)", "balance")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = c.Load(strings.NewReader("1,alice\n"), config.WithColumns("id", "nmae"))
+	var schemaErr *loader.SchemaError
+	if !errors.As(err, &schemaErr) || schemaErr.Problems[0] != `unknown column "nmae"` {
+		t.Errorf("expected a schema error, got %v", err)
+	}
+	if len(server.Loads()) != 1 {
+		t.Errorf("the mismatching load must not be sent, got %d loads", len(server.Loads()))
+	}
+
+	// The cached schema is fetched again when the table gained the column
+	server.SetSchema("test_db", "users", dorismock.UniqueKeys,
+		dorismock.Column{Name: "id", Type: "BIGINT", Key: true}, dorismock.Column{Name: "nmae", Type: "VARCHAR"})
+	if _, err := c.Load(strings.NewReader("1,alice\n"), config.WithColumns("id", "nmae")); err != nil {
+		t.Errorf("expected the refreshed schema to match, got %v", err)
+	}
+
+	type user struct {
+		ID      int64  `json:"id"`
+		Name    string `json:"nmae,omitempty"`
+		Ignored string `json:"-"`
+		Email   string
+	}
+	if err := c.ValidateStruct([]user{}); err == nil || !strings.Contains(err.Error(), `unknown column "Email"`) {
+		t.Errorf("expected Email to be reported, got %v", err)
+	}
+	if err := c.ValidateCSVHeader("id,nmae\n"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,595 @@
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/util"
+	"github.com/google/uuid"
+)
+
+const (
+	spoolSegmentPrefix = "seg-"
+	spoolSegmentSuffix = ".log"
+	spoolCursorFile    = "cursor.json"
+	spoolRejectedDir   = "rejected"
+
+	// spoolHeaderSize is the size of a record header: payload length, crc32, label length
+	spoolHeaderSize = 4 + 4 + 2
+	// maxSpoolBatchBytes is the largest batch the 32-bit payload length of a record can frame
+	maxSpoolBatchBytes = math.MaxUint32
+
+	defaultSpoolSegmentBytes  = 64 * 1024 * 1024
+	defaultSpoolMaxBytes      = 1024 * 1024 * 1024
+	defaultSpoolRetryInterval = 5000
+	spoolFlushPollInterval    = 50 * time.Millisecond
+)
+
+var (
+	// ErrSpoolFull is returned by Spool.Write when the spool is at capacity and BlockWhenFull is false
+	ErrSpoolFull = errors.New("spool is full")
+	// ErrSpoolClosed is returned by Spool.Write after Close
+	ErrSpoolClosed = errors.New("spool is closed")
+)
+
+// SpoolOptions configures a Spool
+type SpoolOptions struct {
+	Dir             string // Directory holding the segment log, required
+	MaxSegmentBytes int64  // Size after which a new segment is started (default 64MB)
+	MaxBytes        int64  // Maximum bytes waiting for upload (default 1GB)
+	BlockWhenFull   bool   // Block Write until space is available instead of returning ErrSpoolFull
+	RetryIntervalMs int64  // Wait between upload rounds while Doris is unavailable (default 5000)
+}
+
+// SpoolStats is a snapshot of the spool state
+type SpoolStats struct {
+	PendingBytes int64 // Bytes accepted but not yet visible in Doris
+	Segments     int   // Segment files on disk
+	Uploaded     int64 // Batches committed since the spool was opened
+	Rejected     int64 // Batches rejected by Doris since the spool was opened, see Spool
+}
+
+// spoolCursor is the position of the first batch not yet committed
+type spoolCursor struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+// spoolRecord is a batch read back from the segment log
+type spoolRecord struct {
+	label   string
+	payload []byte
+	size    int64 // Size of the framed record on disk
+}
+
+// Spool is a durable write-ahead log in front of a DorisLoadClient
+// Write fsyncs each batch to a local segment log and returns; a background uploader loads
+// batches in order with a label fixed at write time, so a batch uploaded before a crash is
+// deduplicated by Doris after restart. Batches are removed from the log once Doris reports
+// them visible. Batches Doris rejects go to the dead-letter sink of the client; without one they
+// are parked in the "rejected" sub-directory of the spool and reported by the next Flush.
+// Group commit must be OFF because it does not allow labels.
+type Spool struct {
+	client *DorisLoadClient
+	opts   SpoolOptions
+	parked *parkingSink
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	active   *os.File
+	activeID uint64
+	segments []uint64
+	sizes    map[uint64]int64
+	cursor   spoolCursor
+	pending  int64
+	uploaded int64
+	closed   bool
+
+	rejected   int64
+	unreported int64 // Batches parked since the last Flush
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewSpool opens (or recovers) the spool in opts.Dir and starts uploading through c
+func NewSpool(c *DorisLoadClient, opts SpoolOptions) (*Spool, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("spool dir cannot be empty")
+	}
+	if c.config.Load().GroupCommit != config.OFF {
+		return nil, fmt.Errorf("spool requires group commit OFF, labels are needed for deduplication")
+	}
+	if opts.MaxSegmentBytes <= 0 {
+		opts.MaxSegmentBytes = defaultSpoolSegmentBytes
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultSpoolMaxBytes
+	}
+	if opts.RetryIntervalMs <= 0 {
+		opts.RetryIntervalMs = defaultSpoolRetryInterval
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+
+	s := &Spool{
+		client: c,
+		opts:   opts,
+		parked: &parkingSink{dir: filepath.Join(opts.Dir, spoolRejectedDir)},
+		sizes:  make(map[uint64]int64),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	if err := s.recover(); err != nil {
+		s.cancel()
+		return nil, err
+	}
+
+	log.Infof("Spool opened at %s: %d segments, %d bytes pending", opts.Dir, len(s.segments), s.pending)
+	go s.run()
+	return s, nil
+}
+
+// Write durably appends a batch to the spool; it is uploaded in the background
+func (s *Spool) Write(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if int64(len(data)) > maxSpoolBatchBytes {
+		return fmt.Errorf("batch of %d bytes exceeds the spool limit of %d bytes per batch", len(data), int64(maxSpoolBatchBytes))
+	}
+
+	label := s.newLabel()
+	frame := encodeSpoolRecord(label, data)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		if s.closed {
+			return ErrSpoolClosed
+		}
+		// A single batch larger than MaxBytes is still accepted into an empty spool
+		if s.pending == 0 || s.pending+int64(len(frame)) <= s.opts.MaxBytes {
+			break
+		}
+		if !s.opts.BlockWhenFull {
+			return ErrSpoolFull
+		}
+		s.cond.Wait()
+	}
+
+	if s.sizes[s.activeID] >= s.opts.MaxSegmentBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	size := s.sizes[s.activeID]
+	if _, err := s.active.Write(frame); err != nil {
+		s.active.Truncate(size)
+		return fmt.Errorf("failed to write to spool: %w", err)
+	}
+	if err := s.active.Sync(); err != nil {
+		s.active.Truncate(size)
+		return fmt.Errorf("failed to sync spool: %w", err)
+	}
+
+	s.sizes[s.activeID] = size + int64(len(frame))
+	s.pending += int64(len(frame))
+	s.cond.Broadcast()
+	return nil
+}
+
+// Flush blocks until every batch written so far left the spool or timeout elapses
+// It returns an error if batches were parked because Doris rejected them since the last Flush.
+func (s *Spool) Flush(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		s.mu.Lock()
+		pending, closed, parked := s.pending, s.closed, s.unreported
+		if pending == 0 {
+			s.unreported = 0
+		}
+		s.mu.Unlock()
+
+		if pending == 0 {
+			if parked > 0 {
+				return fmt.Errorf("%d spooled batches were rejected by Doris and parked in %s", parked, s.parked.dir)
+			}
+			return nil
+		}
+		if closed {
+			return ErrSpoolClosed
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("spool flush timed out with %d bytes pending", pending)
+		}
+		time.Sleep(spoolFlushPollInterval)
+	}
+}
+
+// Close stops the uploader, canceling an upload in progress, and closes the segment log
+// Batches not yet uploaded stay on disk and are uploaded when the spool is opened again
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.stop)
+	s.cancel()
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.active.Close()
+}
+
+// Stats returns a snapshot of the spool state
+func (s *Spool) Stats() SpoolStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return SpoolStats{
+		PendingBytes: s.pending,
+		Segments:     len(s.segments),
+		Uploaded:     s.uploaded,
+		Rejected:     s.rejected,
+	}
+}
+
+// newLabel generates the label assigned to a batch when it enters the spool
+func (s *Spool) newLabel() string {
+	prefix := s.client.config.Load().LabelPrefix
+	if prefix == "" {
+		prefix = "load"
+	}
+	return fmt.Sprintf("%s_spool_%s", prefix, uuid.New().String())
+}
+
+// run is the background uploader loop
+func (s *Spool) run() {
+	defer close(s.done)
+
+	retryInterval := time.Duration(s.opts.RetryIntervalMs) * time.Millisecond
+	for {
+		record, ok := s.next()
+		if !ok {
+			return
+		}
+
+		result := s.upload(record)
+		for result == labeledRetry {
+			select {
+			case <-s.stop:
+				return
+			case <-time.After(retryInterval):
+			}
+			result = s.upload(record)
+		}
+
+		if err := s.ack(record.size, result); err != nil {
+			log.Errorf("Failed to persist spool cursor: %v", err)
+		}
+	}
+}
+
+// next blocks until a batch is available at the cursor and reads it
+func (s *Spool) next() (*spoolRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		if s.closed {
+			return nil, false
+		}
+
+		if s.cursor.Offset < s.sizes[s.cursor.Segment] {
+			// Only this goroutine removes segments, so the file can be read without holding the lock
+			cursor, size := s.cursor, s.sizes[s.cursor.Segment]
+			s.mu.Unlock()
+			record, err := readSpoolRecord(s.segmentPath(cursor.Segment), cursor.Offset, size-cursor.Offset)
+			s.mu.Lock()
+			if err != nil {
+				// Written records are fsynced and checksummed, this only happens on disk corruption
+				log.Errorf("Corrupted spool segment %d at offset %d, skipping rest of segment: %v", s.cursor.Segment, s.cursor.Offset, err)
+				s.pending -= s.sizes[s.cursor.Segment] - s.cursor.Offset
+				s.cursor.Offset = s.sizes[s.cursor.Segment]
+				continue
+			}
+			return record, true
+		}
+
+		if s.cursor.Segment != s.activeID {
+			if err := s.advanceSegment(); err != nil {
+				log.Errorf("Failed to advance spool segment: %v", err)
+			}
+			continue
+		}
+
+		s.cond.Wait()
+	}
+}
+
+// upload loads one batch through the client
+func (s *Spool) upload(record *spoolRecord) labeledResult {
+	return s.client.loadLabeled(s.ctx, "Spooled batch", record.label, record.payload, s.parked)
+}
+
+// ack moves the cursor past a batch that left the spool and persists it
+func (s *Spool) ack(size int64, result labeledResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cursor.Offset += size
+	s.pending -= size
+	switch result {
+	case labeledLoaded:
+		s.uploaded++
+	case labeledParked:
+		s.unreported++
+		s.rejected++
+	default:
+		s.rejected++
+	}
+	s.cond.Broadcast()
+	return s.saveCursor()
+}
+
+// advanceSegment deletes the fully committed cursor segment and moves to the next one
+func (s *Spool) advanceSegment() error {
+	old := s.cursor.Segment
+	idx := sort.Search(len(s.segments), func(i int) bool { return s.segments[i] > old })
+	s.cursor = spoolCursor{Segment: s.segments[idx], Offset: 0}
+	if err := s.saveCursor(); err != nil {
+		return err
+	}
+
+	s.segments = s.segments[idx:]
+	delete(s.sizes, old)
+	return os.Remove(s.segmentPath(old))
+}
+
+// rotate starts a new active segment
+func (s *Spool) rotate() error {
+	id := s.activeID + 1
+	f, err := os.OpenFile(s.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create spool segment: %w", err)
+	}
+	if err := util.SyncDir(s.opts.Dir); err != nil {
+		f.Close()
+		os.Remove(s.segmentPath(id))
+		return fmt.Errorf("failed to sync spool directory: %w", err)
+	}
+	if err := s.active.Close(); err != nil {
+		log.Warnf("Failed to close spool segment %d: %v", s.activeID, err)
+	}
+
+	s.active = f
+	s.activeID = id
+	s.segments = append(s.segments, id)
+	s.sizes[id] = 0
+	s.cond.Broadcast()
+	return nil
+}
+
+// recover rebuilds the in-memory state from the segment files and the cursor
+func (s *Spool) recover() error {
+	items, err := os.ReadDir(s.opts.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read spool directory: %w", err)
+	}
+	for _, item := range items {
+		name := item.Name()
+		if !strings.HasPrefix(name, spoolSegmentPrefix) || !strings.HasSuffix(name, spoolSegmentSuffix) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, spoolSegmentPrefix), spoolSegmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.segments = append(s.segments, id)
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i] < s.segments[j] })
+
+	if len(s.segments) == 0 {
+		s.segments = []uint64{1}
+	}
+	s.activeID = s.segments[len(s.segments)-1]
+
+	// Only the last segment can end with a torn write, earlier ones were complete when rotated
+	validSize, err := validSpoolSize(s.segmentPath(s.activeID))
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.segmentPath(s.activeID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open spool segment: %w", err)
+	}
+	if err := f.Truncate(validSize); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to truncate spool segment: %w", err)
+	}
+	if err := util.SyncDir(s.opts.Dir); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync spool directory: %w", err)
+	}
+	s.active = f
+
+	for _, id := range s.segments {
+		if id == s.activeID {
+			s.sizes[id] = validSize
+			continue
+		}
+		info, err := os.Stat(s.segmentPath(id))
+		if err != nil {
+			f.Close()
+			return fmt.Errorf("failed to stat spool segment: %w", err)
+		}
+		s.sizes[id] = info.Size()
+	}
+
+	if err := s.loadCursor(); err != nil {
+		f.Close()
+		return err
+	}
+
+	// Drop segments fully committed before a crash
+	for len(s.segments) > 1 && s.segments[0] < s.cursor.Segment {
+		os.Remove(s.segmentPath(s.segments[0]))
+		delete(s.sizes, s.segments[0])
+		s.segments = s.segments[1:]
+	}
+
+	for _, id := range s.segments {
+		s.pending += s.sizes[id]
+	}
+	s.pending -= s.cursor.Offset
+	return nil
+}
+
+// loadCursor reads the persisted cursor, defaulting to the start of the oldest segment
+func (s *Spool) loadCursor() error {
+	s.cursor = spoolCursor{Segment: s.segments[0]}
+
+	data, err := os.ReadFile(filepath.Join(s.opts.Dir, spoolCursorFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read spool cursor: %w", err)
+	}
+
+	var cursor spoolCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return fmt.Errorf("failed to parse spool cursor: %w", err)
+	}
+	if size, ok := s.sizes[cursor.Segment]; ok && cursor.Offset <= size {
+		s.cursor = cursor
+	} else if cursor.Segment > s.activeID {
+		return fmt.Errorf("spool cursor points to missing segment %d", cursor.Segment)
+	}
+	return nil
+}
+
+// saveCursor atomically persists the cursor
+func (s *Spool) saveCursor() error {
+	data, err := json.Marshal(s.cursor)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(filepath.Join(s.opts.Dir, spoolCursorFile), data)
+}
+
+// segmentPath returns the file path of segment id
+func (s *Spool) segmentPath(id uint64) string {
+	return filepath.Join(s.opts.Dir, fmt.Sprintf("%s%020d%s", spoolSegmentPrefix, id, spoolSegmentSuffix))
+}
+
+// encodeSpoolRecord frames a batch as header, label and payload
+func encodeSpoolRecord(label string, payload []byte) []byte {
+	frame := make([]byte, spoolHeaderSize+len(label)+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint16(frame[8:10], uint16(len(label)))
+	copy(frame[spoolHeaderSize:], label)
+	copy(frame[spoolHeaderSize+len(label):], payload)
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(frame[spoolHeaderSize:]))
+	return frame
+}
+
+// readSpoolRecord reads and verifies the record at offset of the segment file
+func readSpoolRecord(path string, offset int64, limit int64) (*spoolRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return decodeSpoolRecord(f, limit)
+}
+
+// decodeSpoolRecord reads one framed record of at most limit bytes from r
+func decodeSpoolRecord(r io.Reader, limit int64) (*spoolRecord, error) {
+	header := make([]byte, spoolHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	payloadLen := binary.BigEndian.Uint32(header[0:4])
+	checksum := binary.BigEndian.Uint32(header[4:8])
+	labelLen := binary.BigEndian.Uint16(header[8:10])
+	if int64(spoolHeaderSize)+int64(labelLen)+int64(payloadLen) > limit {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	body := make([]byte, int(labelLen)+int(payloadLen))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(body) != checksum {
+		return nil, fmt.Errorf("checksum mismatch")
+	}
+
+	return &spoolRecord{
+		label:   string(body[:labelLen]),
+		payload: body[labelLen:],
+		size:    int64(spoolHeaderSize + len(body)),
+	}, nil
+}
+
+// validSpoolSize returns the length of the valid prefix of a segment file
+func validSpoolSize(path string) (int64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open spool segment: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat spool segment: %w", err)
+	}
+
+	var size int64
+	for {
+		record, err := decodeSpoolRecord(f, info.Size()-size)
+		if err != nil {
+			if err != io.EOF {
+				log.Warnf("Discarding torn write at offset %d of spool segment %s: %v", size, path, err)
+			}
+			return size, nil
+		}
+		size += record.size
+	}
+}
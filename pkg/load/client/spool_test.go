@@ -0,0 +1,161 @@
+package client_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/deadletter"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestSpoolRecoversAndDeduplicates(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t)
+
+	dir := t.TempDir()
+	open := func() *client.Spool {
+		t.Helper()
+		spool, err := client.NewSpool(c, client.SpoolOptions{Dir: dir, RetryIntervalMs: 10})
+		if err != nil {
+			t.Fatalf("failed to open spool: %v", err)
+		}
+		return spool
+	}
+
+	spool := open()
+	if err := spool.Write([]byte("1,alice\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := spool.Flush(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	spool.Close()
+
+	// The cursor survives a reopen, committed batches are not loaded again
+	spool = open()
+	if err := spool.Flush(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	spool.Close()
+	if loads := server.Loads(); len(loads) != 1 {
+		t.Fatalf("expected 1 load after reopen, got %d", len(loads))
+	}
+
+	// A crash before the cursor was saved replays the batch, Doris deduplicates its label,
+	// and a torn trailing record is discarded
+	if err := os.Remove(filepath.Join(dir, client.SpoolCursorFile)); err != nil {
+		t.Fatal(err)
+	}
+	segment := filepath.Join(dir, fmt.Sprintf("%s%020d%s", client.SpoolSegmentPrefix, 1, client.SpoolSegmentSuffix))
+	info, err := os.Stat(segment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	torn := client.EncodeSpoolRecord("torn", []byte("2,bob\n"))
+	f, err := os.OpenFile(segment, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write(torn[:len(torn)-3])
+	f.Close()
+
+	spool = open()
+	defer spool.Close()
+	if err := spool.Flush(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if stats := spool.Stats(); stats.Uploaded != 1 || stats.PendingBytes != 0 {
+		t.Errorf("unexpected stats after replay: %+v", stats)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 1 {
+		t.Errorf("replayed batch must be deduplicated, got rows %q", rows)
+	}
+	loads := server.Loads()
+	if len(loads) != 2 || loads[0].Label != loads[1].Label {
+		t.Errorf("expected the batch to be replayed with its label, got %+v", loads)
+	}
+	if after, err := os.Stat(segment); err != nil || after.Size() != info.Size() {
+		t.Errorf("torn record was not truncated: %v", err)
+	}
+}
+
+func TestSpoolRollsOverSegments(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithRetry(&config.Retry{}))
+
+	// Doris is unavailable until the faults are cleared, batches pile up in the spool
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultHTTPStatus, StatusCode: 503})
+	spool, err := client.NewSpool(c, client.SpoolOptions{Dir: t.TempDir(), MaxSegmentBytes: 1, RetryIntervalMs: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := spool.Write([]byte(fmt.Sprintf("%d,user_%d\n", i, i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := spool.Stats(); stats.Segments != 3 || stats.PendingBytes == 0 {
+		t.Errorf("expected 3 pending segments, got %+v", stats)
+	}
+
+	server.ClearFaults()
+	if err := spool.Flush(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 3 {
+		t.Errorf("expected 3 rows in order, got %q", rows)
+	}
+	if err := spool.Write([]byte("3,user_3\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := spool.Flush(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if stats := spool.Stats(); stats.Segments != 1 || stats.Uploaded != 4 {
+		t.Errorf("committed segments must be removed, got %+v", stats)
+	}
+}
+
+func TestSpoolParksRejectedBatches(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t)
+
+	dir := t.TempDir()
+	spool, err := client.NewSpool(c, client.SpoolOptions{Dir: dir, RetryIntervalMs: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+
+	server.InjectFault(dorismock.Fault{Type: dorismock.FaultLoadFailed, Message: "too many filtered rows", Times: 1})
+	spool.Write([]byte("bad\n"))
+	spool.Write([]byte("1,alice\n"))
+	if err := spool.Flush(5 * time.Second); err == nil || !strings.Contains(err.Error(), "parked") {
+		t.Errorf("expected Flush to report the parked batch, got %v", err)
+	}
+	if err := spool.Flush(5 * time.Second); err != nil {
+		t.Errorf("a parked batch is reported once, got %v", err)
+	}
+
+	if stats := spool.Stats(); stats.Uploaded != 1 || stats.Rejected != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	entries, err := deadletter.ReadDir(filepath.Join(dir, client.SpoolRejectedDir))
+	if err != nil || len(entries) != 1 || entries[0].Record.Reason != deadletter.ReasonFailed {
+		t.Fatalf("expected one parked entry, got %v, %v", entries, err)
+	}
+	if payload, _ := entries[0].ReadPayload(); string(payload) != "bad\n" {
+		t.Errorf("unexpected parked payload %q", payload)
+	}
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/ddl"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+// EnsureTableOptions configures EnsureTable
+type EnsureTableOptions struct {
+	// AddMissingColumns adds the columns of the definition the existing table lacks, as nullable columns
+	AddMissingColumns bool
+}
+
+// ExecuteSQL executes a statement, e.g. DDL, in the configured database through the FE HTTP SQL API
+func (c *DorisLoadClient) ExecuteSQL(stmt string) error {
+	return c.ExecuteSQLContext(context.Background(), stmt)
+}
+
+// ExecuteSQLContext is ExecuteSQL with a context
+func (c *DorisLoadClient) ExecuteSQLContext(ctx context.Context, stmt string) error {
+	cfg := c.config.Load()
+	return c.executeSQL(ctx, cfg, cfg.Database, stmt)
+}
+
+// EnsureTable creates the table defined by t if it does not exist
+// An empty t.Database or t.Name defaults to the configured database or table. The statements run
+// through the FE HTTP SQL API; use t.CreateSQL with a MySQL driver to run them over the MySQL protocol.
+// With AddMissingColumns the columns of t the table lacks are added, which Doris may apply
+// asynchronously on tables without light schema change.
+func (c *DorisLoadClient) EnsureTable(t *ddl.Table, opts EnsureTableOptions) error {
+	return c.EnsureTableContext(context.Background(), t, opts)
+}
+
+// EnsureTableContext is EnsureTable with a context
+func (c *DorisLoadClient) EnsureTableContext(ctx context.Context, t *ddl.Table, opts EnsureTableOptions) error {
+	cfg := c.config.Load().Clone()
+	table := *t
+	if table.Database == "" {
+		table.Database = cfg.Database
+	}
+	if table.Name == "" {
+		table.Name = cfg.Table
+	}
+	cfg.Database, cfg.Table = table.Database, table.Name
+
+	stmt, err := table.CreateSQL()
+	if err != nil {
+		return fmt.Errorf("invalid definition of table %s.%s: %w", table.Database, table.Name, err)
+	}
+	if err := c.executeSQL(ctx, cfg, table.Database, stmt); err != nil {
+		return fmt.Errorf("failed to create table %s.%s: %w", table.Database, table.Name, err)
+	}
+
+	schema, err := c.fetchSchema(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	var missing []ddl.Column
+	for _, column := range table.Columns {
+		if _, ok := schema.Column(column.Name); !ok {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(missing))
+	for i, column := range missing {
+		names[i] = column.Name
+	}
+	if !opts.AddMissingColumns {
+		log.Warnf("Table %s.%s lacks columns %s, enable AddMissingColumns to add them",
+			table.Database, table.Name, strings.Join(names, ", "))
+		return nil
+	}
+
+	stmt, err = table.AddColumnsSQL(missing...)
+	if err != nil {
+		return err
+	}
+	if err := c.executeSQL(ctx, cfg, table.Database, stmt); err != nil {
+		return fmt.Errorf("failed to add columns to %s.%s: %w", table.Database, table.Name, err)
+	}
+	log.Infof("Added columns %s to %s.%s", strings.Join(names, ", "), table.Database, table.Name)
+
+	// Validation against the cached schema must see the new columns
+	_, err = c.fetchSchema(ctx, cfg)
+	return err
+}
+
+// executeSQL executes stmt in database
+func (c *DorisLoadClient) executeSQL(ctx context.Context, cfg *config.Config, database, stmt string) error {
+	log.Debugf("Executing SQL in %s: %s", database, stmt)
+	return c.doWithCredentials(ctx, cfg, func() (*http.Request, error) {
+		return loader.CreateQueryRequest(cfg, database, stmt)
+	}, func(req *http.Request) error {
+		return c.streamLoader.ExecuteQuery(req)
+	})
+}
@@ -0,0 +1,63 @@
+package client_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/ddl"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestEnsureTableCreatesAndEvolves(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithJSON(config.JSONObjectLine), config.WithSchemaValidation())
+
+	table := &ddl.Table{Name: "events", Columns: []ddl.Column{
+		{Name: "id", Type: "BIGINT"},
+		{Name: "kind", Type: "STRING", Nullable: true},
+	}}
+	if err := table.SetKeys(ddl.Unique, "id"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.EnsureTable(table, client.EnsureTableOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	columns, ok := server.Schema("test_db", "events")
+	if !ok || len(columns) != 2 || !columns[0].Key || columns[0].Nullable || !columns[1].Nullable {
+		t.Fatalf("unexpected table %+v", columns)
+	}
+
+	// A new field is only added when enabled, as a nullable column
+	table.Columns = append(table.Columns, ddl.Column{Name: "source", Type: "VARCHAR(64)"})
+	if err := c.EnsureTable(table, client.EnsureTableOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if columns, _ := server.Schema("test_db", "events"); len(columns) != 2 {
+		t.Errorf("columns must not be added unless enabled, got %+v", columns)
+	}
+	if err := c.EnsureTable(table, client.EnsureTableOptions{AddMissingColumns: true}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	columns, _ = server.Schema("test_db", "events")
+	if len(columns) != 3 || columns[2].Name != "source" || !columns[2].Nullable || columns[2].Type != "VARCHAR" {
+		t.Errorf("unexpected columns after evolution %+v", columns)
+	}
+
+	statements := server.Statements()
+	if len(statements) != 4 || statements[3] != "ALTER TABLE `test_db`.`events` ADD COLUMN (`source` VARCHAR(64) NULL)" {
+		t.Errorf("unexpected statements %q", statements)
+	}
+
+	// Loads into the new table proceed, validated against the evolved schema
+	_, err := c.Load(strings.NewReader(`{"id":1,"kind":"click","source":"web"}`+"\n"),
+		config.WithTable("events"), config.WithColumns("id", "kind", "source"))
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if rows := server.Rows("test_db", "events"); len(rows) != 1 {
+		t.Errorf("unexpected rows %q", rows)
+	}
+}
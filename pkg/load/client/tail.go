@@ -0,0 +1,787 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/util"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTailBatchBytes       = 8 * 1024 * 1024
+	defaultTailFlushInterval    = 5000
+	defaultTailPollInterval     = 500
+	defaultTailDiscoverInterval = 10000
+	defaultTailRetryInterval    = 5000
+	tailRejectedSuffix          = ".rejected"
+
+	// tailFingerprintBytes is how much of the head of a file identifies it across renames
+	tailFingerprintBytes = 1024
+	tailReadChunk        = 256 * 1024
+)
+
+// ErrTailClosed is returned by TailLoader.Flush after Close
+var ErrTailClosed = errors.New("tail loader is closed")
+
+// TailOptions configures a TailLoader
+type TailOptions struct {
+	Paths              []string // Files or glob patterns to follow, required
+	Checkpoint         string   // File holding the committed offsets, required
+	BatchBytes         int64    // Size at which a batch is loaded (default 8MB)
+	FlushIntervalMs    int64    // Maximum time lines wait before their batch is loaded (default 5000)
+	PollIntervalMs     int64    // Interval between reads of the followed files (default 500)
+	DiscoverIntervalMs int64    // Interval between glob evaluations looking for new files (default 10000)
+	RetryIntervalMs    int64    // Wait between load attempts while Doris is unavailable (default 5000)
+	StartAtEnd         bool     // Skip existing content of files found at startup that have no checkpoint
+}
+
+// TailStats is a snapshot of the tail loader state
+type TailStats struct {
+	Files        int   // Files being followed
+	PendingBytes int64 // Bytes read but not yet committed
+	Loaded       int64 // Batches committed since the loader was started
+	Lines        int64 // Lines committed since the loader was started
+	Rejected     int64 // Batches rejected by Doris since the loader was started, see TailLoader
+}
+
+// tailPosition is the committed offset of a file, identified by a checksum of its head
+// so a file is recognized after it was renamed by log rotation
+type tailPosition struct {
+	Path           string `json:"path"`
+	Fingerprint    uint32 `json:"fingerprint"`
+	FingerprintLen int64  `json:"fingerprint_len"`
+	Offset         int64  `json:"offset"`
+}
+
+// tailRange is the part of a file carried by a batch, Offset being its end
+type tailRange struct {
+	tailPosition
+	Start int64 `json:"start"`
+}
+
+// tailPending is a batch whose load started but whose commit was not yet recorded
+type tailPending struct {
+	Label  string      `json:"label"`
+	Ranges []tailRange `json:"ranges"`
+}
+
+// tailCheckpoint is the content of the checkpoint file
+type tailCheckpoint struct {
+	Files   []tailPosition `json:"files"`
+	Pending *tailPending   `json:"pending,omitempty"`
+}
+
+// tailFile is a file followed by the loader
+type tailFile struct {
+	path      string
+	file      *os.File
+	offset    int64  // End of the last complete line read
+	partial   []byte // Start of an incomplete line, read past offset
+	committed int64  // Offset recorded in the checkpoint
+	rotated   bool   // path names another file now, the handle is read to its end and dropped
+}
+
+// tailBatch collects complete lines until they are loaded
+type tailBatch struct {
+	buf    bytes.Buffer
+	lines  int64
+	files  []*tailFile
+	starts map[*tailFile]int64
+	first  time.Time
+}
+
+// TailLoader follows log files and loads their lines through a DorisLoadClient
+// Lines are batched by size and age and loaded with a label recorded in the checkpoint file
+// before the load starts; the offsets advance only after Doris commits the batch. After a
+// restart, a batch whose commit was not recorded is replayed with the same label, which Doris
+// deduplicates, so every line is loaded exactly once. Files are identified by a checksum of
+// their first bytes, which follows them across rotation by rename; a file that shrinks is
+// considered truncated and read again from the start. Lines must end with "\n", so the
+// client format must be JSON lines or CSV with "\n" as line delimiter, and group commit must
+// be OFF because it does not allow labels. Batches Doris rejects go to the dead-letter sink of
+// the client; without one they are parked in the directory named after the checkpoint file with
+// a ".rejected" suffix and reported by the next Flush.
+type TailLoader struct {
+	client *DorisLoadClient
+	opts   TailOptions
+	parked *parkingSink
+
+	// Only the run goroutine touches these
+	files    []*tailFile
+	batch    tailBatch
+	restored []tailPosition // Positions of files not followed, matched to files at the next discovery
+	pending  *tailPending
+
+	mu         sync.Mutex
+	stats      TailStats
+	unreported int64 // Batches parked since the last Flush
+	closed     bool
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	flushes chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewTailLoader starts following opts.Paths and loading their lines through c
+func NewTailLoader(c *DorisLoadClient, opts TailOptions) (*TailLoader, error) {
+	if len(opts.Paths) == 0 {
+		return nil, fmt.Errorf("tail paths cannot be empty")
+	}
+	if opts.Checkpoint == "" {
+		return nil, fmt.Errorf("tail checkpoint cannot be empty")
+	}
+	for _, pattern := range opts.Paths {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid tail path %q: %w", pattern, err)
+		}
+	}
+
+	cfg := c.config.Load()
+	if cfg.GroupCommit != config.OFF {
+		return nil, fmt.Errorf("tail loader requires group commit OFF, labels are needed for deduplication")
+	}
+	switch format := cfg.Format.(type) {
+	case *config.JSONFormat:
+		if format.Type == config.JSONArray {
+			return nil, fmt.Errorf("tail loader requires JSON lines, not a JSON array")
+		}
+	case *config.CSVFormat:
+		if config.UnescapeDelimiter(format.LineDelimiter) != "\n" {
+			return nil, fmt.Errorf("tail loader requires \\n as CSV line delimiter")
+		}
+	}
+
+	if opts.BatchBytes <= 0 {
+		opts.BatchBytes = defaultTailBatchBytes
+	}
+	if opts.FlushIntervalMs <= 0 {
+		opts.FlushIntervalMs = defaultTailFlushInterval
+	}
+	if opts.PollIntervalMs <= 0 {
+		opts.PollIntervalMs = defaultTailPollInterval
+	}
+	if opts.DiscoverIntervalMs <= 0 {
+		opts.DiscoverIntervalMs = defaultTailDiscoverInterval
+	}
+	if opts.RetryIntervalMs <= 0 {
+		opts.RetryIntervalMs = defaultTailRetryInterval
+	}
+
+	t := &TailLoader{
+		client:  c,
+		opts:    opts,
+		parked:  &parkingSink{dir: opts.Checkpoint + tailRejectedSuffix},
+		flushes: make(chan chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	t.batch.starts = make(map[*tailFile]int64)
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+
+	if err := t.loadCheckpoint(); err != nil {
+		t.cancel()
+		return nil, err
+	}
+
+	go t.run()
+	return t, nil
+}
+
+// Flush loads every line written to the followed files so far and blocks until Doris
+// committed them or timeout elapses
+// It returns an error if batches were parked because Doris rejected them since the last Flush.
+func (t *TailLoader) Flush(timeout time.Duration) error {
+	reply := make(chan struct{})
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case t.flushes <- reply:
+	case <-t.done:
+		return ErrTailClosed
+	case <-timer.C:
+		return fmt.Errorf("tail flush timed out")
+	}
+
+	select {
+	case <-reply:
+		t.mu.Lock()
+		parked := t.unreported
+		t.unreported = 0
+		t.mu.Unlock()
+		if parked > 0 {
+			return fmt.Errorf("%d tail batches were rejected by Doris and parked in %s", parked, t.parked.dir)
+		}
+		return nil
+	case <-t.done:
+		return ErrTailClosed
+	case <-timer.C:
+		return fmt.Errorf("tail flush timed out with %d bytes pending", t.Stats().PendingBytes)
+	}
+}
+
+// Close stops the loader, canceling a load in progress, and closes the followed files
+// Lines not yet committed are read again when the loader is started with the same checkpoint
+func (t *TailLoader) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	close(t.stop)
+	t.cancel()
+	t.mu.Unlock()
+
+	<-t.done
+
+	for _, tf := range t.files {
+		tf.file.Close()
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the tail loader state
+func (t *TailLoader) Stats() TailStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// newLabel generates the label of a batch
+func (t *TailLoader) newLabel() string {
+	prefix := t.client.config.Load().LabelPrefix
+	if prefix == "" {
+		prefix = "load"
+	}
+	return fmt.Sprintf("%s_tail_%s", prefix, uuid.New().String())
+}
+
+// run is the background loop reading the files and loading batches
+func (t *TailLoader) run() {
+	defer close(t.done)
+
+	t.discover(true)
+	if t.pending != nil && !t.replay() {
+		return
+	}
+
+	poll := time.NewTicker(time.Duration(t.opts.PollIntervalMs) * time.Millisecond)
+	defer poll.Stop()
+	discoverInterval := time.Duration(t.opts.DiscoverIntervalMs) * time.Millisecond
+	lastDiscover := time.Now()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case reply := <-t.flushes:
+			t.discover(false)
+			lastDiscover = time.Now()
+			if t.poll(true) {
+				close(reply)
+			}
+		case <-poll.C:
+			if time.Since(lastDiscover) >= discoverInterval {
+				t.discover(false)
+				lastDiscover = time.Now()
+			}
+			t.poll(false)
+		}
+	}
+}
+
+// discover evaluates the globs and starts following files not followed yet
+// At startup, files resume from their checkpointed offset and, if StartAtEnd is set, files
+// without one start at their end. Files appearing later are read from the start.
+func (t *TailLoader) discover(startup bool) {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, pattern := range t.opts.Paths {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if t.following(path) {
+			continue
+		}
+		if err := t.follow(path, startup); err != nil {
+			log.Warnf("Failed to follow %s: %v", path, err)
+		}
+	}
+
+	// Positions no discovered file matched belong to files that are gone
+	t.restored = nil
+	t.updateStats()
+}
+
+// following reports whether path names a file being followed
+func (t *TailLoader) following(path string) bool {
+	for _, tf := range t.files {
+		if tf.path == path && !tf.rotated {
+			return true
+		}
+	}
+	return false
+}
+
+// follow opens path and starts following it
+func (t *TailLoader) follow(path string, startup bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		f.Close()
+		return nil
+	}
+
+	for _, tf := range t.files {
+		if other, err := tf.file.Stat(); err == nil && os.SameFile(info, other) {
+			// A followed file was renamed to a path matching the globs, it is followed under its new
+			// name and a file replacing it at its old path is new
+			f.Close()
+			old, rotated := tf.path, tf.rotated
+			tf.path, tf.rotated = path, false
+			if _, err := os.Stat(old); err == nil && !rotated && !t.following(old) {
+				return t.follow(old, false)
+			}
+			return nil
+		}
+	}
+
+	tf := &tailFile{path: path, file: f}
+	if start, ok := t.pendingStart(f); ok {
+		tf.offset, tf.committed = start, start
+	} else if pos, ok := t.restoredPosition(f, path, info.Size()); ok {
+		tf.offset, tf.committed = pos.Offset, pos.Offset
+	} else if startup && t.opts.StartAtEnd {
+		tf.offset, tf.committed = info.Size(), info.Size()
+	}
+	if _, err := f.Seek(tf.offset, io.SeekStart); err != nil {
+		f.Close()
+		return err
+	}
+
+	log.Infof("Following %s from offset %d", path, tf.offset)
+	t.files = append(t.files, tf)
+	return nil
+}
+
+// pendingStart returns where the pending batch starts in f, if it covers f
+func (t *TailLoader) pendingStart(f *os.File) (int64, bool) {
+	if t.pending == nil {
+		return 0, false
+	}
+	for _, r := range t.pending.Ranges {
+		if matchesFingerprint(f, r.tailPosition) {
+			return r.Start, true
+		}
+	}
+	return 0, false
+}
+
+// restoredPosition takes the position of a file out of the restored ones, preferring one
+// recorded under the same path
+func (t *TailLoader) restoredPosition(f *os.File, path string, size int64) (tailPosition, bool) {
+	found := -1
+	for i, pos := range t.restored {
+		if pos.Offset > size || !matchesFingerprint(f, pos) {
+			continue
+		}
+		if found < 0 || pos.Path == path {
+			found = i
+		}
+	}
+	if found < 0 {
+		return tailPosition{}, false
+	}
+
+	pos := t.restored[found]
+	t.restored = append(t.restored[:found], t.restored[found+1:]...)
+	return pos, true
+}
+
+// poll checks the files for rotation, reads new lines and loads the batch when it is due
+// force loads the batch regardless of its age. It returns false if the loader was stopped.
+func (t *TailLoader) poll(force bool) bool {
+	for i := 0; i < len(t.files); i++ {
+		tf := t.files[i]
+		if !tf.rotated && !t.checkRotation(tf) {
+			return false
+		}
+		if !t.read(tf) {
+			return false
+		}
+	}
+
+	if t.batch.buf.Len() > 0 {
+		due := time.Since(t.batch.first) >= time.Duration(t.opts.FlushIntervalMs)*time.Millisecond
+		if (force || due) && !t.commit() {
+			return false
+		}
+	}
+
+	t.dropRotated()
+	t.updateStats()
+	return true
+}
+
+// checkRotation detects a followed path that was truncated, or renamed and replaced by a new file
+// It returns false if the loader was stopped.
+func (t *TailLoader) checkRotation(tf *tailFile) bool {
+	current, err := tf.file.Stat()
+	if err != nil {
+		return true
+	}
+
+	info, err := os.Stat(tf.path)
+	if err != nil || !os.SameFile(current, info) {
+		log.Infof("%s was rotated, reading the old file to its end", tf.path)
+		tf.rotated = true
+		if err == nil {
+			if err := t.follow(tf.path, false); err != nil {
+				log.Warnf("Failed to follow %s: %v", tf.path, err)
+			}
+		}
+		return true
+	}
+
+	if info.Size() < tf.offset+int64(len(tf.partial)) {
+		log.Warnf("%s was truncated, reading it from the start", tf.path)
+		if _, ok := t.batch.starts[tf]; ok && !t.commit() {
+			// Lines read before the truncation cannot share a batch with the new content
+			return false
+		}
+		if _, err := tf.file.Seek(0, io.SeekStart); err != nil {
+			log.Errorf("Failed to rewind %s: %v", tf.path, err)
+			return true
+		}
+		tf.offset, tf.committed, tf.partial = 0, 0, nil
+	}
+	return true
+}
+
+// read appends the complete lines available in tf to the batch, loading it whenever it is full
+func (t *TailLoader) read(tf *tailFile) bool {
+	chunk := make([]byte, tailReadChunk)
+	for {
+		n, err := tf.file.Read(chunk)
+		if n > 0 {
+			data := append(tf.partial, chunk[:n]...)
+			end := bytes.LastIndexByte(data, '\n') + 1
+			t.append(tf, data[:end])
+			tf.partial = append([]byte(nil), data[end:]...)
+
+			if int64(t.batch.buf.Len()) >= t.opts.BatchBytes && !t.commit() {
+				return false
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				log.Warnf("Failed to read %s: %v", tf.path, err)
+			}
+			break
+		}
+	}
+
+	if tf.rotated && len(tf.partial) > 0 {
+		// Nothing is appended to a rotated file any more, its last line is complete
+		line := append(tf.partial, '\n')
+		tf.partial = nil
+		t.append(tf, line)
+		tf.offset-- // The added newline is not part of the file
+	}
+	return true
+}
+
+// append adds complete lines of tf to the batch
+func (t *TailLoader) append(tf *tailFile, lines []byte) {
+	if len(lines) == 0 {
+		return
+	}
+	if t.batch.buf.Len() == 0 {
+		t.batch.first = time.Now()
+	}
+	if _, ok := t.batch.starts[tf]; !ok {
+		t.batch.files = append(t.batch.files, tf)
+		t.batch.starts[tf] = tf.offset
+	}
+	t.batch.buf.Write(lines)
+	t.batch.lines += int64(bytes.Count(lines, []byte{'\n'}))
+	tf.offset += int64(len(lines))
+}
+
+// commit loads the batch, retrying until Doris commits it, and advances the checkpoint
+// It returns false if the loader was stopped first.
+func (t *TailLoader) commit() bool {
+	pending := &tailPending{Label: t.newLabel()}
+	for _, tf := range t.batch.files {
+		pos, err := position(tf, tf.offset)
+		if err != nil {
+			log.Errorf("Failed to fingerprint %s: %v", tf.path, err)
+		}
+		pending.Ranges = append(pending.Ranges, tailRange{tailPosition: pos, Start: t.batch.starts[tf]})
+	}
+
+	// The label is recorded first, so a batch loaded just before a crash is deduplicated after
+	// restart; loading without it recorded could load the batch twice
+	t.pending = pending
+	if !t.savePending() {
+		return false
+	}
+	result := t.loadUntilCommitted(pending.Label, t.batch.buf.Bytes())
+	if result == labeledRetry {
+		return false
+	}
+
+	for _, tf := range t.batch.files {
+		tf.committed = tf.offset
+	}
+	t.pending = nil
+	if err := t.saveCheckpoint(); err != nil {
+		log.Errorf("Failed to persist tail checkpoint: %v", err)
+	}
+
+	t.mu.Lock()
+	if result == labeledLoaded {
+		t.stats.Loaded++
+		t.stats.Lines += t.batch.lines
+	} else {
+		t.countRejected(result)
+	}
+	t.mu.Unlock()
+
+	t.batch.buf.Reset()
+	t.batch.lines = 0
+	t.batch.files = nil
+	t.batch.starts = make(map[*tailFile]int64)
+	return true
+}
+
+// replay loads the batch recorded as pending in the checkpoint again with its label
+func (t *TailLoader) replay() bool {
+	var payload bytes.Buffer
+	files := make([]*tailFile, len(t.pending.Ranges))
+	for i, r := range t.pending.Ranges {
+		for _, tf := range t.files {
+			if tf.committed == r.Start && matchesFingerprint(tf.file, r.tailPosition) {
+				files[i] = tf
+				break
+			}
+		}
+		if files[i] == nil {
+			log.Warnf("Cannot replay tail batch %s, %s is gone; its lines are read again", t.pending.Label, r.Path)
+			t.pending = nil
+			return true
+		}
+
+		data := make([]byte, r.Offset-r.Start)
+		if _, err := files[i].file.ReadAt(data, r.Start); err != nil {
+			log.Warnf("Cannot replay tail batch %s, failed to read %s: %v", t.pending.Label, r.Path, err)
+			t.pending = nil
+			return true
+		}
+		payload.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			payload.WriteByte('\n')
+		}
+	}
+
+	log.Infof("Replaying tail batch %s recorded before restart", t.pending.Label)
+	result := t.loadUntilCommitted(t.pending.Label, payload.Bytes())
+	if result == labeledRetry {
+		return false
+	}
+	if result != labeledLoaded {
+		t.mu.Lock()
+		t.countRejected(result)
+		t.mu.Unlock()
+	}
+
+	for i, r := range t.pending.Ranges {
+		tf := files[i]
+		if _, err := tf.file.Seek(r.Offset, io.SeekStart); err != nil {
+			log.Errorf("Failed to seek %s: %v", tf.path, err)
+			continue
+		}
+		tf.offset, tf.committed = r.Offset, r.Offset
+	}
+	t.pending = nil
+	if err := t.saveCheckpoint(); err != nil {
+		log.Errorf("Failed to persist tail checkpoint: %v", err)
+	}
+	return true
+}
+
+// savePending persists the checkpoint with the pending batch, retrying until it succeeds
+// It returns false if the loader was stopped first.
+func (t *TailLoader) savePending() bool {
+	retryInterval := time.Duration(t.opts.RetryIntervalMs) * time.Millisecond
+	for {
+		err := t.saveCheckpoint()
+		if err == nil {
+			return true
+		}
+		log.Errorf("Failed to persist tail checkpoint before loading batch %s, will retry: %v", t.pending.Label, err)
+		select {
+		case <-t.stop:
+			return false
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// loadUntilCommitted loads payload with label, retrying until it is committed or rejected
+// It returns labeledRetry if the loader was stopped first.
+func (t *TailLoader) loadUntilCommitted(label string, payload []byte) labeledResult {
+	retryInterval := time.Duration(t.opts.RetryIntervalMs) * time.Millisecond
+	for {
+		result := t.client.loadLabeled(t.ctx, "Tail batch", label, payload, t.parked)
+		if result != labeledRetry {
+			return result
+		}
+		select {
+		case <-t.stop:
+			return labeledRetry
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// countRejected records a batch Doris rejected; t.mu must be held
+func (t *TailLoader) countRejected(result labeledResult) {
+	t.stats.Rejected++
+	if result == labeledParked {
+		t.unreported++
+	}
+}
+
+// dropRotated stops following rotated files whose lines are all committed
+// Their positions are kept until the next discovery, which follows them again if the globs
+// still match their new name.
+func (t *TailLoader) dropRotated() {
+	kept := t.files[:0]
+	for _, tf := range t.files {
+		if tf.rotated && len(tf.partial) == 0 && tf.committed == tf.offset {
+			log.Infof("Finished reading rotated file %s", tf.path)
+			if pos, err := position(tf, tf.committed); err == nil && pos.Offset > 0 {
+				t.restored = append(t.restored, pos)
+			}
+			tf.file.Close()
+			continue
+		}
+		kept = append(kept, tf)
+	}
+	t.files = kept
+}
+
+// updateStats refreshes the file and pending byte counts
+func (t *TailLoader) updateStats() {
+	var pending int64
+	for _, tf := range t.files {
+		pending += tf.offset - tf.committed + int64(len(tf.partial))
+	}
+
+	t.mu.Lock()
+	t.stats.Files = len(t.files)
+	t.stats.PendingBytes = pending
+	t.mu.Unlock()
+}
+
+// loadCheckpoint reads the committed positions and the pending batch, if any
+func (t *TailLoader) loadCheckpoint() error {
+	data, err := os.ReadFile(t.opts.Checkpoint)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read tail checkpoint: %w", err)
+	}
+
+	var checkpoint tailCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return fmt.Errorf("failed to parse tail checkpoint: %w", err)
+	}
+	t.restored = checkpoint.Files
+	t.pending = checkpoint.Pending
+	return nil
+}
+
+// saveCheckpoint atomically persists the committed positions and the pending batch
+func (t *TailLoader) saveCheckpoint() error {
+	checkpoint := tailCheckpoint{Files: append([]tailPosition{}, t.restored...), Pending: t.pending}
+	for _, tf := range t.files {
+		if tf.committed == 0 {
+			continue
+		}
+		pos, err := position(tf, tf.committed)
+		if err != nil {
+			return fmt.Errorf("failed to fingerprint %s: %w", tf.path, err)
+		}
+		checkpoint.Files = append(checkpoint.Files, pos)
+	}
+
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(t.opts.Checkpoint, data)
+}
+
+// position returns offset of tf along with the fingerprint of the head of the file up to offset
+func position(tf *tailFile, offset int64) (tailPosition, error) {
+	pos := tailPosition{Path: tf.path, Offset: offset, FingerprintLen: offset}
+	if pos.FingerprintLen > tailFingerprintBytes {
+		pos.FingerprintLen = tailFingerprintBytes
+	}
+
+	head := make([]byte, pos.FingerprintLen)
+	if _, err := tf.file.ReadAt(head, 0); err != nil {
+		return pos, err
+	}
+	pos.Fingerprint = crc32.ChecksumIEEE(head)
+	return pos, nil
+}
+
+// matchesFingerprint reports whether the head of f matches the fingerprint of pos
+func matchesFingerprint(f *os.File, pos tailPosition) bool {
+	if pos.FingerprintLen <= 0 {
+		return false
+	}
+	head := make([]byte, pos.FingerprintLen)
+	if _, err := f.ReadAt(head, 0); err != nil {
+		return false
+	}
+	return crc32.ChecksumIEEE(head) == pos.Fingerprint
+}
@@ -0,0 +1,156 @@
+package client_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestTailLoaderFollowsRotationAndResumes(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithFormat(&config.JSONFormat{Type: config.JSONObjectLine}))
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	checkpoint := filepath.Join(dir, "tail.json")
+	appendLines := func(path string, lines ...string) {
+		t.Helper()
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(strings.Join(lines, "")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := func() *client.TailLoader {
+		t.Helper()
+		tail, err := client.NewTailLoader(c, client.TailOptions{
+			Paths:          []string{filepath.Join(dir, "app.log*")},
+			Checkpoint:     checkpoint,
+			PollIntervalMs: 10, FlushIntervalMs: 60000, RetryIntervalMs: 10,
+		})
+		if err != nil {
+			t.Fatalf("failed to start tail loader: %v", err)
+		}
+		return tail
+	}
+	expectRows := func(n int) {
+		t.Helper()
+		if rows := server.Rows("test_db", "users"); len(rows) != n {
+			t.Fatalf("expected %d rows, got %d: %q", n, len(rows), rows)
+		}
+	}
+
+	// The incomplete last line waits for its newline
+	appendLines(path, `{"id": 1}`+"\n", `{"id": 2}`+"\n", `{"id": 3`)
+	tail := start()
+	if err := tail.Flush(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	expectRows(2)
+
+	// Rotation by rename: the rest of the old file and the new file are both loaded
+	appendLines(path, "}\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendLines(path, `{"id": 4}`+"\n")
+	if err := tail.Flush(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	expectRows(4)
+	if err := tail.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restart resumes after the committed offsets
+	appendLines(path, `{"id": 5}`+"\n")
+	tail = start()
+	if err := tail.Flush(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	expectRows(5)
+	tail.Close()
+
+	// A crash after the load but before its commit was recorded replays it under the same
+	// label, which Doris deduplicates
+	data, err := os.ReadFile(checkpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state client.TailCheckpoint
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+	loads := server.Loads()
+	last := loads[len(loads)-1]
+	for i, pos := range state.Files {
+		if pos.Path == path {
+			state.Pending = &client.TailPending{Label: last.Label, Ranges: []client.TailRange{client.NewTailRange(pos, pos.Offset-int64(len(`{"id": 5}`+"\n")))}}
+			state.Files = append(state.Files[:i], state.Files[i+1:]...)
+			break
+		}
+	}
+	if data, err = json.Marshal(state); err != nil || os.WriteFile(checkpoint, data, 0o644) != nil {
+		t.Fatal(err)
+	}
+
+	tail = start()
+	defer tail.Close()
+	if err := tail.Flush(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	expectRows(5)
+	if stats := tail.Stats(); stats.Files != 2 || stats.PendingBytes != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestTailLoaderWaitsForPendingCheckpoint(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithFormat(&config.JSONFormat{Type: config.JSONObjectLine}))
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	checkpoint := filepath.Join(dir, "tail.json")
+	if err := os.WriteFile(path, []byte(`{"id": 1}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A directory in place of the temporary checkpoint file makes saving fail
+	if err := os.Mkdir(checkpoint+".tmp", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tail, err := client.NewTailLoader(c, client.TailOptions{Paths: []string{path}, Checkpoint: checkpoint, PollIntervalMs: 10, RetryIntervalMs: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tail.Close()
+	if err := tail.Flush(200 * time.Millisecond); err == nil {
+		t.Fatal("expected the flush to wait for the checkpoint")
+	}
+	if loads := server.Loads(); len(loads) != 0 {
+		t.Fatalf("a batch must not be loaded before its label is recorded, got %d loads", len(loads))
+	}
+
+	if err := os.Remove(checkpoint + ".tmp"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tail.Flush(5 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 1 {
+		t.Errorf("expected 1 row, got %q", rows)
+	}
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+// GetLoadState returns the state of the load labeled label in the configured database
+// Doris reports "UNKNOWN" for labels it does not know, e.g. after they expired.
+func (c *DorisLoadClient) GetLoadState(label string) (string, error) {
+	return c.GetLoadStateContext(context.Background(), label)
+}
+
+// GetLoadStateContext is GetLoadState with a context
+func (c *DorisLoadClient) GetLoadStateContext(ctx context.Context, label string) (string, error) {
+	cfg := c.config.Load()
+
+	var state string
+	err := c.doWithCredentials(ctx, cfg, func() (*http.Request, error) {
+		return loader.CreateLoadStateRequest(cfg, label)
+	}, func(req *http.Request) error {
+		var err error
+		state, err = c.streamLoader.GetLoadState(req)
+		return err
+	})
+	return state, err
+}
+
+// CommitTransaction commits the precommitted transaction txnID of a two_phase_commit stream load
+func (c *DorisLoadClient) CommitTransaction(txnID int64) error {
+	return c.CommitTransactionContext(context.Background(), txnID)
+}
+
+// CommitTransactionContext is CommitTransaction with a context
+func (c *DorisLoadClient) CommitTransactionContext(ctx context.Context, txnID int64) error {
+	return c.twoPhaseCommit(ctx, txnID, loader.TxnCommit)
+}
+
+// AbortTransaction aborts the precommitted transaction txnID of a two_phase_commit stream load
+func (c *DorisLoadClient) AbortTransaction(txnID int64) error {
+	return c.AbortTransactionContext(context.Background(), txnID)
+}
+
+// AbortTransactionContext is AbortTransaction with a context
+func (c *DorisLoadClient) AbortTransactionContext(ctx context.Context, txnID int64) error {
+	return c.twoPhaseCommit(ctx, txnID, loader.TxnAbort)
+}
+
+// twoPhaseCommit applies operation to transaction txnID in the configured database
+func (c *DorisLoadClient) twoPhaseCommit(ctx context.Context, txnID int64, operation loader.TxnOperation) error {
+	cfg := c.config.Load()
+
+	return c.doWithCredentials(ctx, cfg, func() (*http.Request, error) {
+		return loader.CreateTwoPhaseCommitRequest(cfg, txnID, operation)
+	}, func(req *http.Request) error {
+		msg, err := c.streamLoader.TwoPhaseCommit(req)
+		if err == nil {
+			log.Infof("Transaction %d %s: %s", txnID, operation, msg)
+		}
+		return err
+	})
+}
+
+// doWithCredentials sends the request built by newRequest with send, refreshing the credentials
+// provider and trying once more if Doris rejects them
+func (c *DorisLoadClient) doWithCredentials(ctx context.Context, cfg *config.Config,
+	newRequest func() (*http.Request, error), send func(*http.Request) error) error {
+	for refreshed := false; ; refreshed = true {
+		req, err := newRequest()
+		if err != nil {
+			return err
+		}
+
+		err = send(req.WithContext(ctx))
+		if err == nil || refreshed || cfg.Credentials == nil || !isUnauthorized(err) {
+			return err
+		}
+
+		log.Warnf("Credentials rejected, refreshing them before trying again")
+		if refreshErr := cfg.Credentials.Refresh(); refreshErr != nil {
+			log.Errorf("Failed to refresh credentials: %v", refreshErr)
+			return err
+		}
+	}
+}
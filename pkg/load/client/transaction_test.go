@@ -0,0 +1,50 @@
+package client_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+func TestTwoPhaseCommit(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithOption("two_phase_commit", "true"))
+
+	committed, err := c.Load(strings.NewReader("1,Alice\n"), config.WithLabel("txn_commit"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	aborted, err := c.Load(strings.NewReader("2,Bob\n"), config.WithLabel("txn_abort"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if state, err := c.GetLoadState("txn_commit"); err != nil || state != dorismock.StatePrecommitted {
+		t.Fatalf("expected prepared load, got %s (%v)", state, err)
+	}
+	if err := c.CommitTransaction(committed.Resp.TxnID); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	if err := c.AbortTransaction(aborted.Resp.TxnID); err != nil {
+		t.Fatalf("abort failed: %v", err)
+	}
+	if err := c.CommitTransaction(aborted.Resp.TxnID); err == nil {
+		t.Error("expected committing an aborted transaction to fail")
+	}
+
+	for label, expected := range map[string]string{
+		"txn_commit": dorismock.StateVisible,
+		"txn_abort":  dorismock.StateAborted,
+		"missing":    dorismock.StateUnknown,
+	} {
+		if state, err := c.GetLoadState(label); err != nil || state != expected {
+			t.Errorf("label %s: expected %s, got %s (%v)", label, expected, state, err)
+		}
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 1 || rows[0] != "1,Alice" {
+		t.Errorf("unexpected rows: %q", rows)
+	}
+}
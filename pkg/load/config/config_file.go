@@ -0,0 +1,379 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/deadletter"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// envReferencePattern matches ${NAME} and ${NAME:-default} references in string values
+	envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+)
+
+// fileConfig is the representation of Config in YAML/JSON files and environment variables
+type fileConfig struct {
+	Endpoints          []string          `yaml:"endpoints" json:"endpoints"`
+	User               string            `yaml:"user" json:"user"`
+	Password           string            `yaml:"password" json:"password"`
+	AllowEmptyPassword bool              `yaml:"allow_empty_password" json:"allow_empty_password"`
+	CredentialsFile    string            `yaml:"credentials_file" json:"credentials_file"`
+	Database           string            `yaml:"database" json:"database"`
+	Table              string            `yaml:"table" json:"table"`
+	LabelPrefix        string            `yaml:"label_prefix" json:"label_prefix"`
+	Label              string            `yaml:"label" json:"label"`
+	Format             *fileFormat       `yaml:"format" json:"format"`
+	Retry              *Retry            `yaml:"retry" json:"retry"`
+	GroupCommit        string            `yaml:"group_commit" json:"group_commit"`
+	Options            map[string]string `yaml:"options" json:"options"`
+	ErrorLogRows       int               `yaml:"error_log_rows" json:"error_log_rows"`
+	ValidateSchema     bool              `yaml:"validate_schema" json:"validate_schema"`
+	DeadLetterDir      string            `yaml:"dead_letter_dir" json:"dead_letter_dir"`
+	WorkerPool         *WorkerPool       `yaml:"worker_pool" json:"worker_pool"`
+	RateLimit          *RateLimit        `yaml:"rate_limit" json:"rate_limit"`
+	Backpressure       *Backpressure     `yaml:"backpressure" json:"backpressure"`
+}
+
+// fileFormat is the representation of Format in configuration files
+type fileFormat struct {
+	Type            string `yaml:"type" json:"type"` // csv or json
+	ColumnSeparator string `yaml:"column_separator" json:"column_separator"`
+	LineDelimiter   string `yaml:"line_delimiter" json:"line_delimiter"`
+	Enclose         string `yaml:"enclose" json:"enclose"`
+	Escape          string `yaml:"escape" json:"escape"`
+	JSONType        string `yaml:"json_type" json:"json_type"` // object_line or array
+}
+
+// LoadConfigFile reads a Config from a YAML (.yaml, .yml) or JSON (.json) file
+// String values may reference environment variables as ${NAME} or ${NAME:-default}, which keeps
+// secrets out of the file. Unknown keys are rejected and the result is checked with ValidateInternal.
+// Group commit defaults to off when group_commit is omitted.
+func LoadConfigFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var fc fileConfig
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&fc); err != nil {
+			return nil, fmt.Errorf("config file %s: %w", path, err)
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&fc); err != nil {
+			return nil, fmt.Errorf("config file %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported extension %q, use .yaml, .yml or .json", path, ext)
+	}
+
+	if err := expandEnvReferences(reflect.ValueOf(&fc).Elem(), ""); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	cfg, err := fc.toConfig()
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ConfigFromEnv builds a Config from environment variables named PREFIX_KEY
+// Keys are the configuration file keys in upper case, with "__" separating nested keys:
+//
+//	DORIS_ENDPOINTS=http://fe1:8030,http://fe2:8030
+//	DORIS_FORMAT__TYPE=csv
+//	DORIS_RETRY__MAX_RETRY_TIMES=3
+//	DORIS_OPTIONS__STRICT_MODE=true
+//
+// Variables with the prefix that do not match a known key are rejected.
+func ConfigFromEnv(prefix string) (*Config, error) {
+	prefix = strings.TrimSuffix(prefix, "_") + "_"
+
+	var names []string
+	for _, env := range os.Environ() {
+		name := strings.SplitN(env, "=", 2)[0]
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var fc fileConfig
+	for _, name := range names {
+		path := strings.Split(strings.ToLower(strings.TrimPrefix(name, prefix)), "__")
+		if err := setFieldByPath(reflect.ValueOf(&fc).Elem(), path, os.Getenv(name)); err != nil {
+			return nil, fmt.Errorf("environment variable %s: %w", name, err)
+		}
+	}
+
+	return fc.toConfig()
+}
+
+// toConfig converts the file representation into a validated Config
+func (fc *fileConfig) toConfig() (*Config, error) {
+	cfg := &Config{
+		Endpoints:          fc.Endpoints,
+		User:               fc.User,
+		Password:           fc.Password,
+		AllowEmptyPassword: fc.AllowEmptyPassword,
+		Database:           fc.Database,
+		Table:              fc.Table,
+		LabelPrefix:        fc.LabelPrefix,
+		Label:              fc.Label,
+		Retry:              fc.Retry,
+		Options:            fc.Options,
+		ErrorLogRows:       fc.ErrorLogRows,
+		ValidateSchema:     fc.ValidateSchema,
+		WorkerPool:         fc.WorkerPool,
+		RateLimit:          fc.RateLimit,
+		Backpressure:       fc.Backpressure,
+	}
+
+	if fc.Format != nil {
+		format, err := fc.Format.toFormat()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Format = format
+	}
+
+	switch strings.ToLower(fc.GroupCommit) {
+	case "", "off":
+		cfg.GroupCommit = OFF
+	case "sync", "sync_mode":
+		cfg.GroupCommit = SYNC
+	case "async", "async_mode":
+		cfg.GroupCommit = ASYNC
+	default:
+		return nil, fieldError("group_commit", fmt.Sprintf("unknown mode %q, use off, sync or async", fc.GroupCommit))
+	}
+
+	if fc.CredentialsFile != "" {
+		if fc.User != "" || fc.Password != "" {
+			return nil, fieldError("credentials_file", "cannot be combined with user and password")
+		}
+		cfg.Credentials = NewFileCredentials(fc.CredentialsFile)
+	}
+
+	if err := cfg.ValidateInternal(); err != nil {
+		return nil, err
+	}
+
+	// Only a valid configuration creates the dead-letter directory
+	if fc.DeadLetterDir != "" {
+		sink, err := deadletter.NewDirSink(fc.DeadLetterDir)
+		if err != nil {
+			return nil, fieldError("dead_letter_dir", err.Error())
+		}
+		cfg.DeadLetter = sink
+	}
+	return cfg, nil
+}
+
+// toFormat converts the file representation of a format into a Format
+func (ff *fileFormat) toFormat() (Format, error) {
+	switch strings.ToLower(ff.Type) {
+	case "csv":
+		if ff.JSONType != "" {
+			return nil, fieldError("format.json_type", "only applies to json format")
+		}
+		format := &CSVFormat{ColumnSeparator: ff.ColumnSeparator, LineDelimiter: ff.LineDelimiter, Enclose: ff.Enclose, Escape: ff.Escape}
+		if format.ColumnSeparator == "" {
+			format.ColumnSeparator = ","
+		}
+		if format.LineDelimiter == "" {
+			format.LineDelimiter = "\\n"
+		}
+		return format, nil
+	case "json":
+		if ff.ColumnSeparator != "" || ff.LineDelimiter != "" || ff.Enclose != "" || ff.Escape != "" {
+			return nil, fieldError("format", "column_separator, line_delimiter, enclose and escape only apply to csv format")
+		}
+		switch JSONFormatType(ff.JSONType) {
+		case "", JSONObjectLine:
+			return &JSONFormat{Type: JSONObjectLine}, nil
+		case JSONArray:
+			return &JSONFormat{Type: JSONArray}, nil
+		default:
+			return nil, fieldError("format.json_type", fmt.Sprintf("unknown json type %q, use object_line or array", ff.JSONType))
+		}
+	case "":
+		return nil, fieldError("format.type", "cannot be empty")
+	default:
+		return nil, fieldError("format.type", fmt.Sprintf("unknown format %q, use csv or json", ff.Type))
+	}
+}
+
+// expandEnvReferences replaces ${NAME} references in every string value reachable from v
+func expandEnvReferences(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return expandEnvReferences(v.Elem(), path)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if err := expandEnvReferences(v.Field(i), joinFieldPath(path, yamlKey(v.Type().Field(i)))); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := expandEnvReferences(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		for _, key := range v.MapKeys() {
+			value := v.MapIndex(key)
+			if value.Kind() != reflect.String {
+				continue
+			}
+			expanded, err := expandEnv(value.String(), joinFieldPath(path, key.String()))
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(key, reflect.ValueOf(expanded))
+		}
+	case reflect.String:
+		expanded, err := expandEnv(v.String(), path)
+		if err != nil {
+			return err
+		}
+		v.SetString(expanded)
+	}
+	return nil
+}
+
+// expandEnv replaces ${NAME} and ${NAME:-default} references in s
+func expandEnv(s string, path string) (string, error) {
+	var missing string
+	result := envReferencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		match := envReferencePattern.FindStringSubmatch(ref)
+		if value, ok := os.LookupEnv(match[1]); ok {
+			return value
+		}
+		if match[2] != "" {
+			return match[3]
+		}
+		if missing == "" {
+			missing = match[1]
+		}
+		return ref
+	})
+
+	if missing != "" {
+		return "", fieldError(path, fmt.Sprintf("environment variable %s is not set", missing))
+	}
+	return result, nil
+}
+
+// setFieldByPath sets the field of struct v addressed by a path of yaml keys from a string value
+func setFieldByPath(v reflect.Value, path []string, raw string) error {
+	field, ok := fieldByYAMLKey(v, path[0])
+	if !ok {
+		return fmt.Errorf("unknown key %q", strings.Join(path, "."))
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.Struct:
+		if len(path) < 2 {
+			return fmt.Errorf("key %q is a section, set its nested keys instead", path[0])
+		}
+		return setFieldByPath(field, path[1:], raw)
+	case reflect.Map:
+		if len(path) != 2 {
+			return fmt.Errorf("key %q needs exactly one nested key", path[0])
+		}
+		if field.IsNil() {
+			field.Set(reflect.MakeMap(field.Type()))
+		}
+		field.SetMapIndex(reflect.ValueOf(path[1]), reflect.ValueOf(raw))
+		return nil
+	}
+
+	if len(path) > 1 {
+		return fmt.Errorf("unknown key %q", strings.Join(path, "."))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid integer %q", path[0], raw)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid number %q", path[0], raw)
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s: invalid boolean %q", path[0], raw)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("key %q cannot be set from the environment", path[0])
+	}
+	return nil
+}
+
+// fieldByYAMLKey returns the field of struct v whose yaml tag is key
+func fieldByYAMLKey(v reflect.Value, key string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if yamlKey(v.Type().Field(i)) == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// yamlKey returns the yaml key of a struct field
+func yamlKey(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("yaml"), ",")[0]
+}
+
+// joinFieldPath appends key to a dotted field path
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
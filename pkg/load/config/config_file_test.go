@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleYAMLConfig = `
+endpoints:
+  - http://fe1:8030
+  - http://fe2:8030
+user: root
+password: ${DORIS_TEST_PASSWORD}
+database: test_db
+table: ${DORIS_TEST_TABLE:-users}
+label_prefix: app
+format:
+  type: csv
+  column_separator: "|"
+retry:
+  max_retry_times: 3
+  base_interval_ms: 500
+  max_total_time_ms: 10000
+group_commit: async
+options:
+  timeout: 3600
+`
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoadConfigFileYAML(t *testing.T) {
+	t.Setenv("DORIS_TEST_PASSWORD", "secret")
+
+	cfg, err := LoadConfigFile(writeConfigFile(t, "doris.yaml", sampleYAMLConfig))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(cfg.Endpoints) != 2 || cfg.Password != "secret" || cfg.Table != "users" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if cfg.GroupCommit != ASYNC || cfg.Options["timeout"] != "3600" {
+		t.Errorf("unexpected group commit or options: %v %v", cfg.GroupCommit, cfg.Options)
+	}
+	csv, ok := cfg.Format.(*CSVFormat)
+	if !ok || csv.ColumnSeparator != "|" || csv.LineDelimiter != "\\n" {
+		t.Errorf("unexpected format: %#v", cfg.Format)
+	}
+	if cfg.Retry == nil || cfg.Retry.MaxRetryTimes != 3 {
+		t.Errorf("unexpected retry: %+v", cfg.Retry)
+	}
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	t.Setenv("DORIS_TEST_PASSWORD", "")
+	_, err := LoadConfigFile(writeConfigFile(t, "doris.yaml", sampleYAMLConfig))
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "password" {
+		t.Errorf("expected password field error, got %v", err)
+	}
+
+	_, err = LoadConfigFile(writeConfigFile(t, "doris.yaml", "user: root\nendpoint: http://fe:8030\n"))
+	if err == nil || !strings.Contains(err.Error(), "endpoint") {
+		t.Errorf("expected unknown key error, got %v", err)
+	}
+
+	_, err = LoadConfigFile(writeConfigFile(t, "doris.json", `{"endpoints": ["http://fe:8030"], "user": "root", "password": "p",
+		"database": "db", "table": "t", "format": {"type": "json"}, "retry": {"max_retry_times": -1}}`))
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "retry.max_retry_times" {
+		t.Errorf("expected retry field error, got %v", err)
+	}
+
+	deadLetterDir := filepath.Join(t.TempDir(), "dead-letter")
+	_, err = LoadConfigFile(writeConfigFile(t, "doris.json", `{"endpoints": ["http://fe:8030"], "user": "root",
+		"database": "db", "table": "t", "format": {"type": "json"}, "dead_letter_dir": "`+deadLetterDir+`"}`))
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "password" {
+		t.Errorf("expected password field error, got %v", err)
+	}
+	if _, statErr := os.Stat(deadLetterDir); !os.IsNotExist(statErr) {
+		t.Errorf("invalid config created the dead-letter directory: %v", statErr)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("DORISENV_ENDPOINTS", "http://fe1:8030, http://fe2:8030")
+	t.Setenv("DORISENV_USER", "root")
+	t.Setenv("DORISENV_PASSWORD", "secret")
+	t.Setenv("DORISENV_DATABASE", "db")
+	t.Setenv("DORISENV_TABLE", "t")
+	t.Setenv("DORISENV_FORMAT__TYPE", "json")
+	t.Setenv("DORISENV_RETRY__MAX_RETRY_TIMES", "2")
+	t.Setenv("DORISENV_OPTIONS__STRICT_MODE", "true")
+
+	cfg, err := ConfigFromEnv("DORISENV")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.Endpoints) != 2 || cfg.Endpoints[1] != "http://fe2:8030" {
+		t.Errorf("unexpected endpoints: %v", cfg.Endpoints)
+	}
+	if cfg.Retry.MaxRetryTimes != 2 || cfg.Options["strict_mode"] != "true" || cfg.GroupCommit != OFF {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	t.Setenv("DORISENV_RETRY__MAX_RETRIES", "2")
+	if _, err := ConfigFromEnv("DORISENV"); err == nil || !strings.Contains(err.Error(), "DORISENV_RETRY__MAX_RETRIES") {
+		t.Errorf("expected unknown variable error, got %v", err)
+	}
+}
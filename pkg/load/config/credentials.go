@@ -0,0 +1,190 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Credentials are the user and password sent with a stream load request
+type Credentials struct {
+	User     string
+	Password string
+}
+
+// CredentialsProvider supplies the credentials of each request
+// Credentials is called for every request and should be cheap; Refresh is called once
+// after Doris rejects a request with 401 Unauthorized, before the request is retried.
+type CredentialsProvider interface {
+	Credentials() (Credentials, error)
+	Refresh() error
+}
+
+// staticCredentials always returns the same credentials
+type staticCredentials struct {
+	creds Credentials
+}
+
+// NewStaticCredentials creates a provider returning fixed credentials
+func NewStaticCredentials(user, password string) CredentialsProvider {
+	return &staticCredentials{creds: Credentials{User: user, Password: password}}
+}
+
+// Credentials implements CredentialsProvider
+func (p *staticCredentials) Credentials() (Credentials, error) {
+	return p.creds, nil
+}
+
+// Refresh implements CredentialsProvider; static credentials never change
+func (p *staticCredentials) Refresh() error {
+	return nil
+}
+
+// envCredentials reads the credentials from environment variables on every request
+type envCredentials struct {
+	userVar     string
+	passwordVar string
+}
+
+// NewEnvCredentials creates a provider reading the user and password from the given environment variables
+// The variables are read for every request, so updating them rotates the credentials.
+func NewEnvCredentials(userVar, passwordVar string) CredentialsProvider {
+	return &envCredentials{userVar: userVar, passwordVar: passwordVar}
+}
+
+// Credentials implements CredentialsProvider
+func (p *envCredentials) Credentials() (Credentials, error) {
+	user, ok := os.LookupEnv(p.userVar)
+	if !ok || user == "" {
+		return Credentials{}, fmt.Errorf("environment variable %s is not set", p.userVar)
+	}
+	return Credentials{User: user, Password: os.Getenv(p.passwordVar)}, nil
+}
+
+// Refresh implements CredentialsProvider; the variables are read on every request anyway
+func (p *envCredentials) Refresh() error {
+	return nil
+}
+
+// fileCredentials reads the credentials from a file and reloads them when the file changes
+type fileCredentials struct {
+	path string
+
+	mu      sync.Mutex
+	creds   Credentials
+	modTime time.Time
+	size    int64
+	loaded  bool
+}
+
+// NewFileCredentials creates a provider reading "user:password" from the first line of the file at path
+// The file is checked for changes on every request, so mounted secrets can be rotated in place.
+func NewFileCredentials(path string) CredentialsProvider {
+	return &fileCredentials{path: path}
+}
+
+// Credentials implements CredentialsProvider
+func (p *fileCredentials) Credentials() (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		if p.loaded {
+			// Keep the last credentials while the secret is being replaced
+			return p.creds, nil
+		}
+		return Credentials{}, fmt.Errorf("failed to stat credentials file: %w", err)
+	}
+
+	if !p.loaded || !info.ModTime().Equal(p.modTime) || info.Size() != p.size {
+		if err := p.reload(); err != nil {
+			return Credentials{}, err
+		}
+		p.modTime = info.ModTime()
+		p.size = info.Size()
+	}
+	return p.creds, nil
+}
+
+// Refresh implements CredentialsProvider by re-reading the file
+func (p *fileCredentials) Refresh() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.reload()
+}
+
+// reload reads the credentials file; p.mu must be held
+func (p *fileCredentials) reload() error {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	line := strings.TrimRight(strings.SplitN(string(data), "\n", 2)[0], "\r")
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("credentials file %s: expected \"user:password\" on the first line", p.path)
+	}
+
+	p.creds = Credentials{User: parts[0], Password: parts[1]}
+	p.loaded = true
+	return nil
+}
+
+// callbackCredentials caches the credentials returned by a user function
+type callbackCredentials struct {
+	fetch func() (Credentials, error)
+
+	mu     sync.Mutex
+	creds  Credentials
+	loaded bool
+}
+
+// NewCallbackCredentials creates a provider calling fetch for the first request and on every Refresh
+// Use it to integrate secret managers; the result is cached between refreshes.
+func NewCallbackCredentials(fetch func() (Credentials, error)) CredentialsProvider {
+	return &callbackCredentials{fetch: fetch}
+}
+
+// Credentials implements CredentialsProvider
+func (p *callbackCredentials) Credentials() (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.loaded {
+		if err := p.refreshLocked(); err != nil {
+			return Credentials{}, err
+		}
+	}
+	return p.creds, nil
+}
+
+// Refresh implements CredentialsProvider by calling the user function again
+func (p *callbackCredentials) Refresh() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.refreshLocked()
+}
+
+// refreshLocked calls the user function; p.mu must be held
+func (p *callbackCredentials) refreshLocked() error {
+	creds, err := p.fetch()
+	if err != nil {
+		return fmt.Errorf("failed to fetch credentials: %w", err)
+	}
+	p.creds = creds
+	p.loaded = true
+	return nil
+}
+
+// ResolveCredentials returns the credentials for the next request
+// The Credentials provider is used when set, otherwise User and Password.
+func (c *Config) ResolveCredentials() (Credentials, error) {
+	if c.Credentials != nil {
+		return c.Credentials.Credentials()
+	}
+	return Credentials{User: c.User, Password: c.Password}, nil
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileCredentialsReloadOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "doris.secret")
+	if err := os.WriteFile(path, []byte("root:old\n"), 0o600); err != nil {
+		t.Fatalf("failed to write credentials: %v", err)
+	}
+
+	provider := NewFileCredentials(path)
+	creds, err := provider.Credentials()
+	if err != nil || creds.User != "root" || creds.Password != "old" {
+		t.Fatalf("unexpected credentials %+v, err %v", creds, err)
+	}
+
+	if err := os.WriteFile(path, []byte("root:rotated\n"), 0o600); err != nil {
+		t.Fatalf("failed to write credentials: %v", err)
+	}
+	future := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("failed to touch credentials: %v", err)
+	}
+
+	creds, err = provider.Credentials()
+	if err != nil || creds.Password != "rotated" {
+		t.Fatalf("expected rotated credentials, got %+v, err %v", creds, err)
+	}
+}
+
+func TestValidateAllowEmptyPassword(t *testing.T) {
+	cfg := &Config{
+		Endpoints: []string{"http://fe:8030"},
+		User:      "root",
+		Database:  "db",
+		Table:     "t",
+		Format:    &JSONFormat{Type: JSONObjectLine},
+	}
+	if err := cfg.ValidateInternal(); err == nil {
+		t.Fatal("expected empty password to be rejected")
+	}
+
+	cfg.AllowEmptyPassword = true
+	if err := cfg.ValidateInternal(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg.User = ""
+	cfg.Credentials = NewStaticCredentials("root", "")
+	if err := cfg.ValidateInternal(); err != nil {
+		t.Fatalf("unexpected error with credentials provider: %v", err)
+	}
+}
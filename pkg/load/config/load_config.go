@@ -2,6 +2,12 @@ package config
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/deadletter"
 )
 
 // Format interface defines the data format for stream load
@@ -49,9 +55,15 @@ func (f *JSONFormat) GetOptions() map[string]string {
 
 // CSVFormat represents CSV format configuration
 // Usage: &CSVFormat{ColumnSeparator: ",", LineDelimiter: "\n"}
+// Separators may be given as raw characters ("\n") or escaped the way Doris expects them ("\\n");
+// control characters are escaped when the options are sent as headers.
+// Enclose (usually "\"") lets values contain the separator and line delimiter, Escape (usually "\\")
+// escapes the enclose character inside enclosed values; both require Doris 2.0 or later.
 type CSVFormat struct {
 	ColumnSeparator string
 	LineDelimiter   string
+	Enclose         string
+	Escape          string
 }
 
 // GetFormatType implements Format interface
@@ -63,11 +75,80 @@ func (f *CSVFormat) GetFormatType() string {
 func (f *CSVFormat) GetOptions() map[string]string {
 	options := make(map[string]string)
 	options["format"] = "csv"
-	options["column_separator"] = f.ColumnSeparator
-	options["line_delimiter"] = f.LineDelimiter
+	options["column_separator"] = EscapeDelimiter(f.ColumnSeparator)
+	options["line_delimiter"] = EscapeDelimiter(f.LineDelimiter)
+	if f.Enclose != "" {
+		options["enclose"] = f.Enclose
+	}
+	if f.Escape != "" {
+		options["escape"] = f.Escape
+	}
 	return options
 }
 
+// EscapeDelimiter converts control characters of a separator into the escaped form Doris expects
+// in headers ("\n", "\t", "\x01"), since HTTP header values cannot carry them raw
+func EscapeDelimiter(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch b := s[i]; {
+		case b == '\n':
+			sb.WriteString("\\n")
+		case b == '\t':
+			sb.WriteString("\\t")
+		case b == '\r':
+			sb.WriteString("\\r")
+		case b < 0x20 || b == 0x7f:
+			fmt.Fprintf(&sb, "\\x%02x", b)
+		default:
+			sb.WriteByte(b)
+		}
+	}
+	return sb.String()
+}
+
+// UnescapeDelimiter converts a separator written the way Doris headers expect it
+// ("\\n", "\\t", "\\x01") into the bytes it stands for; other strings are returned unchanged
+func UnescapeDelimiter(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case 'n':
+			sb.WriteByte('\n')
+			i++
+		case 't':
+			sb.WriteByte('\t')
+			i++
+		case 'r':
+			sb.WriteByte('\r')
+			i++
+		case '\\':
+			sb.WriteByte('\\')
+			i++
+		case 'x':
+			if i+3 < len(s) {
+				if b, err := strconv.ParseUint(s[i+2:i+4], 16, 8); err == nil {
+					sb.WriteByte(byte(b))
+					i += 3
+					continue
+				}
+			}
+			sb.WriteByte(s[i])
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
+
 // GroupCommitMode defines the group commit mode
 type GroupCommitMode int
 
@@ -82,9 +163,37 @@ const (
 
 // Retry contains configuration for retry attempts when loading data
 type Retry struct {
-	MaxRetryTimes  int   // Maximum number of retry attempts
-	BaseIntervalMs int64 // Base interval in milliseconds for exponential backoff
-	MaxTotalTimeMs int64 // Maximum total time for all retries in milliseconds
+	MaxRetryTimes  int   `yaml:"max_retry_times" json:"max_retry_times"`     // Maximum number of retry attempts
+	BaseIntervalMs int64 `yaml:"base_interval_ms" json:"base_interval_ms"`   // Base interval in milliseconds for exponential backoff
+	MaxTotalTimeMs int64 `yaml:"max_total_time_ms" json:"max_total_time_ms"` // Maximum total time for all retries in milliseconds
+}
+
+// WorkerPool contains configuration for the worker pool behind LoadAsync
+type WorkerPool struct {
+	Workers   int  `yaml:"workers" json:"workers"`       // Number of concurrent loads (default 4)
+	QueueSize int  `yaml:"queue_size" json:"queue_size"` // Maximum loads waiting for a worker (default 100)
+	FailFast  bool `yaml:"fail_fast" json:"fail_fast"`   // Fail LoadAsync immediately when the queue is full instead of blocking
+}
+
+// RateLimit contains client-side throttling applied before each load request
+// Zero values disable the corresponding limit
+type RateLimit struct {
+	BytesPerSecond     int64   `yaml:"bytes_per_second" json:"bytes_per_second"`         // Maximum payload bytes sent per second
+	LoadsPerSecond     float64 `yaml:"loads_per_second" json:"loads_per_second"`         // Maximum load requests started per second
+	MaxConcurrentLoads int     `yaml:"max_concurrent_loads" json:"max_concurrent_loads"` // Maximum load requests in flight at the same time
+	// SharedByTable shares the limits with every other client in the process loading the same
+	// database.table. The first client created for a table defines the limits, until every client
+	// sharing them is closed.
+	SharedByTable bool `yaml:"shared_by_table" json:"shared_by_table"`
+}
+
+// Backpressure configures adaptive throttling driven by Doris overload signals such as
+// -235 (too many tablet versions) or publish timeouts. Zero values use the defaults.
+type Backpressure struct {
+	MaxConcurrency int   `yaml:"max_concurrency" json:"max_concurrency"`   // Upper bound of the adaptive concurrency window (default 8)
+	MinConcurrency int   `yaml:"min_concurrency" json:"min_concurrency"`   // Lower bound of the adaptive concurrency window (default 1)
+	BaseIntervalMs int64 `yaml:"base_interval_ms" json:"base_interval_ms"` // Delay between load starts added on the first overload signal (default 500)
+	MaxIntervalMs  int64 `yaml:"max_interval_ms" json:"max_interval_ms"`   // Upper bound of the delay between load starts (default 30000)
 }
 
 // Config contains all configuration for stream load operations
@@ -100,45 +209,209 @@ type Config struct {
 	Retry       *Retry
 	GroupCommit GroupCommitMode
 	Options     map[string]string
+
+	// Credentials supplies the user and password of each request instead of User and Password.
+	// nil uses User and Password.
+	Credentials CredentialsProvider
+
+	// AllowEmptyPassword accepts an empty Password, e.g. for a root user without one
+	AllowEmptyPassword bool
+
+	// ErrorLogRows is the number of rejected rows fetched from ErrorURL and attached
+	// to the error of a failed load. 0 disables fetching.
+	ErrorLogRows int
+
+	// ValidateSchema checks the columns option against the table schema, fetched once per table,
+	// before each load is sent
+	ValidateSchema bool
+
+	// DeadLetter receives batches that exhausted retries or had filtered rows. nil disables it.
+	DeadLetter deadletter.Sink
+
+	// WorkerPool configures LoadAsync. nil uses the defaults.
+	WorkerPool *WorkerPool
+
+	// RateLimit throttles loads on the client side. nil disables throttling.
+	RateLimit *RateLimit
+
+	// Backpressure adapts load concurrency and frequency to overload signals. nil disables it.
+	Backpressure *Backpressure
+
+	// Transport sends the HTTP requests of the client instead of the shared SDK transport,
+	// e.g. to inject faults in tests. nil uses the shared transport.
+	Transport http.RoundTripper
+
+	// clientSetting is set by ClientSetting, client-level options then record their setting instead of applying it
+	clientSetting *string
+}
+
+// FieldError is a validation error of a single configuration field
+// Field is the path of the field as written in configuration files, e.g. "retry.max_retry_times"
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error returns the error message prefixed with the field path
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// fieldError creates a FieldError
+func fieldError(field, message string) *FieldError {
+	return &FieldError{Field: field, Message: message}
+}
+
+// ValidationError lists every invalid field of a configuration
+// errors.As can extract the individual *FieldError values.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+// Error returns all field errors separated by semicolons
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the field errors
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// validator collects field errors
+type validator struct {
+	errs []*FieldError
+}
+
+// check records a field error when ok is false
+func (v *validator) check(ok bool, field, message string) {
+	if !ok {
+		v.errs = append(v.errs, fieldError(field, message))
+	}
 }
 
 // ValidateInternal validates the configuration
+// The returned error is a *ValidationError listing every invalid field
 func (c *Config) ValidateInternal() error {
-	if c.User == "" {
-		return fmt.Errorf("user cannot be empty")
+	v := &validator{}
+
+	if c.Credentials == nil {
+		v.check(c.User != "", "user", "cannot be empty")
+		v.check(c.Password != "" || c.AllowEmptyPassword, "password",
+			"cannot be empty, set allow_empty_password to use a user without password")
 	}
 
-	if c.Password == "" {
-		return fmt.Errorf("password cannot be empty")
+	v.check(c.Database != "", "database", "cannot be empty")
+	v.check(c.Table != "", "table", "cannot be empty")
+
+	v.check(len(c.Endpoints) > 0, "endpoints", "cannot be empty")
+	for i, endpoint := range c.Endpoints {
+		field := fmt.Sprintf("endpoints[%d]", i)
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			v.check(false, field, fmt.Sprintf("invalid URL %q: %v", endpoint, err))
+			continue
+		}
+		v.check((u.Scheme == "http" || u.Scheme == "https") && u.Host != "", field,
+			fmt.Sprintf("invalid URL %q, expected http://host:port", endpoint))
 	}
 
-	if c.Database == "" {
-		return fmt.Errorf("database cannot be empty")
+	switch f := c.Format.(type) {
+	case nil:
+		v.check(false, "format", "cannot be nil")
+	case *CSVFormat:
+		v.check(f.ColumnSeparator != "", "format.column_separator", "cannot be empty")
+		v.check(f.LineDelimiter != "", "format.line_delimiter", "cannot be empty")
+		v.check(f.ColumnSeparator == "" || UnescapeDelimiter(f.ColumnSeparator) != UnescapeDelimiter(f.LineDelimiter),
+			"format.column_separator", "cannot be the same as line_delimiter")
+		v.check(len(f.Enclose) <= 1, "format.enclose", "must be a single byte")
+		v.check(len(f.Escape) <= 1, "format.escape", "must be a single byte")
+	case *JSONFormat:
+		v.check(f.Type == JSONObjectLine || f.Type == JSONArray, "format.json_type",
+			fmt.Sprintf("unknown json type %q, use object_line or array", f.Type))
 	}
 
-	if c.Table == "" {
-		return fmt.Errorf("table cannot be empty")
+	v.check(c.GroupCommit == SYNC || c.GroupCommit == ASYNC || c.GroupCommit == OFF, "group_commit",
+		fmt.Sprintf("unknown mode %d", c.GroupCommit))
+	v.check(c.Label == "" || c.GroupCommit == OFF, "label", "cannot be set when group commit is enabled")
+
+	v.check(c.ErrorLogRows >= 0, "error_log_rows", "cannot be negative")
+
+	if c.WorkerPool != nil {
+		v.check(c.WorkerPool.Workers >= 0, "worker_pool.workers", "cannot be negative")
+		v.check(c.WorkerPool.QueueSize >= 0, "worker_pool.queue_size", "cannot be negative")
 	}
 
-	if len(c.Endpoints) == 0 {
-		return fmt.Errorf("endpoints cannot be empty")
+	if c.RateLimit != nil {
+		v.check(c.RateLimit.BytesPerSecond >= 0, "rate_limit.bytes_per_second", "cannot be negative")
+		v.check(c.RateLimit.LoadsPerSecond >= 0, "rate_limit.loads_per_second", "cannot be negative")
+		v.check(c.RateLimit.MaxConcurrentLoads >= 0, "rate_limit.max_concurrent_loads", "cannot be negative")
 	}
 
-	if c.Format == nil {
-		return fmt.Errorf("format cannot be nil")
+	if c.Backpressure != nil {
+		bp := c.Backpressure
+		v.check(bp.MaxConcurrency >= 0, "backpressure.max_concurrency", "cannot be negative")
+		v.check(bp.MinConcurrency >= 0, "backpressure.min_concurrency", "cannot be negative")
+		v.check(bp.MaxConcurrency == 0 || bp.MinConcurrency <= bp.MaxConcurrency, "backpressure.min_concurrency",
+			"cannot exceed max_concurrency")
+		v.check(bp.BaseIntervalMs >= 0, "backpressure.base_interval_ms", "cannot be negative")
+		v.check(bp.MaxIntervalMs >= 0, "backpressure.max_interval_ms", "cannot be negative")
 	}
 
 	if c.Retry != nil {
-		if c.Retry.MaxRetryTimes < 0 {
-			return fmt.Errorf("maxRetryTimes cannot be negative")
-		}
-		if c.Retry.BaseIntervalMs < 0 {
-			return fmt.Errorf("retryIntervalMs cannot be negative")
-		}
-		if c.Retry.MaxTotalTimeMs < 0 {
-			return fmt.Errorf("maxTotalTimeMs cannot be negative")
-		}
+		v.check(c.Retry.MaxRetryTimes >= 0, "retry.max_retry_times", "cannot be negative")
+		v.check(c.Retry.BaseIntervalMs >= 0, "retry.base_interval_ms", "cannot be negative")
+		v.check(c.Retry.MaxTotalTimeMs >= 0, "retry.max_total_time_ms", "cannot be negative")
 	}
 
+	if len(v.errs) > 0 {
+		return &ValidationError{Errors: v.errs}
+	}
 	return nil
 }
+
+// Clone returns a deep copy of the configuration
+// Credentials, DeadLetter and Transport are shared since they are safe for concurrent use; a Format other than
+// *CSVFormat or *JSONFormat is shared as well.
+func (c *Config) Clone() *Config {
+	clone := *c
+	clone.Endpoints = append([]string(nil), c.Endpoints...)
+	if c.Options != nil {
+		clone.Options = mergeOptions(nil, c.Options)
+	}
+
+	switch f := c.Format.(type) {
+	case *CSVFormat:
+		format := *f
+		clone.Format = &format
+	case *JSONFormat:
+		format := *f
+		clone.Format = &format
+	}
+
+	if c.Retry != nil {
+		retry := *c.Retry
+		clone.Retry = &retry
+	}
+	if c.WorkerPool != nil {
+		pool := *c.WorkerPool
+		clone.WorkerPool = &pool
+	}
+	if c.RateLimit != nil {
+		limit := *c.RateLimit
+		clone.RateLimit = &limit
+	}
+	if c.Backpressure != nil {
+		backpressure := *c.Backpressure
+		clone.Backpressure = &backpressure
+	}
+	return &clone
+}
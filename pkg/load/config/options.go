@@ -0,0 +1,232 @@
+package config
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/deadletter"
+)
+
+// Option sets a field of a Config
+type Option func(*Config)
+
+// clientOption marks the setter of a setting of the client, which a single load cannot override
+func clientOption(setting string, set func(*Config)) Option {
+	return func(c *Config) {
+		if c.clientSetting != nil {
+			*c.clientSetting = setting
+			return
+		}
+		set(c)
+	}
+}
+
+// ClientSetting returns the client-level setting opt configures, e.g. "endpoints", or "" for an
+// option that can be applied to a single load
+func ClientSetting(opt Option) string {
+	var setting string
+	opt(&Config{clientSetting: &setting})
+	return setting
+}
+
+// NewConfig creates a validated Config from options
+// Defaults: CSV format with "," and "\n", group commit off and the default retry policy
+// (6 retries, 1 second base interval, 60 seconds total). All validation errors are reported
+// together in a *ValidationError.
+func NewConfig(opts ...Option) (*Config, error) {
+	cfg := &Config{
+		Format:      &CSVFormat{ColumnSeparator: ",", LineDelimiter: "\\n"},
+		Retry:       &Retry{MaxRetryTimes: 6, BaseIntervalMs: 1000, MaxTotalTimeMs: 60000},
+		GroupCommit: OFF,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if err := cfg.ValidateInternal(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// WithEndpoints sets the FE HTTP endpoints, e.g. "http://fe1:8030"
+func WithEndpoints(endpoints ...string) Option {
+	return clientOption("endpoints", func(c *Config) {
+		c.Endpoints = append([]string(nil), endpoints...)
+	})
+}
+
+// WithAuth sets the user and password
+func WithAuth(user, password string) Option {
+	return clientOption("credentials", func(c *Config) {
+		c.User = user
+		c.Password = password
+	})
+}
+
+// WithCredentials sets a provider supplying the credentials of each request
+func WithCredentials(provider CredentialsProvider) Option {
+	return clientOption("credentials", func(c *Config) {
+		c.Credentials = provider
+	})
+}
+
+// WithAllowEmptyPassword accepts a user without password
+func WithAllowEmptyPassword() Option {
+	return clientOption("credentials", func(c *Config) {
+		c.AllowEmptyPassword = true
+	})
+}
+
+// WithDatabase sets the target database
+func WithDatabase(database string) Option {
+	return func(c *Config) {
+		c.Database = database
+	}
+}
+
+// WithTable sets the target table
+func WithTable(table string) Option {
+	return func(c *Config) {
+		c.Table = table
+	}
+}
+
+// WithCSV uses CSV format with the given separators, raw ("\t") or escaped ("\\x01")
+func WithCSV(columnSeparator, lineDelimiter string) Option {
+	return func(c *Config) {
+		c.Format = &CSVFormat{ColumnSeparator: columnSeparator, LineDelimiter: lineDelimiter}
+	}
+}
+
+// WithJSON uses JSON format of the given type
+func WithJSON(jsonType JSONFormatType) Option {
+	return func(c *Config) {
+		c.Format = &JSONFormat{Type: jsonType}
+	}
+}
+
+// WithFormat sets the data format
+func WithFormat(format Format) Option {
+	return func(c *Config) {
+		c.Format = format
+	}
+}
+
+// WithLabel sets a fixed label; it cannot be combined with group commit
+func WithLabel(label string) Option {
+	return func(c *Config) {
+		c.Label = label
+	}
+}
+
+// WithLabelPrefix sets the prefix of generated labels
+func WithLabelPrefix(prefix string) Option {
+	return func(c *Config) {
+		c.LabelPrefix = prefix
+	}
+}
+
+// WithGroupCommit sets the group commit mode
+func WithGroupCommit(mode GroupCommitMode) Option {
+	return func(c *Config) {
+		c.GroupCommit = mode
+	}
+}
+
+// WithRetry sets the retry policy; nil uses the built-in default
+func WithRetry(retry *Retry) Option {
+	return func(c *Config) {
+		c.Retry = retry
+	}
+}
+
+// WithOption sets one stream load option, e.g. WithOption("strict_mode", "true")
+func WithOption(key, value string) Option {
+	return func(c *Config) {
+		c.Options = mergeOptions(c.Options, map[string]string{key: value})
+	}
+}
+
+// WithOptions merges stream load options over the ones already set
+func WithOptions(options map[string]string) Option {
+	return func(c *Config) {
+		c.Options = mergeOptions(c.Options, options)
+	}
+}
+
+// WithHeaders merges raw stream load headers over the configured options, e.g. {"timeout": "3600"}
+func WithHeaders(headers map[string]string) Option {
+	return WithOptions(headers)
+}
+
+// WithPartitions restricts the load to the given partitions
+func WithPartitions(partitions ...string) Option {
+	return WithOption("partitions", strings.Join(partitions, ","))
+}
+
+// WithColumns sets the column mapping of the load, e.g. WithColumns("id", "name", "age=age+1")
+func WithColumns(columns ...string) Option {
+	return WithOption("columns", strings.Join(columns, ","))
+}
+
+// WithErrorLogRows attaches up to rows rejected rows to the error of a failed load
+func WithErrorLogRows(rows int) Option {
+	return func(c *Config) {
+		c.ErrorLogRows = rows
+	}
+}
+
+// WithSchemaValidation checks the columns option against the table schema before each load
+func WithSchemaValidation() Option {
+	return func(c *Config) {
+		c.ValidateSchema = true
+	}
+}
+
+// WithDeadLetter sets the sink receiving failed and filtered batches
+func WithDeadLetter(sink deadletter.Sink) Option {
+	return func(c *Config) {
+		c.DeadLetter = sink
+	}
+}
+
+// WithWorkerPool configures LoadAsync
+func WithWorkerPool(pool *WorkerPool) Option {
+	return clientOption("worker pool", func(c *Config) {
+		c.WorkerPool = pool
+	})
+}
+
+// WithRateLimit enables client-side throttling
+func WithRateLimit(limit *RateLimit) Option {
+	return clientOption("rate limit", func(c *Config) {
+		c.RateLimit = limit
+	})
+}
+
+// WithBackpressure enables adaptive throttling on Doris overload signals
+func WithBackpressure(backpressure *Backpressure) Option {
+	return clientOption("backpressure", func(c *Config) {
+		c.Backpressure = backpressure
+	})
+}
+
+// WithTransport sends the client's HTTP requests through transport
+func WithTransport(transport http.RoundTripper) Option {
+	return clientOption("transport", func(c *Config) {
+		c.Transport = transport
+	})
+}
+
+// mergeOptions returns a new map with overrides applied over base
+func mergeOptions(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewConfigDefaults(t *testing.T) {
+	cfg, err := NewConfig(
+		WithEndpoints("http://fe:8030"),
+		WithAuth("root", "secret"),
+		WithDatabase("db"),
+		WithTable("t"),
+		WithOption("strict_mode", "true"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GroupCommit != OFF || cfg.Retry == nil || cfg.Options["strict_mode"] != "true" {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+	if _, ok := cfg.Format.(*CSVFormat); !ok {
+		t.Errorf("expected CSV format, got %#v", cfg.Format)
+	}
+}
+
+func TestNewConfigReportsAllErrors(t *testing.T) {
+	_, err := NewConfig(
+		WithEndpoints("fe:8030"),
+		WithAuth("root", ""),
+		WithCSV("\n", "\\n"),
+		WithLabel("batch_1"),
+		WithGroupCommit(ASYNC),
+	)
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	fields := make(map[string]bool)
+	for _, fieldErr := range validationErr.Errors {
+		fields[fieldErr.Field] = true
+	}
+	for _, field := range []string{"password", "database", "table", "endpoints[0]", "format.column_separator", "label"} {
+		if !fields[field] {
+			t.Errorf("expected an error for %s, got %v", field, err)
+		}
+	}
+}
+
+func TestCSVFormatEscapesDelimiters(t *testing.T) {
+	options := (&CSVFormat{ColumnSeparator: "\t", LineDelimiter: "\n"}).GetOptions()
+	if options["column_separator"] != "\\t" || options["line_delimiter"] != "\\n" {
+		t.Errorf("unexpected options: %v", options)
+	}
+
+	options = (&CSVFormat{ColumnSeparator: "\\x01", LineDelimiter: "\\n"}).GetOptions()
+	if options["column_separator"] != "\\x01" || options["line_delimiter"] != "\\n" {
+		t.Errorf("escaped separators should be kept: %v", options)
+	}
+}
+
+func TestCloneIsDeep(t *testing.T) {
+	cfg, err := NewConfig(
+		WithEndpoints("http://fe:8030"),
+		WithAuth("root", "secret"),
+		WithDatabase("db"),
+		WithTable("t"),
+		WithOption("timeout", "60"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	clone := cfg.Clone()
+	cfg.Endpoints[0] = "http://other:8030"
+	cfg.Options["timeout"] = "3600"
+	cfg.Retry.MaxRetryTimes = 0
+	cfg.Format.(*CSVFormat).ColumnSeparator = "|"
+
+	if clone.Endpoints[0] != "http://fe:8030" || clone.Options["timeout"] != "60" {
+		t.Errorf("clone shares endpoints or options: %+v", clone)
+	}
+	if clone.Retry.MaxRetryTimes != 6 || clone.Format.(*CSVFormat).ColumnSeparator != "," {
+		t.Errorf("clone shares retry or format: %+v %+v", clone.Retry, clone.Format)
+	}
+}
+
+func TestClientSetting(t *testing.T) {
+	for setting, opt := range map[string]Option{
+		"endpoints":    WithEndpoints(),
+		"credentials":  WithAuth("", ""),
+		"worker pool":  WithWorkerPool(nil),
+		"rate limit":   WithRateLimit(nil),
+		"backpressure": WithBackpressure(nil),
+		"":             WithLabel("l"),
+	} {
+		if got := ClientSetting(opt); got != setting {
+			t.Errorf("expected setting %q, got %q", setting, got)
+		}
+	}
+
+	// Applied to a config, client options set their field as usual
+	cfg := &Config{}
+	WithEndpoints("http://fe:8030")(cfg)
+	if len(cfg.Endpoints) != 1 {
+		t.Errorf("unexpected endpoints: %v", cfg.Endpoints)
+	}
+}
@@ -0,0 +1,147 @@
+package ddl
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/encoder"
+)
+
+type audit struct {
+	Source string `json:"source"`
+}
+
+type event struct {
+	ID       int64           `json:"id" doris:",key"`
+	Day      encoder.Date    `json:"day" doris:",key"`
+	Kind     string          `json:"kind" doris:",key"`
+	Amount   float64         `doris:"amount,type=DECIMAL(18, 2),comment=Total, taxes included"`
+	Tags     []string        `json:"tags"`
+	Attrs    map[string]int  `json:"attrs"`
+	Payload  json.RawMessage `json:"payload"`
+	Seen     *time.Time      `json:"seen"`
+	Note     sql.NullString  `json:"note"`
+	Count    int32           `json:"count" doris:",notnull"`
+	internal string
+	Skipped  string `json:"-"`
+	audit
+}
+
+func TestCreateSQLFromStruct(t *testing.T) {
+	table, err := FromStruct([]event{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	table.Name = "events"
+	table.Keys = Unique
+	table.Partition = &Partition{Column: "day", Interval: "Day"}
+	table.Distribution = Distribution{Columns: []string{"id"}, Buckets: 8}
+	table.Properties = map[string]string{"replication_num": "1", "enable_unique_key_merge_on_write": "true"}
+
+	stmt, err := table.CreateSQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "CREATE TABLE IF NOT EXISTS `events` (\n" +
+		"  `id` BIGINT NOT NULL,\n" +
+		"  `day` DATE NOT NULL,\n" +
+		"  `kind` VARCHAR(255) NOT NULL,\n" +
+		"  `amount` DECIMAL(18, 2) NULL COMMENT 'Total, taxes included',\n" +
+		"  `tags` ARRAY<STRING> NULL,\n" +
+		"  `attrs` MAP<STRING, BIGINT> NULL,\n" +
+		"  `payload` JSON NULL,\n" +
+		"  `seen` DATETIME(6) NULL,\n" +
+		"  `note` STRING NULL,\n" +
+		"  `count` INT NOT NULL,\n" +
+		"  `source` STRING NULL\n" +
+		")\n" +
+		"UNIQUE KEY(`id`, `day`, `kind`)\n" +
+		"AUTO PARTITION BY RANGE (date_trunc(`day`, 'day')) ()\n" +
+		"DISTRIBUTED BY HASH(`id`) BUCKETS 8\n" +
+		"PROPERTIES (\n" +
+		"  \"enable_unique_key_merge_on_write\" = \"true\",\n" +
+		"  \"replication_num\" = \"1\"\n" +
+		")"
+	if stmt != expected {
+		t.Errorf("unexpected statement:\n%s\nexpected:\n%s", stmt, expected)
+	}
+}
+
+func TestAggregateTable(t *testing.T) {
+	table := &Table{Database: "db", Name: "pv", Columns: []Column{
+		{Name: "hits", Type: "BIGINT", Aggregation: "sum"},
+		{Name: "page", Type: "STRING"},
+		{Name: "last", Type: "DATETIME"},
+	}}
+	if err := table.SetKeys(Aggregate, "page"); err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := table.CreateSQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "CREATE TABLE IF NOT EXISTS `db`.`pv` (\n" +
+		"  `page` VARCHAR(255) NOT NULL,\n" +
+		"  `hits` BIGINT SUM NOT NULL,\n" +
+		"  `last` DATETIME REPLACE NOT NULL\n" +
+		")\n" +
+		"AGGREGATE KEY(`page`)\n" +
+		"DISTRIBUTED BY HASH(`page`) BUCKETS AUTO"
+	if stmt != expected {
+		t.Errorf("unexpected statement:\n%s", stmt)
+	}
+
+	stmt, err = table.AddColumnsSQL(Column{Name: "bytes", Type: "BIGINT", Aggregation: "MAX"})
+	if err != nil || stmt != "ALTER TABLE `db`.`pv` ADD COLUMN (`bytes` BIGINT MAX NULL)" {
+		t.Errorf("unexpected statement %q: %v", stmt, err)
+	}
+}
+
+func TestValidateRejectsInvalidDefinitions(t *testing.T) {
+	columns := func() []Column {
+		return []Column{{Name: "id", Type: "INT"}, {Name: "doc", Type: "JSON"}, {Name: "at", Type: "DATETIME", Nullable: true}}
+	}
+	cases := map[string]func(*Table){
+		"needs key columns":      func(t *Table) { t.Keys = Unique },
+		"cannot be JSON":         func(t *Table) { t.Columns[1].Key = true },
+		"is not AGGREGATE":       func(t *Table) { t.Columns[1].Aggregation = "SUM" },
+		"must be a key":          func(t *Table) { t.SetKeys(Unique, "id"); t.Distribution.Columns = []string{"doc"} },
+		"must be a NOT NULL key": func(t *Table) { t.Partition = &Partition{Column: "at", Interval: "day"} },
+		"duplicate column":       func(t *Table) { t.Columns = append(t.Columns, Column{Name: "ID", Type: "INT"}) },
+	}
+	for message, change := range cases {
+		table := &Table{Name: "t", Columns: columns()}
+		change(table)
+		if _, err := table.CreateSQL(); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("expected %q, got %v", message, err)
+		}
+	}
+}
+
+func TestFromJSON(t *testing.T) {
+	samples := `{"id": 1, "name": "a", "at": "2024-03-01 10:00:00.5", "day": "2024-03-01", "price": 1, "doc": {"k": 1}}
+{"id": 99999999999999999999, "name": null, "at": "2024-03-01T10:00:00Z", "day": "2024-03-02", "price": 1.5, "flag": true, "mixed": 1}
+{"id": 3, "extra": null, "mixed": "x"}
+{"id": "ignored"}`
+
+	table, err := FromJSON(strings.NewReader(samples), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, column := range table.Columns {
+		got = append(got, column.Name+" "+column.Type)
+	}
+	expected := "id LARGEINT, name STRING, at DATETIME(6), day DATE, price DOUBLE, doc JSON, flag BOOLEAN, mixed STRING, extra STRING"
+	if strings.Join(got, ", ") != expected {
+		t.Errorf("unexpected columns: %s", strings.Join(got, ", "))
+	}
+
+	table, err = FromJSON(strings.NewReader(` [{"b": [1, 2]}, {"a": false}]`), 0)
+	if err != nil || len(table.Columns) != 2 || table.Columns[0].Type != "JSON" || table.Columns[1].Name != "a" {
+		t.Errorf("unexpected table from a JSON array: %+v, %v", table, err)
+	}
+}
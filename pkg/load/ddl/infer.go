@@ -0,0 +1,196 @@
+package ddl
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/encoder"
+)
+
+// Layouts of the strings inferred as DATETIME
+var dateTimeLayouts = []string{encoder.DateTimeLayout, time.RFC3339Nano}
+
+// columnSample collects the kinds of values seen for a column
+type columnSample struct {
+	name                                  string
+	ints, bigInts, floats, bools, objects bool
+	dates, dateTimes, texts               bool
+}
+
+// FromJSON infers the columns of a table from JSON samples, read as a JSON array or as one object per line
+// Up to maxRows objects are read, all of them when maxRows <= 0. Columns are ordered as they first
+// appear and are nullable since samples cannot prove otherwise. Integers become BIGINT (LARGEINT when
+// larger), other numbers DOUBLE, booleans BOOLEAN, strings DATE or DATETIME(6) when every sample
+// parses as one and STRING otherwise, objects and arrays JSON. Columns mixing kinds become STRING,
+// or JSON when they contain objects or arrays. Set Name and Keys of the returned table before use.
+func FromJSON(r io.Reader, maxRows int) (*Table, error) {
+	reader := bufio.NewReader(r)
+	decoder := json.NewDecoder(reader)
+	decoder.UseNumber()
+
+	array, err := startsWithArray(reader)
+	if err != nil {
+		return nil, err
+	}
+	if array {
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	var samples []*columnSample
+	byName := make(map[string]*columnSample)
+	rows := 0
+	for maxRows <= 0 || rows < maxRows {
+		if array && !decoder.More() {
+			break
+		}
+		names, values, err := readObject(decoder)
+		if err != nil {
+			if errors.Is(err, io.EOF) && !array {
+				break
+			}
+			return nil, fmt.Errorf("failed to read JSON sample %d: %w", rows+1, err)
+		}
+		rows++
+
+		for i, name := range names {
+			value := values[i]
+			key := strings.ToLower(name)
+			sample, ok := byName[key]
+			if !ok {
+				sample = &columnSample{name: name}
+				byName[key] = sample
+				samples = append(samples, sample)
+			}
+			sample.add(value)
+		}
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no columns found in %d JSON samples", rows)
+	}
+
+	table := &Table{}
+	for _, sample := range samples {
+		table.Columns = append(table.Columns, Column{Name: sample.name, Type: sample.dorisType(), Nullable: true})
+	}
+	return table, nil
+}
+
+// readObject reads the next JSON object, keeping the order of its keys
+func readObject(decoder *json.Decoder) ([]string, []any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, nil, fmt.Errorf("expected an object, got %v", token)
+	}
+
+	var names []string
+	var values []any
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		names = append(names, token.(string))
+		values = append(values, value)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+	return names, values, nil
+}
+
+// startsWithArray reports whether the first non-space byte of reader opens a JSON array
+func startsWithArray(reader *bufio.Reader) (bool, error) {
+	for {
+		b, err := reader.Peek(1)
+		if errors.Is(err, io.EOF) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.ReadByte()
+		default:
+			return b[0] == '[', nil
+		}
+	}
+}
+
+// add records the kind of value
+func (s *columnSample) add(value any) {
+	switch v := value.(type) {
+	case nil:
+	case bool:
+		s.bools = true
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			s.ints = true
+		} else if !strings.ContainsAny(string(v), ".eE") {
+			s.bigInts = true
+		} else {
+			s.floats = true
+		}
+	case string:
+		switch {
+		case isLayout(v, encoder.DateLayout):
+			s.dates = true
+		case isLayout(v, dateTimeLayouts...):
+			s.dateTimes = true
+		default:
+			s.texts = true
+		}
+	default:
+		s.objects = true
+	}
+}
+
+// dorisType returns the narrowest type holding every sampled value
+func (s *columnSample) dorisType() string {
+	numbers := s.ints || s.bigInts || s.floats
+	strs := s.dates || s.dateTimes || s.texts
+	switch {
+	case s.objects:
+		return "JSON"
+	case strs && (numbers || s.bools) || s.texts || numbers && s.bools:
+		return "STRING"
+	case s.dateTimes:
+		return "DATETIME(6)"
+	case s.dates:
+		return "DATE"
+	case s.bools:
+		return "BOOLEAN"
+	case s.floats:
+		return "DOUBLE"
+	case s.bigInts:
+		return "LARGEINT"
+	case s.ints:
+		return "BIGINT"
+	}
+	// Only NULL was seen
+	return "STRING"
+}
+
+// isLayout reports whether s parses with one of layouts
+func isLayout(s string, layouts ...string) bool {
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, s); err == nil {
+			return true
+		}
+	}
+	return false
+}
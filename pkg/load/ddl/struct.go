@@ -0,0 +1,230 @@
+package ddl
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/encoder"
+)
+
+// keyStringType is the type of string keys, Doris does not accept STRING in keys
+const keyStringType = "VARCHAR(255)"
+
+// Doris types of the Go types the encoder writes specially, and whether they are nullable
+var knownTypes = map[reflect.Type]struct {
+	doris    string
+	nullable bool
+}{
+	reflect.TypeOf(time.Time{}):       {"DATETIME(6)", false},
+	reflect.TypeOf(encoder.Date{}):    {"DATE", false},
+	reflect.TypeOf(json.RawMessage{}): {"JSON", false},
+	reflect.TypeOf([]byte{}):          {"STRING", false},
+	reflect.TypeOf(big.Int{}):         {"LARGEINT", false},
+	reflect.TypeOf(big.Rat{}):         {"DECIMAL(38, 9)", false},
+	reflect.TypeOf(big.Float{}):       {"DECIMAL(38, 9)", false},
+	reflect.TypeOf(sql.NullString{}):  {"STRING", true},
+	reflect.TypeOf(sql.NullInt64{}):   {"BIGINT", true},
+	reflect.TypeOf(sql.NullInt32{}):   {"INT", true},
+	reflect.TypeOf(sql.NullInt16{}):   {"SMALLINT", true},
+	reflect.TypeOf(sql.NullByte{}):    {"SMALLINT", true},
+	reflect.TypeOf(sql.NullFloat64{}): {"DOUBLE", true},
+	reflect.TypeOf(sql.NullBool{}):    {"BOOLEAN", true},
+	reflect.TypeOf(sql.NullTime{}):    {"DATETIME(6)", true},
+}
+
+// Doris types of basic kinds
+var kindTypes = map[reflect.Kind]string{
+	reflect.Bool:    "BOOLEAN",
+	reflect.Int8:    "TINYINT",
+	reflect.Int16:   "SMALLINT",
+	reflect.Int32:   "INT",
+	reflect.Int:     "BIGINT",
+	reflect.Int64:   "BIGINT",
+	reflect.Uint8:   "SMALLINT",
+	reflect.Uint16:  "INT",
+	reflect.Uint32:  "BIGINT",
+	reflect.Uint:    "LARGEINT",
+	reflect.Uint64:  "LARGEINT",
+	reflect.Float32: "FLOAT",
+	reflect.Float64: "DOUBLE",
+	reflect.String:  "STRING",
+}
+
+// FromStruct derives the columns of a table from the fields of a struct, a pointer to one or a slice of them
+// Columns are named like encoding/json names the fields, so JSON loads of the struct match the table.
+// The doris tag overrides the name and sets options, e.g. `doris:"id,key"` or
+// `doris:",type=DECIMAL(18, 2),agg=SUM,comment=Order total"`:
+//
+//	key       part of the table key, NOT NULL
+//	nullable  NULL, the default of value columns and pointers
+//	notnull   NOT NULL
+//	type=T    Doris type instead of the one derived from the Go type
+//	agg=A     aggregation of the value column in AGGREGATE tables
+//	comment=C column comment, last since it may contain commas
+//
+// A tag of "-" skips the field. Go types map to the Doris types the encoder package writes them for:
+// int64 to BIGINT, string to STRING (VARCHAR(255) in keys), time.Time to DATETIME(6), encoder.Date to DATE,
+// slices to ARRAY, maps to MAP and structs to JSON. Set Name and Keys of the returned table before use.
+func FromStruct(v any) (*Table, error) {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, got %T", v)
+	}
+
+	table := &Table{}
+	if err := addStructColumns(table, t); err != nil {
+		return nil, err
+	}
+	if len(table.Columns) == 0 {
+		return nil, fmt.Errorf("struct %s has no exported fields", t)
+	}
+	return table, nil
+}
+
+// addStructColumns appends the columns of the fields of struct type t
+func addStructColumns(table *Table, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, hasTag := field.Tag.Lookup("doris")
+		jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "-" || jsonName == "-" && !hasTag {
+			continue
+		}
+
+		if field.Anonymous && !hasTag && jsonName == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := addStructColumns(table, embedded); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		column, err := structColumn(field, tag, jsonName)
+		if err != nil {
+			return err
+		}
+		if _, exists := table.Column(column.Name); exists {
+			return fmt.Errorf("duplicate column %q from field %s", column.Name, field.Name)
+		}
+		table.Columns = append(table.Columns, column)
+	}
+	return nil
+}
+
+// structColumn returns the column of a struct field
+func structColumn(field reflect.StructField, tag, jsonName string) (Column, error) {
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = jsonName
+	}
+	if name == "" {
+		name = field.Name
+	}
+
+	dorisType, nullable := goType(field.Type)
+	column := Column{Name: name, Type: dorisType, Nullable: true}
+	explicitNull := false
+	for options != "" {
+		var option string
+		option, options = nextOption(options)
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch key {
+		case "key":
+			column.Key = true
+		case "nullable":
+			column.Nullable, explicitNull = true, true
+		case "notnull":
+			column.Nullable, explicitNull = false, true
+		case "type":
+			column.Type = value
+		case "agg":
+			column.Aggregation = value
+		case "comment":
+			column.Comment = value
+		case "":
+		default:
+			return Column{}, fmt.Errorf("unknown doris tag option %q on field %s", key, field.Name)
+		}
+	}
+
+	if column.Type == "" {
+		return Column{}, fmt.Errorf("no Doris type for field %s of type %s, set type= in its doris tag", field.Name, field.Type)
+	}
+	if column.Key && !explicitNull {
+		column.Nullable = nullable
+	}
+	if column.Key && column.Type == "STRING" {
+		column.Type = keyStringType
+	}
+	return column, nil
+}
+
+// nextOption splits the first option off a doris tag, at a comma outside brackets
+// A comment takes the rest of the tag.
+func nextOption(options string) (string, string) {
+	if strings.HasPrefix(strings.TrimSpace(options), "comment=") {
+		return options, ""
+	}
+	depth := 0
+	for i := 0; i < len(options); i++ {
+		switch options[i] {
+		case '(', '<':
+			depth++
+		case ')', '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				return options[:i], options[i+1:]
+			}
+		}
+	}
+	return options, ""
+}
+
+// goType returns the Doris type of Go type t and whether t can hold NULL, "" if there is none
+func goType(t reflect.Type) (string, bool) {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+	if known, ok := knownTypes[t]; ok {
+		return known.doris, nullable || known.nullable
+	}
+	if dorisType, ok := kindTypes[t.Kind()]; ok {
+		return dorisType, nullable
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if element, _ := goType(t.Elem()); element != "" && element != "JSON" {
+			return "ARRAY<" + element + ">", true
+		}
+		return "JSON", true
+	case reflect.Map:
+		key, _ := goType(t.Key())
+		value, _ := goType(t.Elem())
+		if key != "" && value != "" && value != "JSON" {
+			return "MAP<" + key + ", " + value + ">", true
+		}
+		return "JSON", true
+	case reflect.Struct, reflect.Interface:
+		return "JSON", true
+	}
+	return "", false
+}
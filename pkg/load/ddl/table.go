@@ -0,0 +1,351 @@
+// Package ddl generates the Doris CREATE TABLE and ALTER TABLE statements of table definitions,
+// written by hand, derived from a tagged Go struct with FromStruct or inferred from JSON samples with FromJSON
+package ddl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KeysType is the data model of a table
+type KeysType string
+
+const (
+	Duplicate KeysType = "DUPLICATE"
+	Unique    KeysType = "UNIQUE"
+	Aggregate KeysType = "AGGREGATE"
+)
+
+// defaultAggregation is the aggregation of AGGREGATE value columns that do not set one
+const defaultAggregation = "REPLACE"
+
+// Types Doris does not accept in keys, matched as prefixes of Column.Type
+var nonKeyTypes = []string{"STRING", "TEXT", "JSON", "VARIANT", "ARRAY", "MAP", "STRUCT", "FLOAT", "DOUBLE", "HLL", "BITMAP", "QUANTILE_STATE", "AGG_STATE"}
+
+// Intervals accepted by Partition
+var partitionIntervals = map[string]bool{"hour": true, "day": true, "week": true, "month": true, "quarter": true, "year": true}
+
+// Column defines a column of a table
+type Column struct {
+	Name        string
+	Type        string // Doris type, e.g. "BIGINT", "VARCHAR(64)" or "ARRAY<INT>"
+	Key         bool
+	Nullable    bool
+	Aggregation string // Aggregation of value columns of AGGREGATE tables, e.g. "SUM" (default REPLACE)
+	Comment     string
+}
+
+// Distribution defines how rows are spread over buckets
+type Distribution struct {
+	Columns []string // HASH columns; empty distributes DUPLICATE tables randomly and the others by their keys
+	Buckets int      // Number of buckets, 0 lets Doris choose (BUCKETS AUTO)
+}
+
+// Partition defines automatic range partitions created as rows arrive
+type Partition struct {
+	Column   string // DATE or DATETIME key column, NOT NULL
+	Interval string // "hour", "day", "week", "month", "quarter" or "year"
+}
+
+// Table defines a Doris table
+type Table struct {
+	Database     string // Empty for the database the statements run in
+	Name         string
+	Keys         KeysType // Data model, DUPLICATE when empty
+	Columns      []Column
+	Distribution Distribution
+	Partition    *Partition        // nil for an unpartitioned table
+	Properties   map[string]string // e.g. "replication_num": "1"
+	Comment      string
+}
+
+// Column returns the column named name, compared case-insensitively like Doris does
+func (t *Table) Column(name string) (*Column, bool) {
+	for i := range t.Columns {
+		if strings.EqualFold(t.Columns[i].Name, name) {
+			return &t.Columns[i], true
+		}
+	}
+	return nil, false
+}
+
+// SetKeys sets the data model and makes columns, in that order, the keys of the table
+// Key columns become NOT NULL and STRING keys VARCHAR(255), which Doris accepts in keys.
+func (t *Table) SetKeys(keys KeysType, columns ...string) error {
+	for i := range t.Columns {
+		t.Columns[i].Key = false
+	}
+
+	ordered := make([]Column, 0, len(t.Columns))
+	for _, name := range columns {
+		column, ok := t.Column(name)
+		if !ok {
+			return fmt.Errorf("unknown key column %q", name)
+		}
+		if column.Key {
+			return fmt.Errorf("duplicate key column %q", name)
+		}
+		column.Key = true
+		column.Nullable = false
+		if strings.EqualFold(column.Type, "STRING") {
+			column.Type = "VARCHAR(255)"
+		}
+		ordered = append(ordered, *column)
+	}
+	for _, column := range t.Columns {
+		if !column.Key {
+			ordered = append(ordered, column)
+		}
+	}
+
+	t.Keys = keys
+	t.Columns = ordered
+	return nil
+}
+
+// Validate checks the definition against the rules Doris applies to CREATE TABLE
+func (t *Table) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("table name is required")
+	}
+	if len(t.Columns) == 0 {
+		return fmt.Errorf("table %s has no columns", t.Name)
+	}
+
+	keysType := t.keysType()
+	switch keysType {
+	case Duplicate, Unique, Aggregate:
+	default:
+		return fmt.Errorf("unknown keys type %q", t.Keys)
+	}
+
+	seen := make(map[string]bool)
+	keys := 0
+	for _, column := range t.Columns {
+		name := strings.ToLower(column.Name)
+		switch {
+		case column.Name == "":
+			return fmt.Errorf("column name is required")
+		case seen[name]:
+			return fmt.Errorf("duplicate column %q", column.Name)
+		case column.Type == "":
+			return fmt.Errorf("column %q has no type", column.Name)
+		case column.Key && column.Aggregation != "":
+			return fmt.Errorf("key column %q cannot have an aggregation", column.Name)
+		case column.Aggregation != "" && keysType != Aggregate:
+			return fmt.Errorf("column %q has an aggregation but the table is not AGGREGATE", column.Name)
+		}
+		seen[name] = true
+
+		if column.Key {
+			keys++
+			for _, prefix := range nonKeyTypes {
+				if strings.HasPrefix(strings.ToUpper(column.Type), prefix) {
+					return fmt.Errorf("key column %q cannot be %s", column.Name, column.Type)
+				}
+			}
+		}
+	}
+	if keys == 0 && keysType != Duplicate {
+		return fmt.Errorf("%s table %s needs key columns", keysType, t.Name)
+	}
+
+	for _, name := range t.Distribution.Columns {
+		column, ok := t.Column(name)
+		if !ok {
+			return fmt.Errorf("unknown distribution column %q", name)
+		}
+		if !column.Key && keysType != Duplicate {
+			return fmt.Errorf("distribution column %q of a %s table must be a key", name, keysType)
+		}
+	}
+	if t.Distribution.Buckets < 0 {
+		return fmt.Errorf("buckets cannot be negative")
+	}
+
+	if p := t.Partition; p != nil {
+		column, ok := t.Column(p.Column)
+		switch {
+		case !ok:
+			return fmt.Errorf("unknown partition column %q", p.Column)
+		case !column.Key || column.Nullable:
+			return fmt.Errorf("partition column %q must be a NOT NULL key", p.Column)
+		case !partitionIntervals[strings.ToLower(p.Interval)]:
+			return fmt.Errorf("unknown partition interval %q", p.Interval)
+		}
+	}
+	return nil
+}
+
+// CreateSQL returns the CREATE TABLE IF NOT EXISTS statement of the table
+// Key columns are written first, as Doris requires.
+func (t *Table) CreateSQL() (string, error) {
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
+	sb.WriteString(t.qualifiedName())
+	sb.WriteString(" (\n")
+
+	var keys, values []Column
+	for _, column := range t.Columns {
+		if column.Key {
+			keys = append(keys, column)
+		} else {
+			values = append(values, column)
+		}
+	}
+	for i, column := range append(keys, values...) {
+		if i > 0 {
+			sb.WriteString(",\n")
+		}
+		sb.WriteString("  ")
+		sb.WriteString(t.columnSQL(column))
+	}
+	sb.WriteString("\n)")
+
+	keysType := t.keysType()
+	if len(keys) > 0 {
+		fmt.Fprintf(&sb, "\n%s KEY(%s)", keysType, quoteNames(columnNames(keys)))
+	}
+	if t.Comment != "" {
+		fmt.Fprintf(&sb, "\nCOMMENT %s", quoteString(t.Comment))
+	}
+	if p := t.Partition; p != nil {
+		fmt.Fprintf(&sb, "\nAUTO PARTITION BY RANGE (date_trunc(%s, %s)) ()", quoteName(p.Column), quoteString(strings.ToLower(p.Interval)))
+	}
+
+	distribution := t.Distribution.Columns
+	if len(distribution) == 0 && keysType != Duplicate {
+		distribution = columnNames(keys)
+	}
+	if len(distribution) == 0 {
+		sb.WriteString("\nDISTRIBUTED BY RANDOM")
+	} else {
+		fmt.Fprintf(&sb, "\nDISTRIBUTED BY HASH(%s)", quoteNames(distribution))
+	}
+	if t.Distribution.Buckets > 0 {
+		fmt.Fprintf(&sb, " BUCKETS %d", t.Distribution.Buckets)
+	} else {
+		sb.WriteString(" BUCKETS AUTO")
+	}
+
+	if len(t.Properties) > 0 {
+		names := make([]string, 0, len(t.Properties))
+		for name := range t.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sb.WriteString("\nPROPERTIES (\n")
+		for i, name := range names {
+			if i > 0 {
+				sb.WriteString(",\n")
+			}
+			fmt.Fprintf(&sb, "  %s = %s", quoteProperty(name), quoteProperty(t.Properties[name]))
+		}
+		sb.WriteString("\n)")
+	}
+	return sb.String(), nil
+}
+
+// AddColumnsSQL returns the ALTER TABLE statement adding columns to the table
+// Columns are added as nullable value columns, rows already loaded have no value for them.
+func (t *Table) AddColumnsSQL(columns ...Column) (string, error) {
+	if t.Name == "" {
+		return "", fmt.Errorf("table name is required")
+	}
+	if len(columns) == 0 {
+		return "", fmt.Errorf("no columns to add")
+	}
+
+	definitions := make([]string, len(columns))
+	for i, column := range columns {
+		if column.Name == "" || column.Type == "" {
+			return "", fmt.Errorf("column name and type are required")
+		}
+		column.Key = false
+		column.Nullable = true
+		definitions[i] = t.columnSQL(column)
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN (%s)", t.qualifiedName(), strings.Join(definitions, ", ")), nil
+}
+
+// columnSQL returns the definition of column in CREATE and ALTER TABLE
+func (t *Table) columnSQL(column Column) string {
+	var sb strings.Builder
+	sb.WriteString(quoteName(column.Name))
+	sb.WriteByte(' ')
+	sb.WriteString(column.Type)
+	if !column.Key && t.keysType() == Aggregate {
+		aggregation := column.Aggregation
+		if aggregation == "" {
+			aggregation = defaultAggregation
+		}
+		sb.WriteByte(' ')
+		sb.WriteString(strings.ToUpper(aggregation))
+	}
+	if column.Nullable {
+		sb.WriteString(" NULL")
+	} else {
+		sb.WriteString(" NOT NULL")
+	}
+	if column.Comment != "" {
+		sb.WriteString(" COMMENT ")
+		sb.WriteString(quoteString(column.Comment))
+	}
+	return sb.String()
+}
+
+// keysType returns the data model, DUPLICATE by default
+func (t *Table) keysType() KeysType {
+	if t.Keys == "" {
+		return Duplicate
+	}
+	return KeysType(strings.ToUpper(string(t.Keys)))
+}
+
+// qualifiedName returns the quoted name of the table, with its database if set
+func (t *Table) qualifiedName() string {
+	if t.Database == "" {
+		return quoteName(t.Name)
+	}
+	return quoteName(t.Database) + "." + quoteName(t.Name)
+}
+
+// columnNames returns the names of columns
+func columnNames(columns []Column) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+// quoteName quotes an identifier with backquotes
+func quoteName(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// quoteNames quotes identifiers and joins them with commas
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quoteName(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// quoteString quotes a string literal with single quotes
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+// quoteProperty quotes a property name or value with double quotes
+func quoteProperty(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
@@ -0,0 +1,199 @@
+// Package deadletter stores batches that could not be fully loaded into Doris so they can be replayed later
+package deadletter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	payloadFileName = "payload.dat"
+	metaFileName    = "meta.json"
+	tmpSuffix       = ".tmp"
+)
+
+// Reason describes why a batch was sent to the dead-letter sink
+type Reason string
+
+const (
+	// ReasonFailed means the load failed after exhausting all retries
+	ReasonFailed Reason = "failed"
+	// ReasonFiltered means the load succeeded but Doris filtered some rows
+	ReasonFiltered Reason = "filtered"
+)
+
+// Record is a batch captured for later replay
+type Record struct {
+	Reason     Reason            `json:"reason"`
+	Database   string            `json:"database"`
+	Table      string            `json:"table"`
+	MultiTable bool              `json:"multi_table,omitempty"` // Lines carry their table as a "table|" prefix, Table is a placeholder
+	Label      string            `json:"label,omitempty"`
+	Headers    map[string]string `json:"headers"`
+	Error      string            `json:"error,omitempty"`
+	Response   string            `json:"response,omitempty"` // Stream load response content as JSON
+	Attempts   int               `json:"attempts"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Payload    []byte            `json:"-"`
+}
+
+// Sink receives batches that failed or had filtered rows
+// Implementations must be safe for concurrent use
+type Sink interface {
+	Write(record *Record) error
+}
+
+// DirSink is a Sink storing each record as a sub-directory of a local directory
+// Every entry contains payload.dat with the raw body and meta.json with label, headers and error metadata
+type DirSink struct {
+	dir string
+	seq uint64
+	mu  sync.Mutex
+}
+
+// NewDirSink creates a DirSink writing to dir, creating the directory if needed
+func NewDirSink(dir string) (*DirSink, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dead-letter directory: %w", err)
+	}
+	return &DirSink{dir: dir}, nil
+}
+
+// Dir returns the directory the sink writes to
+func (s *DirSink) Dir() string {
+	return s.dir
+}
+
+// Write stores the record atomically: the entry only becomes visible to ReadDir once complete
+func (s *DirSink) Write(record *Record) error {
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = time.Now()
+	}
+
+	name := fmt.Sprintf("%d_%06d_%s", record.CreatedAt.UnixMilli(), atomic.AddUint64(&s.seq, 1), sanitize(record.Label))
+	tmpPath := filepath.Join(s.dir, name+tmpSuffix)
+	finalPath := filepath.Join(s.dir, name)
+
+	if err := os.MkdirAll(tmpPath, 0o755); err != nil {
+		return fmt.Errorf("failed to create dead-letter entry: %w", err)
+	}
+
+	if err := writeFileSync(filepath.Join(tmpPath, payloadFileName), record.Payload); err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+
+	meta, err := json.MarshalIndent(record, "", "  ")
+	if err != nil {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("failed to marshal dead-letter metadata: %w", err)
+	}
+	if err := writeFileSync(filepath.Join(tmpPath, metaFileName), meta); err != nil {
+		os.RemoveAll(tmpPath)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Rename(tmpPath, finalPath); err != nil {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("failed to commit dead-letter entry: %w", err)
+	}
+
+	return nil
+}
+
+// Entry is a stored record as found on disk by ReadDir
+type Entry struct {
+	Path   string
+	Record Record
+}
+
+// OpenPayload opens the stored payload of the entry
+func (e *Entry) OpenPayload() (io.ReadCloser, error) {
+	return os.Open(filepath.Join(e.Path, payloadFileName))
+}
+
+// ReadPayload reads the whole stored payload of the entry
+func (e *Entry) ReadPayload() ([]byte, error) {
+	return os.ReadFile(filepath.Join(e.Path, payloadFileName))
+}
+
+// Remove deletes the entry from disk, typically after a successful replay
+func (e *Entry) Remove() error {
+	return os.RemoveAll(e.Path)
+}
+
+// ReadDir lists the complete entries stored in dir, oldest first
+// Payloads are not loaded into memory; use OpenPayload or ReadPayload
+func ReadDir(dir string) ([]*Entry, error) {
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter directory: %w", err)
+	}
+
+	var entries []*Entry
+	for _, item := range items {
+		if !item.IsDir() || strings.HasSuffix(item.Name(), tmpSuffix) {
+			continue
+		}
+
+		path := filepath.Join(dir, item.Name())
+		meta, err := os.ReadFile(filepath.Join(path, metaFileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dead-letter metadata %s: %w", path, err)
+		}
+
+		entry := &Entry{Path: path}
+		if err := json.Unmarshal(meta, &entry.Record); err != nil {
+			return nil, fmt.Errorf("failed to parse dead-letter metadata %s: %w", path, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return filepath.Base(entries[i].Path) < filepath.Base(entries[j].Path)
+	})
+
+	return entries, nil
+}
+
+// writeFileSync writes data to path and fsyncs it
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// sanitize makes a label safe to use as part of a file name
+func sanitize(label string) string {
+	if label == "" {
+		return "nolabel"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, label)
+}
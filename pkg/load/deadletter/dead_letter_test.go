@@ -0,0 +1,58 @@
+package deadletter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDirSinkWriteReadRemove(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "dlq")
+	sink, err := NewDirSink(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	records := []*Record{
+		{Reason: ReasonFailed, Database: "db", Table: "t", Label: "batch/1", Headers: map[string]string{"format": "csv"},
+			Error: "timeout", Attempts: 3, CreatedAt: created, Payload: []byte("1,a\n")},
+		{Reason: ReasonFiltered, Database: "db", Table: "t", CreatedAt: created.Add(time.Second), Payload: []byte("2,b\n")},
+	}
+	for _, record := range records {
+		if err := sink.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Incomplete entries are ignored
+	if err := os.MkdirAll(filepath.Join(dir, "0_000000_partial.tmp"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.Record.Label != "batch/1" || first.Record.Reason != ReasonFailed || first.Record.Attempts != 3 ||
+		first.Record.Headers["format"] != "csv" || filepath.Base(first.Path) != "1709251200000_000001_batch_1" {
+		t.Errorf("unexpected first entry %s: %+v", first.Path, first.Record)
+	}
+	if payload, err := first.ReadPayload(); err != nil || string(payload) != "1,a\n" {
+		t.Errorf("unexpected payload %q: %v", payload, err)
+	}
+	if entries[1].Record.Reason != ReasonFiltered || filepath.Base(entries[1].Path) != "1709251201000_000002_nolabel" {
+		t.Errorf("unexpected second entry %s", entries[1].Path)
+	}
+
+	if err := first.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err = ReadDir(dir); err != nil || len(entries) != 1 {
+		t.Errorf("expected 1 entry after Remove, got %d: %v", len(entries), err)
+	}
+}
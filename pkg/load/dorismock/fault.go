@@ -0,0 +1,69 @@
+package dorismock
+
+import (
+	"time"
+)
+
+// FaultType selects how a stream load request misbehaves
+type FaultType int
+
+const (
+	// FaultHTTPStatus answers with StatusCode (e.g. 500 or 503) instead of a load result
+	FaultHTTPStatus FaultType = iota
+	// FaultTimeout waits Delay before handling the request, or until the client gives up
+	FaultTimeout
+	// FaultFilterRows filters the first FilteredRows rows; the load fails unless max_filter_ratio allows them
+	FaultFilterRows
+	// FaultLoadFailed answers with Status "Fail" and Message
+	FaultLoadFailed
+	// FaultLabelExists answers with "Label Already Exists" and ExistingJobStatus
+	FaultLabelExists
+)
+
+// Fault scripts a failure of upcoming stream load requests
+type Fault struct {
+	Type  FaultType
+	Table string // Only requests loading into this table, "" for any table
+	Times int    // Number of requests affected, 0 for every matching request until ClearFaults
+
+	StatusCode        int           // FaultHTTPStatus: HTTP status code (default 503)
+	Delay             time.Duration // FaultTimeout: how long to stall (default 1 minute)
+	FilteredRows      int           // FaultFilterRows: rows filtered from the start of the batch (default 1)
+	Message           string        // FaultLoadFailed: error message
+	ExistingJobStatus string        // FaultLabelExists: status of the existing job (default "FINISHED")
+}
+
+// InjectFault queues a fault; faults are matched in the order they were injected
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fault := f
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes all pending faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// takeFault returns the fault applying to a load into table and consumes one of its uses; s.mu must be held
+func (s *Server) takeFault(table string) *Fault {
+	for i, f := range s.faults {
+		if f.Table != "" && f.Table != table {
+			continue
+		}
+
+		fault := *f
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return &fault
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+package dorismock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Keys types of a table, as reported by _schema
+const (
+	DuplicateKeys = "DUP_KEYS"
+	UniqueKeys    = "UNIQUE_KEYS"
+	AggregateKeys = "AGG_KEYS"
+)
+
+// Column defines a column served by _schema
+type Column struct {
+	Name        string
+	Type        string // e.g. "INT", "VARCHAR" or "DECIMAL128"
+	Precision   int
+	Scale       int
+	Nullable    bool
+	Key         bool
+	Aggregation string // e.g. "SUM" for value columns of aggregate tables
+	Comment     string
+}
+
+// tableSchema is a table defined by SetSchema
+type tableSchema struct {
+	keysType string
+	columns  []Column
+}
+
+// SetSchema defines database.table for the _schema API; Reset keeps it
+// Loads are not checked against it.
+func (s *Server) SetSchema(database, table, keysType string, columns ...Column) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables[database+"."+table] = &tableSchema{keysType: keysType, columns: append([]Column(nil), columns...)}
+}
+
+// Schema returns the columns of database.table and whether it is defined
+func (s *Server) Schema(database, table string) ([]Column, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	schema, ok := s.tables[database+"."+table]
+	if !ok {
+		return nil, false
+	}
+	return append([]Column(nil), schema.columns...), true
+}
+
+// handleSchema serves GET /api/{db}/{table}/_schema like Doris, every property being a string
+func (s *Server) handleSchema(w http.ResponseWriter, database, table string) {
+	s.mu.Lock()
+	schema, ok := s.tables[database+"."+table]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, map[string]interface{}{"msg": fmt.Sprintf("Unknown table '%s.%s'", database, table), "code": 1, "data": nil, "count": 0})
+		return
+	}
+
+	properties := make([]map[string]string, 0, len(schema.columns))
+	for _, column := range schema.columns {
+		property := map[string]string{
+			"name":             column.Name,
+			"type":             column.Type,
+			"is_nullable":      yesNo(column.Nullable),
+			"is_key":           yesNo(column.Key),
+			"aggregation_type": column.Aggregation,
+			"comment":          column.Comment,
+		}
+		if column.Precision > 0 {
+			property["precision"] = strconv.Itoa(column.Precision)
+			property["scale"] = strconv.Itoa(column.Scale)
+		}
+		properties = append(properties, property)
+	}
+	writeJSON(w, map[string]interface{}{
+		"msg":   "success",
+		"code":  0,
+		"data":  map[string]interface{}{"properties": properties, "keysType": schema.keysType, "status": 200},
+		"count": 0,
+	})
+}
+
+// yesNo formats a flag like Doris does in _schema
+func yesNo(flag bool) string {
+	if flag {
+		return "Yes"
+	}
+	return "No"
+}
+
+var (
+	createTablePattern = regexp.MustCompile(`(?is)^\s*CREATE\s+TABLE\s+(IF\s+NOT\s+EXISTS\s+)?([^\s(]+)\s*\(`)
+	alterTablePattern  = regexp.MustCompile(`(?is)^\s*ALTER\s+TABLE\s+(\S+)\s+ADD\s+COLUMN\s*(.*?)\s*;?\s*$`)
+	tableKeysPattern   = regexp.MustCompile(`(?is)^\s*(DUPLICATE|UNIQUE|AGGREGATE)\s+KEY\s*\(([^)]*)\)`)
+	commentPattern     = regexp.MustCompile(`(?is)\bCOMMENT\s+'((?:[^'\\]|\\.)*)'`)
+)
+
+// Aggregation types accepted in column definitions
+var aggregations = map[string]bool{"SUM": true, "MIN": true, "MAX": true, "REPLACE": true,
+	"REPLACE_IF_NOT_NULL": true, "HLL_UNION": true, "BITMAP_UNION": true, "QUANTILE_UNION": true}
+
+// Statements returns the statements the HTTP SQL API received
+func (s *Server) Statements() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.sqls...)
+}
+
+// handleQuery serves POST /api/query/default_cluster/{db}
+// CREATE TABLE and ALTER TABLE ... ADD COLUMN update the schemas served by _schema, other
+// statements are recorded and succeed.
+func (s *Server) handleQuery(w http.ResponseWriter, r *http.Request, database string) {
+	var request struct {
+		Stmt string `json:"stmt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Stmt == "" {
+		writeJSON(w, map[string]interface{}{"msg": "stmt is required", "code": 1, "data": nil, "count": 0})
+		return
+	}
+
+	s.mu.Lock()
+	s.sqls = append(s.sqls, request.Stmt)
+	err := s.applyLocked(database, request.Stmt)
+	s.mu.Unlock()
+	if err != nil {
+		writeJSON(w, map[string]interface{}{"msg": "errCode = 2, detailMessage = " + err.Error(), "code": 1, "data": nil, "count": 0})
+		return
+	}
+	writeJSON(w, map[string]interface{}{"msg": "success", "code": 0,
+		"data": map[string]interface{}{"type": "exec_status", "status": map[string]interface{}{}}, "count": 0})
+}
+
+// applyLocked applies a DDL statement to the schemas; s.mu must be held
+func (s *Server) applyLocked(database, stmt string) error {
+	if m := createTablePattern.FindStringSubmatchIndex(stmt); m != nil {
+		key := tableKey(database, stmt[m[4]:m[5]])
+		definitions, rest, err := enclosed(stmt[m[1]-1:])
+		if err != nil {
+			return err
+		}
+		if _, exists := s.tables[key]; exists {
+			if m[2] >= 0 {
+				return nil
+			}
+			return fmt.Errorf("Table '%s' already exists", key)
+		}
+
+		table := &tableSchema{keysType: DuplicateKeys}
+		for _, definition := range splitTopLevel(definitions) {
+			column, err := parseColumn(definition)
+			if err != nil {
+				return err
+			}
+			table.columns = append(table.columns, column)
+		}
+		if keys := tableKeysPattern.FindStringSubmatch(rest); keys != nil {
+			table.keysType = map[string]string{"DUPLICATE": DuplicateKeys, "UNIQUE": UniqueKeys, "AGGREGATE": AggregateKeys}[strings.ToUpper(keys[1])]
+			for _, name := range strings.Split(keys[2], ",") {
+				name = strings.Trim(strings.TrimSpace(name), "`")
+				for i := range table.columns {
+					if strings.EqualFold(table.columns[i].Name, name) {
+						table.columns[i].Key = true
+					}
+				}
+			}
+		}
+		s.tables[key] = table
+		return nil
+	}
+
+	if m := alterTablePattern.FindStringSubmatch(stmt); m != nil {
+		key := tableKey(database, m[1])
+		table, ok := s.tables[key]
+		if !ok {
+			return fmt.Errorf("Unknown table '%s'", key)
+		}
+		definitions := m[2]
+		if strings.HasPrefix(definitions, "(") {
+			inner, _, err := enclosed(definitions)
+			if err != nil {
+				return err
+			}
+			definitions = inner
+		}
+
+		var added []Column
+		for _, definition := range splitTopLevel(definitions) {
+			column, err := parseColumn(definition)
+			if err != nil {
+				return err
+			}
+			for _, existing := range append(table.columns, added...) {
+				if strings.EqualFold(existing.Name, column.Name) {
+					return fmt.Errorf("Can not add column which already exists in base table: %s", column.Name)
+				}
+			}
+			added = append(added, column)
+		}
+		table.columns = append(table.columns, added...)
+	}
+	return nil
+}
+
+// tableKey returns the "database.table" key of a possibly qualified table name
+func tableKey(database, name string) string {
+	name = strings.ReplaceAll(name, "`", "")
+	if strings.Contains(name, ".") {
+		return name
+	}
+	return database + "." + name
+}
+
+// enclosed returns the text inside the parentheses s starts with and the text after them
+func enclosed(s string) (string, string, error) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return s[1:i], s[i+1:], nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("unbalanced parentheses")
+}
+
+// splitTopLevel splits column definitions at the commas outside brackets and quotes
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(' || c == '<':
+			depth++
+		case c == ')' || c == '>':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	if strings.TrimSpace(s[start:]) != "" {
+		parts = append(parts, s[start:])
+	}
+	return parts
+}
+
+// parseColumn parses a column definition like "`id` DECIMAL(18, 2) SUM NOT NULL COMMENT 'x'"
+func parseColumn(definition string) (Column, error) {
+	definition = strings.TrimSpace(definition)
+	var column Column
+	if strings.HasPrefix(definition, "`") {
+		end := strings.Index(definition[1:], "`")
+		if end < 0 {
+			return Column{}, fmt.Errorf("invalid column definition %q", definition)
+		}
+		column.Name, definition = definition[1:end+1], definition[end+2:]
+	} else {
+		name, rest, _ := strings.Cut(definition, " ")
+		column.Name, definition = name, rest
+	}
+
+	// The type runs to the first space outside brackets
+	definition = strings.TrimSpace(definition)
+	depth, end := 0, len(definition)
+	for i := 0; i < len(definition); i++ {
+		c := definition[i]
+		if c == '(' || c == '<' {
+			depth++
+		} else if c == ')' || c == '>' {
+			depth--
+		} else if c == ' ' && depth == 0 {
+			end = i
+			break
+		}
+	}
+	fullType, rest := definition[:end], definition[end:]
+	if column.Name == "" || fullType == "" {
+		return Column{}, fmt.Errorf("invalid column definition %q", definition)
+	}
+	column.Type = strings.ToUpper(fullType)
+	if i := strings.IndexAny(column.Type, "(<"); i >= 0 {
+		if column.Type[i] == '(' && strings.HasPrefix(column.Type, "DECIMAL") {
+			precision, scale, _ := strings.Cut(strings.Trim(column.Type[i:], "()"), ",")
+			column.Precision, _ = strconv.Atoi(strings.TrimSpace(precision))
+			column.Scale, _ = strconv.Atoi(strings.TrimSpace(scale))
+		}
+		column.Type = column.Type[:i]
+	}
+
+	if m := commentPattern.FindStringSubmatchIndex(rest); m != nil {
+		column.Comment = rest[m[2]:m[3]]
+		rest = rest[:m[0]] + rest[m[1]:]
+	}
+	// Columns are NOT NULL unless declared NULL, like in Doris
+	words := strings.Fields(strings.ToUpper(rest))
+	for i, word := range words {
+		switch {
+		case word == "KEY":
+			column.Key = true
+		case aggregations[word]:
+			column.Aggregation = word
+		case word == "NULL":
+			column.Nullable = i == 0 || words[i-1] != "NOT"
+		}
+	}
+	return column, nil
+}
@@ -0,0 +1,707 @@
+// Package dorismock provides an in-process fake of the Doris stream load HTTP API for tests
+// The fake FE redirects stream loads to a fake BE with 307 like a real cluster, deduplicates labels,
+// supports two-phase commit, get_load_state, _schema and table DDL, records the rows it received per table and can be
+// scripted to fail with Fault.
+package dorismock
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+)
+
+// Load states reported by get_load_state
+const (
+	StatePrecommitted = "PRECOMMITTED"
+	StateVisible      = "VISIBLE"
+	StateAborted      = "ABORTED"
+	StateUnknown      = "UNKNOWN"
+)
+
+const (
+	// multiTableSeparator separates the table name from the row in a multi-table stream
+	multiTableSeparator = "|"
+
+	defaultFaultDelay = time.Minute
+)
+
+// Load describes a stream load request received by the fake BE
+type Load struct {
+	Database string
+	Table    string // Empty for multi-table loads
+	Label    string
+	Headers  http.Header
+	Rows     int    // Rows parsed from the request body, 0 if the request failed before parsing
+	Status   string // Status returned to the client, or the HTTP status text of an injected error
+	TxnID    int64
+}
+
+// streamLoadResult is the JSON body of a stream load response
+type streamLoadResult struct {
+	TxnID              int64  `json:"TxnId"`
+	Label              string `json:"Label"`
+	TwoPhaseCommit     string `json:"TwoPhaseCommit"`
+	Status             string `json:"Status"`
+	ExistingJobStatus  string `json:"ExistingJobStatus,omitempty"`
+	Message            string `json:"Message"`
+	NumberTotalRows    int64  `json:"NumberTotalRows"`
+	NumberLoadedRows   int64  `json:"NumberLoadedRows"`
+	NumberFilteredRows int    `json:"NumberFilteredRows"`
+	LoadBytes          int64  `json:"LoadBytes"`
+	LoadTimeMs         int    `json:"LoadTimeMs"`
+	ReadDataTimeMs     int    `json:"ReadDataTimeMs"`
+	WriteDataTimeMs    int    `json:"WriteDataTimeMs"`
+	ErrorURL           string `json:"ErrorURL,omitempty"`
+}
+
+// labelRecord is the transaction a label is bound to
+type labelRecord struct {
+	txnID int64
+	state string
+}
+
+// transaction holds the rows of a load until it becomes visible
+type transaction struct {
+	database string
+	label    string
+	state    string
+	rows     map[string][]string // table -> rows
+}
+
+// Server is a fake Doris cluster made of one FE and one BE
+type Server struct {
+	fe *httptest.Server
+	be *httptest.Server
+
+	mu        sync.Mutex
+	user      string
+	password  string
+	checkAuth bool
+	discard   bool
+	nextTxnID int64
+	labels    map[string]*labelRecord // "database/label" -> transaction
+	txns      map[int64]*transaction  // transaction id -> transaction
+	rows      map[string][]string     // "database.table" -> visible rows
+	errorLogs map[string][]string     // error log file -> lines
+	loads     []Load
+	faults    []*Fault
+	tables    map[string]*tableSchema // "database.table" -> schema served by _schema
+	sqls      []string                // statements received by the HTTP SQL API
+}
+
+// NewServer starts a fake FE and BE; call Close when done
+// Any credentials are accepted until SetAuth is called.
+func NewServer() *Server {
+	s := &Server{nextTxnID: 1000, tables: make(map[string]*tableSchema)}
+	s.resetLocked()
+	s.fe = httptest.NewServer(http.HandlerFunc(s.handleFrontend))
+	s.be = httptest.NewServer(http.HandlerFunc(s.handleBackend))
+	return s
+}
+
+// URL returns the FE endpoint to use in Config.Endpoints
+func (s *Server) URL() string {
+	return s.fe.URL
+}
+
+// NewClient returns a client loading CSV into test_db.users on the server with the credentials it
+// accepts and a short retry policy, followed by opts, which override them. It fails t when the
+// configuration is invalid.
+func (s *Server) NewClient(t testing.TB, opts ...config.Option) *client.DorisLoadClient {
+	t.Helper()
+
+	s.mu.Lock()
+	user, password := s.user, s.password
+	s.mu.Unlock()
+	if user == "" {
+		user, password = "root", "secret"
+	}
+
+	base := []config.Option{
+		config.WithEndpoints(s.URL()),
+		config.WithAuth(user, password),
+		config.WithDatabase("test_db"),
+		config.WithTable("users"),
+		config.WithRetry(&config.Retry{MaxRetryTimes: 2, BaseIntervalMs: 10, MaxTotalTimeMs: 10000}),
+	}
+	cfg, err := config.NewConfig(append(base, opts...)...)
+	if err != nil {
+		t.Fatalf("invalid test config: %v", err)
+	}
+	c, err := client.NewDorisClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return c
+}
+
+// BackendURL returns the BE endpoint stream loads are redirected to
+func (s *Server) BackendURL() string {
+	return s.be.URL
+}
+
+// Close shuts down the FE and BE
+func (s *Server) Close() {
+	s.fe.Close()
+	s.be.Close()
+}
+
+// SetAuth makes the server reject requests without the given credentials with 401
+func (s *Server) SetAuth(user, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.user = user
+	s.password = password
+	s.checkAuth = true
+}
+
+// DiscardRows makes the server count loaded rows without keeping them, for benchmarks loading
+// more data than fits in memory; Rows then returns nothing
+func (s *Server) DiscardRows(discard bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.discard = discard
+}
+
+// Rows returns the visible rows of database.table in the order they were loaded
+// CSV rows are raw lines, JSON rows are the raw JSON objects.
+func (s *Server) Rows(database, table string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.rows[database+"."+table]...)
+}
+
+// Loads returns every stream load request the BE received
+func (s *Server) Loads() []Load {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Load(nil), s.loads...)
+}
+
+// LoadState returns the state of the load with label in database, as get_load_state would
+func (s *Server) LoadState(database, label string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.labels[database+"/"+label]; ok {
+		return record.state
+	}
+	return StateUnknown
+}
+
+// Reset forgets all rows, labels, transactions, recorded loads and faults
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.resetLocked()
+}
+
+// resetLocked clears the server state; s.mu must be held
+func (s *Server) resetLocked() {
+	s.labels = make(map[string]*labelRecord)
+	s.txns = make(map[int64]*transaction)
+	s.rows = make(map[string][]string)
+	s.errorLogs = make(map[string][]string)
+	s.loads = nil
+	s.faults = nil
+}
+
+// authorized checks the basic auth of r against SetAuth
+func (s *Server) authorized(r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.checkAuth {
+		return true
+	}
+	user, password, ok := r.BasicAuth()
+	return ok && user == s.user && password == s.password
+}
+
+// handleFrontend serves the FE API: stream load redirects, get_load_state, 2PC, _schema and SQL
+func (s *Server) handleFrontend(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case isStreamLoadPath(parts):
+		s.redirectToBackend(w, r)
+	case len(parts) == 3 && parts[0] == "api" && parts[2] == "get_load_state":
+		s.handleLoadState(w, r, parts[1])
+	case len(parts) == 3 && parts[0] == "api" && parts[2] == "_stream_load_2pc":
+		s.handleTwoPhaseCommit(w, r, parts[1])
+	case len(parts) == 4 && parts[0] == "api" && parts[1] == "query":
+		s.handleQuery(w, r, parts[3])
+	case len(parts) == 4 && parts[0] == "api" && parts[3] == "_schema":
+		s.handleSchema(w, parts[1], parts[2])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// redirectToBackend answers a stream load with a 307 to the BE, carrying the credentials in the URL like Doris does
+func (s *Server) redirectToBackend(w http.ResponseWriter, r *http.Request) {
+	target, err := url.Parse(s.be.URL)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	target.Path = r.URL.Path
+	target.RawQuery = r.URL.RawQuery
+	if user, password, ok := r.BasicAuth(); ok {
+		target.User = url.UserPassword(user, password)
+	}
+	http.Redirect(w, r, target.String(), http.StatusTemporaryRedirect)
+}
+
+// handleLoadState serves GET /api/{db}/get_load_state?label=...
+func (s *Server) handleLoadState(w http.ResponseWriter, r *http.Request, database string) {
+	label := r.URL.Query().Get("label")
+	if label == "" {
+		writeJSON(w, map[string]interface{}{"msg": "label is empty", "code": 1, "data": nil, "count": 0})
+		return
+	}
+	writeJSON(w, map[string]interface{}{"msg": "success", "code": 0, "data": s.LoadState(database, label), "count": 0})
+}
+
+// handleTwoPhaseCommit serves PUT /api/{db}/_stream_load_2pc with txn_id or label and txn_operation headers
+func (s *Server) handleTwoPhaseCommit(w http.ResponseWriter, r *http.Request, database string) {
+	operation := strings.ToLower(r.Header.Get("txn_operation"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var txnID int64
+	if id := r.Header.Get("txn_id"); id != "" {
+		parsed, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			writeJSON(w, map[string]string{"status": "Fail", "msg": fmt.Sprintf("invalid txn_id %q", id)})
+			return
+		}
+		txnID = parsed
+	} else if record, ok := s.labels[database+"/"+r.Header.Get("label")]; ok {
+		txnID = record.txnID
+	}
+
+	txn, ok := s.txns[txnID]
+	if !ok || txn.database != database {
+		writeJSON(w, map[string]string{"status": "Fail", "msg": fmt.Sprintf("transaction [%d] not found", txnID)})
+		return
+	}
+	if txn.state != StatePrecommitted {
+		writeJSON(w, map[string]string{"status": "Fail", "msg": fmt.Sprintf("transaction [%d] is already %s", txnID, strings.ToLower(txn.state))})
+		return
+	}
+
+	switch operation {
+	case "commit":
+		s.publishLocked(txnID, txn)
+	case "abort":
+		txn.state = StateAborted
+		if record, ok := s.labels[database+"/"+txn.label]; ok && record.txnID == txnID {
+			record.state = StateAborted
+		}
+	default:
+		writeJSON(w, map[string]string{"status": "Fail", "msg": fmt.Sprintf("unknown txn_operation %q", operation)})
+		return
+	}
+	writeJSON(w, map[string]string{"status": "Success", "msg": fmt.Sprintf("transaction [%d] %s successfully.", txnID, operation)})
+}
+
+// handleBackend serves the BE API: stream loads and error logs
+func (s *Server) handleBackend(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/_load_error_log" {
+		s.handleErrorLog(w, r)
+		return
+	}
+	if !s.authorized(r) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if !isStreamLoadPath(parts) || r.Method != http.MethodPut {
+		http.NotFound(w, r)
+		return
+	}
+
+	load := Load{Database: parts[1], Label: r.Header.Get("label"), Headers: r.Header.Clone()}
+	if len(parts) == 4 {
+		load.Table = parts[2]
+	}
+	s.handleStreamLoad(w, r, &load)
+
+	s.mu.Lock()
+	s.loads = append(s.loads, load)
+	s.mu.Unlock()
+}
+
+// handleStreamLoad executes one stream load on the BE and fills in load
+func (s *Server) handleStreamLoad(w http.ResponseWriter, r *http.Request, load *Load) {
+	start := time.Now()
+
+	s.mu.Lock()
+	fault := s.takeFault(load.Table)
+	s.mu.Unlock()
+
+	if fault != nil {
+		switch fault.Type {
+		case FaultHTTPStatus:
+			code := fault.StatusCode
+			if code == 0 {
+				code = http.StatusServiceUnavailable
+			}
+			load.Status = http.StatusText(code)
+			http.Error(w, "injected fault", code)
+			return
+		case FaultTimeout:
+			delay := fault.Delay
+			if delay <= 0 {
+				delay = defaultFaultDelay
+			}
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				load.Status = "Timeout"
+				return
+			}
+		}
+	}
+
+	readStart := time.Now()
+	body, err := io.ReadAll(r.Body)
+	readTime := time.Since(readStart)
+	if err != nil {
+		load.Status = "Fail"
+		s.writeResult(w, load, &streamLoadResult{Status: "Fail", Message: fmt.Sprintf("failed to read body: %v", err)})
+		return
+	}
+
+	result := &streamLoadResult{Label: load.Label, TwoPhaseCommit: "false", LoadBytes: int64(len(body)),
+		ReadDataTimeMs: int(readTime.Milliseconds())}
+	writeStart := time.Now()
+	if body, err = decompress(r.Header.Get("compress_type"), body); err != nil {
+		load.Status = "Fail"
+		result.Status = "Fail"
+		result.Message = err.Error()
+		s.writeResult(w, load, result)
+		return
+	}
+	if fault != nil {
+		switch fault.Type {
+		case FaultLoadFailed:
+			result.Status = "Fail"
+			result.Message = fault.Message
+			s.writeResult(w, load, result)
+			return
+		case FaultLabelExists:
+			result.Status = "Label Already Exists"
+			result.ExistingJobStatus = fault.ExistingJobStatus
+			if result.ExistingJobStatus == "" {
+				result.ExistingJobStatus = "FINISHED"
+			}
+			result.Message = fmt.Sprintf("Label [%s] has already been used.", load.Label)
+			s.writeResult(w, load, result)
+			return
+		}
+	}
+
+	rows, err := parseRows(r.Header, body, load.Table == "")
+	if err != nil {
+		result.Status = "Fail"
+		result.Message = fmt.Sprintf("[DATA_QUALITY_ERROR]%v", err)
+		s.writeResult(w, load, result)
+		return
+	}
+	load.Rows = len(rows)
+	result.NumberTotalRows = int64(len(rows))
+
+	groupCommit := r.Header.Get("group_commit") != ""
+	if groupCommit && load.Label != "" {
+		result.Status = "Fail"
+		result.Message = "label and group_commit can't be set at the same time"
+		s.writeResult(w, load, result)
+		return
+	}
+
+	// Split rows per table before taking the lock
+	tableRows, err := splitTableRows(load.Table, rows)
+	if err != nil {
+		result.Status = "Fail"
+		result.Message = fmt.Sprintf("[DATA_QUALITY_ERROR]%v", err)
+		s.writeResult(w, load, result)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if load.Label == "" {
+		load.Label = fmt.Sprintf("group_commit_%d", s.nextTxnID)
+		if !groupCommit {
+			load.Label = fmt.Sprintf("mock_%d", s.nextTxnID)
+		}
+		result.Label = load.Label
+	}
+
+	labelKey := load.Database + "/" + load.Label
+	// An aborted label may be reused
+	if existing, ok := s.labels[labelKey]; ok && existing.state != StateAborted && !groupCommit {
+		result.Status = "Label Already Exists"
+		result.TxnID = existing.txnID
+		result.ExistingJobStatus = "RUNNING"
+		if existing.state == StateVisible {
+			result.ExistingJobStatus = "FINISHED"
+		}
+		result.Message = fmt.Sprintf("Label [%s] has already been used, relate to txn [%d]", load.Label, existing.txnID)
+		s.writeResult(w, load, result)
+		return
+	}
+
+	txnID := s.nextTxnID
+	s.nextTxnID++
+	result.TxnID = txnID
+	load.TxnID = txnID
+
+	if fault != nil && fault.Type == FaultFilterRows {
+		filtered := fault.FilteredRows
+		if filtered <= 0 {
+			filtered = 1
+		}
+		if filtered > len(rows) {
+			filtered = len(rows)
+		}
+		result.NumberFilteredRows = filtered
+		result.ErrorURL = s.addErrorLogLocked(txnID, rows[:filtered])
+
+		maxRatio, _ := strconv.ParseFloat(r.Header.Get("max_filter_ratio"), 64)
+		if len(rows) > 0 && float64(filtered)/float64(len(rows)) > maxRatio {
+			result.Status = "Fail"
+			result.Message = "[DATA_QUALITY_ERROR]too many filtered rows"
+			s.writeResult(w, load, result)
+			return
+		}
+		tableRows, _ = splitTableRows(load.Table, rows[filtered:])
+	}
+	result.NumberLoadedRows = result.NumberTotalRows - int64(result.NumberFilteredRows)
+
+	txn := &transaction{database: load.Database, label: load.Label, state: StatePrecommitted, rows: tableRows}
+	s.txns[txnID] = txn
+	if !groupCommit {
+		s.labels[labelKey] = &labelRecord{txnID: txnID, state: StatePrecommitted}
+	}
+
+	if strings.EqualFold(r.Header.Get("two_phase_commit"), "true") {
+		result.TwoPhaseCommit = "true"
+	} else {
+		s.publishLocked(txnID, txn)
+	}
+
+	result.Status = "Success"
+	result.Message = "OK"
+	result.WriteDataTimeMs = int(time.Since(writeStart).Milliseconds())
+	result.LoadTimeMs = int(time.Since(start).Milliseconds())
+	s.writeResult(w, load, result)
+}
+
+// publishLocked makes the rows of a transaction visible; s.mu must be held
+func (s *Server) publishLocked(txnID int64, txn *transaction) {
+	txn.state = StateVisible
+	if !s.discard {
+		for table, rows := range txn.rows {
+			key := txn.database + "." + table
+			s.rows[key] = append(s.rows[key], rows...)
+		}
+	}
+	txn.rows = nil
+	if record, ok := s.labels[txn.database+"/"+txn.label]; ok && record.txnID == txnID {
+		record.state = StateVisible
+	}
+}
+
+// addErrorLogLocked stores the error log of filtered rows and returns its URL; s.mu must be held
+func (s *Server) addErrorLogLocked(txnID int64, rows []string) string {
+	file := fmt.Sprintf("error_log_%d", txnID)
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		lines[i] = fmt.Sprintf("Reason: row filtered by dorismock fault. src line [%s]; ", row)
+	}
+	s.errorLogs[file] = lines
+	return s.be.URL + "/api/_load_error_log?file=" + url.QueryEscape(file)
+}
+
+// handleErrorLog serves GET /api/_load_error_log?file=...
+func (s *Server) handleErrorLog(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	lines, ok := s.errorLogs[r.URL.Query().Get("file")]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
+
+// writeResult sends a stream load result and records its status
+func (s *Server) writeResult(w http.ResponseWriter, load *Load, result *streamLoadResult) {
+	load.Status = result.Status
+	writeJSON(w, result)
+}
+
+// isStreamLoadPath reports whether path parts are api/{db}/{table}/_stream_load or api/{db}/_stream_load
+func isStreamLoadPath(parts []string) bool {
+	if len(parts) < 3 || len(parts) > 4 || parts[0] != "api" {
+		return false
+	}
+	return parts[len(parts)-1] == "_stream_load"
+}
+
+// decompress decodes a body sent with compress_type; only gz is supported by the fake
+func decompress(compressType string, body []byte) ([]byte, error) {
+	switch strings.ToLower(compressType) {
+	case "":
+		return body, nil
+	case "gz":
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gz body: %v", err)
+		}
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress gz body: %v", err)
+		}
+		return data, nil
+	default:
+		return nil, fmt.Errorf("unsupported compress_type %s", compressType)
+	}
+}
+
+// parseRows splits a stream load body into rows according to the format headers
+// Rows of a multi-table load keep their "table|" prefix.
+func parseRows(headers http.Header, body []byte, multiTable bool) ([]string, error) {
+	if strings.EqualFold(headers.Get("format"), "json") {
+		return parseJSONRows(headers, body, multiTable)
+	}
+
+	delimiter := "\n"
+	if d := headers.Get("line_delimiter"); d != "" {
+		delimiter = config.UnescapeDelimiter(d)
+	}
+
+	var rows []string
+	for _, line := range splitCSVLines(string(body), delimiter, headers.Get("enclose"), headers.Get("escape")) {
+		if line != "" {
+			rows = append(rows, line)
+		}
+	}
+	return rows, nil
+}
+
+// splitCSVLines splits body at delimiter, except inside values wrapped in enclose
+// Within an enclosed value, escape makes the next byte literal.
+func splitCSVLines(body, delimiter, enclose, escape string) []string {
+	if enclose == "" {
+		return strings.Split(body, delimiter)
+	}
+
+	var lines []string
+	start, enclosed := 0, false
+	for i := 0; i < len(body); i++ {
+		switch {
+		case enclosed && escape != "" && body[i] == escape[0]:
+			i++
+		case body[i] == enclose[0]:
+			enclosed = !enclosed
+		case !enclosed && strings.HasPrefix(body[i:], delimiter):
+			lines = append(lines, body[start:i])
+			i += len(delimiter) - 1
+			start = i + 1
+		}
+	}
+	return append(lines, body[start:])
+}
+
+// parseJSONRows splits a JSON body into one compact object per row
+// Lines of a multi-table load start with "table|", which is kept in front of the compact object.
+func parseJSONRows(headers http.Header, body []byte, multiTable bool) ([]string, error) {
+	var items [][]byte
+	switch {
+	case strings.EqualFold(headers.Get("strip_outer_array"), "true"):
+		var array []json.RawMessage
+		if err := json.Unmarshal(body, &array); err != nil {
+			return nil, fmt.Errorf("JSON data is not a valid array: %v", err)
+		}
+		for _, item := range array {
+			items = append(items, item)
+		}
+	case strings.EqualFold(headers.Get("read_json_by_line"), "true"):
+		for _, line := range bytes.Split(body, []byte("\n")) {
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				items = append(items, line)
+			}
+		}
+	default:
+		items = append(items, body)
+	}
+
+	rows := make([]string, 0, len(items))
+	for _, item := range items {
+		var prefix []byte
+		if multiTable {
+			if i := bytes.Index(item, []byte(multiTableSeparator)); i > 0 && !bytes.ContainsAny(item[:i], `{["`) {
+				prefix, item = item[:i+len(multiTableSeparator)], item[i+len(multiTableSeparator):]
+			}
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, item); err != nil {
+			return nil, fmt.Errorf("invalid JSON row %q: %v", item, err)
+		}
+		rows = append(rows, string(prefix)+compact.String())
+	}
+	return rows, nil
+}
+
+// splitTableRows groups rows by target table; rows of a multi-table load (table == "") start with "table|"
+func splitTableRows(table string, rows []string) (map[string][]string, error) {
+	result := make(map[string][]string)
+	if table != "" {
+		result[table] = rows
+		return result, nil
+	}
+
+	for _, row := range rows {
+		parts := strings.SplitN(row, multiTableSeparator, 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("multi-table row without table prefix: %q", row)
+		}
+		result[parts[0]] = append(result[parts[0]], parts[1])
+	}
+	return result, nil
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,85 @@
+package dorismock
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// doRequest sends a request with basic auth and decodes the JSON response
+func doRequest(t *testing.T, method, url string, body string, headers map[string]string) map[string]interface{} {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.SetBasicAuth("root", "")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return result
+}
+
+func TestTwoPhaseCommit(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+
+	result := doRequest(t, http.MethodPut, s.URL()+"/api/db/t/_stream_load", "1,a\n2,b\n",
+		map[string]string{"label": "txn_label", "two_phase_commit": "true"})
+	if result["Status"] != "Success" || result["TwoPhaseCommit"] != "true" {
+		t.Fatalf("unexpected load result: %v", result)
+	}
+	if rows := s.Rows("db", "t"); len(rows) != 0 {
+		t.Errorf("prepared rows should not be visible: %q", rows)
+	}
+
+	state := doRequest(t, http.MethodGet, s.URL()+"/api/db/get_load_state?label=txn_label", "", nil)
+	if state["data"] != StatePrecommitted {
+		t.Errorf("unexpected state: %v", state)
+	}
+
+	commit := doRequest(t, http.MethodPut, s.URL()+"/api/db/_stream_load_2pc", "",
+		map[string]string{"label": "txn_label", "txn_operation": "commit"})
+	if commit["status"] != "Success" {
+		t.Fatalf("unexpected commit result: %v", commit)
+	}
+	if rows := s.Rows("db", "t"); len(rows) != 2 {
+		t.Errorf("expected committed rows, got %q", rows)
+	}
+
+	again := doRequest(t, http.MethodPut, s.URL()+"/api/db/t/_stream_load", "3,c\n", map[string]string{"label": "txn_label"})
+	if again["Status"] != "Label Already Exists" || again["ExistingJobStatus"] != "FINISHED" {
+		t.Errorf("expected duplicate label, got %v", again)
+	}
+}
+
+func TestFaultTimes(t *testing.T) {
+	s := NewServer()
+	defer s.Close()
+	s.InjectFault(Fault{Type: FaultLoadFailed, Table: "t", Message: "boom", Times: 2})
+
+	for i, want := range []string{"Fail", "Fail", "Success"} {
+		result := doRequest(t, http.MethodPut, s.URL()+"/api/db/t/_stream_load", "1,a\n", nil)
+		if result["Status"] != want {
+			t.Errorf("request %d: expected %s, got %v", i, want, result)
+		}
+	}
+
+	other := doRequest(t, http.MethodPut, s.URL()+"/api/db/other/_stream_load", "1,a\n", nil)
+	if other["Status"] != "Success" {
+		t.Errorf("fault should only apply to table t: %v", other)
+	}
+}
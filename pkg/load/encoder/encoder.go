@@ -0,0 +1,491 @@
+// Package encoder writes rows of typed values in the CSV or JSON encoding Doris stream load reads
+package encoder
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+)
+
+const (
+	// NullCSV is how Doris reads NULL in CSV
+	NullCSV = `\N`
+
+	// DateTimeLayout is how times are written, Doris DATETIME keeps up to microseconds
+	DateTimeLayout = "2006-01-02 15:04:05.999999"
+	// DateLayout is how Date values are written
+	DateLayout = "2006-01-02"
+)
+
+// ErrNeedsEnclose is returned by WriteRow when a CSV value cannot be written without CSVFormat.Enclose
+var ErrNeedsEnclose = errors.New("value contains the column separator, line delimiter or \\N, set CSVFormat.Enclose")
+
+// Date is a time written as a DATE, without time of day
+type Date time.Time
+
+// bufferPool recycles the buffers of released encoders
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+
+// valueKind tells how an encoded value is written
+type valueKind int
+
+const (
+	kindNull   valueKind = iota
+	kindString           // Quoted in JSON
+	kindNumber           // Numbers and booleans, bare in JSON
+	kindJSON             // Arrays, maps, structs and JSON columns, bare in JSON
+)
+
+// Encoder writes rows in the encoding of a Format into a pooled buffer
+// Values may be nil (NULL), strings, integers, floats, booleans, time.Time (as DATETIME), Date,
+// []byte (binary, written as hex), json.RawMessage (a JSON column), *big.Int, *big.Float and
+// *big.Rat (decimals), pointers to those (nil is NULL), driver.Valuer such as sql.NullString,
+// and slices, arrays, maps and structs, written as JSON for ARRAY, MAP, STRUCT and JSON columns.
+// Other types are written with fmt.Sprint.
+// An Encoder is not safe for concurrent use.
+type Encoder struct {
+	csv       bool
+	separator string
+	delimiter string
+	enclose   byte
+	escape    byte
+	jsonArray bool
+	columns   []string
+	keys      [][]byte // Quoted JSON keys
+
+	buf     *bytes.Buffer
+	rows    int
+	closed  bool   // The JSON array was closed by Bytes
+	scratch []byte // Text of the value being written
+}
+
+// New creates an encoder for format
+// JSON rows are objects keyed by columns, which are required; CSV rows are positional, columns
+// are then only reported by Columns, e.g. for config.WithColumns.
+func New(format config.Format, columns ...string) (*Encoder, error) {
+	e := &Encoder{columns: columns, buf: bufferPool.Get().(*bytes.Buffer)}
+	e.buf.Reset()
+
+	switch f := format.(type) {
+	case *config.CSVFormat:
+		e.csv = true
+		e.separator = config.UnescapeDelimiter(f.ColumnSeparator)
+		e.delimiter = config.UnescapeDelimiter(f.LineDelimiter)
+		if f.Enclose != "" {
+			e.enclose = f.Enclose[0]
+		}
+		if f.Escape != "" {
+			e.escape = f.Escape[0]
+		}
+	case *config.JSONFormat:
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("JSON encoder requires column names")
+		}
+		e.jsonArray = f.Type == config.JSONArray
+		for _, name := range columns {
+			e.keys = append(e.keys, appendJSONString(nil, name))
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %T, use CSVFormat or JSONFormat", format)
+	}
+	return e, nil
+}
+
+// Columns returns the column names the encoder was created with
+func (e *Encoder) Columns() []string {
+	return e.columns
+}
+
+// WriteRow appends a row; on error the row is not written
+func (e *Encoder) WriteRow(values ...any) error {
+	if e.closed {
+		return fmt.Errorf("encoder is closed, call Reset before writing more rows")
+	}
+	if !e.csv && len(values) != len(e.keys) {
+		return fmt.Errorf("row has %d values for %d columns", len(values), len(e.keys))
+	}
+
+	start := e.buf.Len()
+	var err error
+	if e.csv {
+		err = e.writeCSV(values)
+	} else {
+		err = e.writeJSON(values)
+	}
+	if err != nil {
+		e.buf.Truncate(start)
+		return err
+	}
+	e.rows++
+	return nil
+}
+
+// Rows returns the number of rows written since the last Reset
+func (e *Encoder) Rows() int {
+	return e.rows
+}
+
+// Len returns the size of the encoded rows
+func (e *Encoder) Len() int {
+	return e.buf.Len()
+}
+
+// Bytes returns the encoded rows, valid until the next Reset or Release
+// For JSON arrays it closes the array, no more rows can be written until Reset.
+func (e *Encoder) Bytes() []byte {
+	if e.jsonArray && !e.closed {
+		if e.rows == 0 {
+			e.buf.WriteByte('[')
+		}
+		e.buf.WriteByte(']')
+		e.closed = true
+	}
+	return e.buf.Bytes()
+}
+
+// Reader returns the encoded rows as a reader for DorisLoadClient.Load
+func (e *Encoder) Reader() *bytes.Reader {
+	return bytes.NewReader(e.Bytes())
+}
+
+// Reset discards the encoded rows, keeping the buffer
+func (e *Encoder) Reset() {
+	e.buf.Reset()
+	e.rows = 0
+	e.closed = false
+}
+
+// Release returns the buffer to the pool; the encoder must not be used afterwards
+func (e *Encoder) Release() {
+	if e.buf == nil {
+		return
+	}
+	e.buf.Reset()
+	bufferPool.Put(e.buf)
+	e.buf = nil
+}
+
+// writeCSV appends a CSV row
+func (e *Encoder) writeCSV(values []any) error {
+	for i, value := range values {
+		if i > 0 {
+			e.buf.WriteString(e.separator)
+		}
+
+		kind, err := e.encode(value)
+		if err != nil {
+			return fmt.Errorf("column %d: %w", i+1, err)
+		}
+		if kind == kindNull {
+			e.buf.WriteString(NullCSV)
+			continue
+		}
+		if err := e.writeCSVText(e.scratch); err != nil {
+			return fmt.Errorf("column %d: %w", i+1, err)
+		}
+	}
+	e.buf.WriteString(e.delimiter)
+	return nil
+}
+
+// writeCSVText appends a value, enclosed and escaped if it contains special characters
+func (e *Encoder) writeCSVText(text []byte) error {
+	special := bytes.Contains(text, []byte(e.separator)) || bytes.Contains(text, []byte(e.delimiter)) ||
+		string(text) == NullCSV
+	if e.enclose != 0 && bytes.IndexByte(text, e.enclose) >= 0 {
+		special = true
+	}
+	if !special {
+		e.buf.Write(text)
+		return nil
+	}
+	if e.enclose == 0 {
+		return ErrNeedsEnclose
+	}
+
+	e.buf.WriteByte(e.enclose)
+	for _, b := range text {
+		if b == e.enclose || (e.escape != 0 && b == e.escape) {
+			if e.escape == 0 {
+				return fmt.Errorf("value contains the enclose character, set CSVFormat.Escape")
+			}
+			e.buf.WriteByte(e.escape)
+		}
+		e.buf.WriteByte(b)
+	}
+	e.buf.WriteByte(e.enclose)
+	return nil
+}
+
+// writeJSON appends a JSON object row
+func (e *Encoder) writeJSON(values []any) error {
+	if e.jsonArray {
+		if e.rows == 0 {
+			e.buf.WriteByte('[')
+		} else {
+			e.buf.WriteByte(',')
+		}
+	}
+
+	e.buf.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		e.buf.Write(e.keys[i])
+		e.buf.WriteByte(':')
+
+		kind, err := e.encode(value)
+		if err != nil {
+			return fmt.Errorf("column %s: %w", e.columns[i], err)
+		}
+		switch kind {
+		case kindNull:
+			e.buf.WriteString("null")
+		case kindString:
+			e.buf.Write(appendJSONString(nil, string(e.scratch)))
+		default:
+			e.buf.Write(e.scratch)
+		}
+	}
+	e.buf.WriteByte('}')
+	if !e.jsonArray {
+		e.buf.WriteByte('\n')
+	}
+	return nil
+}
+
+// encode writes the text of value into e.scratch and returns its kind
+func (e *Encoder) encode(value any) (valueKind, error) {
+	e.scratch = e.scratch[:0]
+	switch v := value.(type) {
+	case nil:
+		return kindNull, nil
+	case string:
+		e.scratch = append(e.scratch, v...)
+		return kindString, nil
+	case json.RawMessage:
+		if v == nil {
+			return kindNull, nil
+		}
+		e.scratch = append(e.scratch, v...)
+		return kindJSON, nil
+	case []byte:
+		if v == nil {
+			return kindNull, nil
+		}
+		e.scratch = append(e.scratch, make([]byte, hex.EncodedLen(len(v)))...)
+		hex.Encode(e.scratch, v)
+		return kindString, nil
+	case bool:
+		if e.csv {
+			// Doris reads booleans from CSV as 0 and 1
+			if v {
+				e.scratch = append(e.scratch, '1')
+			} else {
+				e.scratch = append(e.scratch, '0')
+			}
+		} else {
+			e.scratch = strconv.AppendBool(e.scratch, v)
+		}
+		return kindNumber, nil
+	case int:
+		e.scratch = strconv.AppendInt(e.scratch, int64(v), 10)
+	case int8:
+		e.scratch = strconv.AppendInt(e.scratch, int64(v), 10)
+	case int16:
+		e.scratch = strconv.AppendInt(e.scratch, int64(v), 10)
+	case int32:
+		e.scratch = strconv.AppendInt(e.scratch, int64(v), 10)
+	case int64:
+		e.scratch = strconv.AppendInt(e.scratch, v, 10)
+	case uint:
+		e.scratch = strconv.AppendUint(e.scratch, uint64(v), 10)
+	case uint8:
+		e.scratch = strconv.AppendUint(e.scratch, uint64(v), 10)
+	case uint16:
+		e.scratch = strconv.AppendUint(e.scratch, uint64(v), 10)
+	case uint32:
+		e.scratch = strconv.AppendUint(e.scratch, uint64(v), 10)
+	case uint64:
+		e.scratch = strconv.AppendUint(e.scratch, v, 10)
+	case float32:
+		return e.encodeFloat(float64(v), 32)
+	case float64:
+		return e.encodeFloat(v, 64)
+	case time.Time:
+		e.scratch = v.AppendFormat(e.scratch, DateTimeLayout)
+		return kindString, nil
+	case Date:
+		e.scratch = time.Time(v).AppendFormat(e.scratch, DateLayout)
+		return kindString, nil
+	case *big.Int:
+		if v == nil {
+			return kindNull, nil
+		}
+		e.scratch = v.Append(e.scratch, 10)
+	case *big.Float:
+		if v == nil {
+			return kindNull, nil
+		}
+		e.scratch = v.Append(e.scratch, 'f', -1)
+	case *big.Rat:
+		if v == nil {
+			return kindNull, nil
+		}
+		e.scratch = append(e.scratch, ratString(v)...)
+	case driver.Valuer:
+		return e.encodeValuer(v)
+	default:
+		return e.encodeReflect(value)
+	}
+	return kindNumber, nil
+}
+
+// encodeFloat writes a float, NaN and infinities as NULL since Doris cannot store them
+func (e *Encoder) encodeFloat(v float64, bitSize int) (valueKind, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return kindNull, nil
+	}
+	e.scratch = strconv.AppendFloat(e.scratch, v, 'g', -1, bitSize)
+	return kindNumber, nil
+}
+
+// encodeValuer writes the value of a driver.Valuer such as sql.NullInt64
+func (e *Encoder) encodeValuer(v driver.Valuer) (valueKind, error) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return kindNull, nil
+	}
+	value, err := v.Value()
+	if err != nil {
+		return kindNull, err
+	}
+	if _, ok := value.(driver.Valuer); ok {
+		return kindNull, fmt.Errorf("%T returned another driver.Valuer", v)
+	}
+	return e.encode(value)
+}
+
+// encodeReflect writes pointers, named basic types, composite values as JSON, and other values with fmt.Sprint
+func (e *Encoder) encodeReflect(value any) (valueKind, error) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return kindNull, nil
+		}
+		return e.encode(rv.Elem().Interface())
+	case reflect.Slice, reflect.Map:
+		if rv.IsNil() {
+			return kindNull, nil
+		}
+	}
+
+	if m, ok := value.(json.Marshaler); ok {
+		data, err := m.MarshalJSON()
+		if err != nil {
+			return kindNull, err
+		}
+		e.scratch = append(e.scratch, data...)
+		return kindJSON, nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.Struct:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return kindNull, err
+		}
+		e.scratch = append(e.scratch, data...)
+		return kindJSON, nil
+	case reflect.String:
+		return e.encode(rv.String())
+	case reflect.Bool:
+		return e.encode(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.encode(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return e.encode(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return e.encodeFloat(rv.Float(), rv.Type().Bits())
+	}
+
+	e.scratch = append(e.scratch, fmt.Sprint(value)...)
+	return kindString, nil
+}
+
+// ratString writes an exact decimal for rationals with a finite expansion, 18 digits otherwise
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	// A denominator of only 2s and 5s has as many decimals as its largest exponent
+	denominator := new(big.Int).Set(r.Denom())
+	decimals := 0
+	for _, p := range []int64{2, 5} {
+		prime, m := big.NewInt(p), new(big.Int)
+		n := 0
+		for {
+			q, rem := new(big.Int).QuoRem(denominator, prime, m)
+			if rem.Sign() != 0 {
+				break
+			}
+			denominator, n = q, n+1
+		}
+		if n > decimals {
+			decimals = n
+		}
+	}
+	if denominator.Cmp(big.NewInt(1)) != 0 {
+		decimals = 18
+	}
+	return strings.TrimRight(strings.TrimRight(r.FloatString(decimals), "0"), ".")
+}
+
+// appendJSONString appends s as a JSON string, keeping valid UTF-8 as is
+func appendJSONString(dst []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			switch {
+			case b == '"' || b == '\\':
+				dst = append(dst, '\\', b)
+			case b == '\n':
+				dst = append(dst, '\\', 'n')
+			case b == '\r':
+				dst = append(dst, '\\', 'r')
+			case b == '\t':
+				dst = append(dst, '\\', 't')
+			case b < 0x20:
+				dst = append(dst, '\\', 'u', '0', '0', hexDigits[b>>4], hexDigits[b&0xf])
+			default:
+				dst = append(dst, b)
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, `�`...)
+		} else {
+			dst = append(dst, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(dst, '"')
+}
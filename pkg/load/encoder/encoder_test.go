@@ -0,0 +1,99 @@
+package encoder
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+)
+
+var testTime = time.Date(2024, 3, 1, 12, 30, 5, 250000000, time.UTC)
+
+func TestCSVEncoding(t *testing.T) {
+	enc, err := New(&config.CSVFormat{ColumnSeparator: ",", LineDelimiter: "\\n", Enclose: `"`, Escape: `\`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Release()
+
+	var missing *int
+	rows := [][]any{
+		{1, "plain", nil, missing, true, testTime, Date(testTime)},
+		{int8(-2), "a,b", "line\nbreak", `say "hi"`, `C:\dir`, `\N`, ""},
+		{uint64(3), 1.5, math.NaN(), []byte{0xde, 0xad}, big.NewRat(1, 8), []int{1, 2}, map[string]int{"k": 1}},
+		{sql.NullString{}, sql.NullInt64{Int64: 7, Valid: true}, json.RawMessage(`{"a":1}`), float32(0.1), false, "", ""},
+	}
+	for _, row := range rows {
+		if err := enc.WriteRow(row...); err != nil {
+			t.Fatalf("failed to write %v: %v", row, err)
+		}
+	}
+
+	expected := "1,plain,\\N,\\N,1,2024-03-01 12:30:05.25,2024-03-01\n" +
+		`-2,"a,b","line` + "\n" + `break","say \"hi\"",C:\dir,"\\N",` + "\n" +
+		`3,1.5,\N,dead,0.125,"[1,2]","{\"k\":1}"` + "\n" +
+		`\N,7,"{\"a\":1}",0.1,0,,` + "\n"
+	if got := string(enc.Bytes()); got != expected {
+		t.Errorf("unexpected CSV:\n%s\nexpected:\n%s", got, expected)
+	}
+	if enc.Rows() != 4 {
+		t.Errorf("expected 4 rows, got %d", enc.Rows())
+	}
+}
+
+func TestCSVWithoutEncloseRejectsSpecialValues(t *testing.T) {
+	enc, err := New(&config.CSVFormat{ColumnSeparator: "|", LineDelimiter: "\\n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteRow(1, "ok"); err != nil {
+		t.Fatal(err)
+	}
+	for _, value := range []string{"a|b", "a\nb", `\N`} {
+		if err := enc.WriteRow(2, value); !errors.Is(err, ErrNeedsEnclose) {
+			t.Errorf("expected ErrNeedsEnclose for %q, got %v", value, err)
+		}
+	}
+	if got := string(enc.Bytes()); got != "1|ok\n" {
+		t.Errorf("failed rows must not be written, got %q", got)
+	}
+}
+
+func TestJSONEncoding(t *testing.T) {
+	enc, err := New(&config.JSONFormat{Type: config.JSONArray}, "id", "name", "tags", "doc", "created", "price", "raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteRow(1, "a \"quoted\"\nname", []string{"x"}, json.RawMessage(`{"k":[1]}`), testTime, big.NewRat(21, 2), []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteRow(2, nil, nil, nil, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.WriteRow(3); err == nil {
+		t.Errorf("expected an error for a short row")
+	}
+
+	expected := `[{"id":1,"name":"a \"quoted\"\nname","tags":["x"],"doc":{"k":[1]},"created":"2024-03-01 12:30:05.25","price":10.5,"raw":"6869"},` +
+		`{"id":2,"name":null,"tags":null,"doc":null,"created":null,"price":null,"raw":null}]`
+	if got := string(enc.Bytes()); got != expected {
+		t.Errorf("unexpected JSON:\n%s\nexpected:\n%s", got, expected)
+	}
+	var parsed []map[string]any
+	if err := json.Unmarshal(enc.Bytes(), &parsed); err != nil || len(parsed) != 2 {
+		t.Errorf("output is not a valid JSON array: %v", err)
+	}
+
+	if err := enc.WriteRow(4, "", nil, nil, nil, nil, nil); err == nil {
+		t.Errorf("expected an error writing to a closed array")
+	}
+	enc.Reset()
+	if got := string(enc.Bytes()); got != "[]" {
+		t.Errorf("expected an empty array after Reset, got %q", got)
+	}
+}
@@ -0,0 +1,36 @@
+package encoder_test
+
+import (
+	"testing"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/encoder"
+)
+
+func TestEnclosedRowsLoad(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+
+	format := &config.CSVFormat{ColumnSeparator: ",", LineDelimiter: "\\n", Enclose: `"`, Escape: `\`}
+	c := server.NewClient(t, config.WithFormat(format))
+
+	enc, err := encoder.New(format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enc.Release()
+	enc.WriteRow(1, "multi\nline, text")
+	enc.WriteRow(2, `quoted "name"`)
+
+	response, err := c.Load(enc.Reader())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Resp.NumberLoadedRows != 2 || len(server.Rows("test_db", "users")) != 2 {
+		t.Errorf("expected 2 rows, got %+v", response.Resp)
+	}
+	if headers := server.Loads()[0].Headers; headers.Get("enclose") != `"` || headers.Get("escape") != `\` {
+		t.Errorf("missing enclose headers: %v", headers)
+	}
+}
@@ -3,7 +3,8 @@ package exception
 
 // StreamLoadError represents an error that occurred during a stream load operation
 type StreamLoadError struct {
-	Message string
+	Message    string
+	StatusCode int // HTTP status code of the response, 0 if the error did not come from a response
 }
 
 // Error returns the error message
@@ -16,4 +17,12 @@ func NewStreamLoadError(message string) *StreamLoadError {
 	return &StreamLoadError{
 		Message: message,
 	}
-} 
\ No newline at end of file
+}
+
+// NewStreamLoadStatusError creates a new StreamLoadError for an HTTP response with the given status code
+func NewStreamLoadStatusError(statusCode int, message string) *StreamLoadError {
+	return &StreamLoadError{
+		Message:    message,
+		StatusCode: statusCode,
+	}
+}
@@ -0,0 +1,300 @@
+// Package faultinject provides an http.RoundTripper that injects network faults for chaos testing
+// Plug a Transport into Config.Transport to exercise the retry, labeling and deduplication paths of
+// the client against latency, connection resets, partial writes and truncated responses.
+package faultinject
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrConnectionReset is the default error of injected connection failures
+	ErrConnectionReset = errors.New("faultinject: connection reset by peer")
+)
+
+// FaultKind selects what an injected fault does to a request
+type FaultKind int
+
+const (
+	// FaultLatency delays the request by Latency and lets other rules apply
+	FaultLatency FaultKind = iota
+	// FaultError fails the request with Err before anything is sent
+	FaultError
+	// FaultResetBody sends the first Bytes bytes of the request body, then fails with a connection reset
+	FaultResetBody
+	// FaultTruncateResponse forwards the request but cuts the response body after Bytes bytes
+	FaultTruncateResponse
+	// FaultStatus answers with StatusCode and Body without forwarding the request
+	FaultStatus
+)
+
+// Rule schedules a fault on matching requests
+type Rule struct {
+	Kind  FaultKind
+	Match func(*http.Request) bool // nil matches every request
+
+	Nth         int     // Only the Nth matching request (1-based), 0 for every matching request
+	Probability float64 // Chance of injecting into a matching request, 0 means always
+	Times       int     // Maximum number of injections, 0 for unlimited
+
+	Latency    time.Duration // FaultLatency: added delay
+	Err        error         // FaultError: returned error, ErrConnectionReset if nil
+	Bytes      int64         // FaultResetBody, FaultTruncateResponse: bytes let through
+	StatusCode int           // FaultStatus: response status code
+	Body       string        // FaultStatus: response body
+
+	matched  int
+	injected int
+}
+
+// Stats counts the requests seen and faults injected by a Transport
+type Stats struct {
+	Requests int
+	Faults   int
+}
+
+// Transport wraps another RoundTripper and injects the faults scheduled by its rules
+// Rules are evaluated in order: every matching latency rule applies, then the first other matching
+// rule decides the outcome of the request.
+type Transport struct {
+	base http.RoundTripper
+
+	mu    sync.Mutex
+	rules []*Rule
+	rng   *rand.Rand
+	stats Stats
+}
+
+// New creates a Transport forwarding to base (http.DefaultTransport if nil) with the given rules
+func New(base http.RoundTripper, rules ...Rule) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &Transport{base: base, rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	for _, rule := range rules {
+		t.AddRule(rule)
+	}
+	return t
+}
+
+// AddRule schedules another fault
+func (t *Transport) AddRule(rule Rule) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := rule
+	t.rules = append(t.rules, &r)
+}
+
+// Reset removes all rules and clears the statistics
+func (t *Transport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rules = nil
+	t.stats = Stats{}
+}
+
+// Seed makes random failure rates reproducible
+func (t *Transport) Seed(seed int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rng = rand.New(rand.NewSource(seed))
+}
+
+// Stats returns the number of requests seen and faults injected
+func (t *Transport) Stats() Stats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stats
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	latency, fault := t.schedule(req)
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			closeBody(req)
+			return nil, req.Context().Err()
+		}
+	}
+
+	if fault == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	switch fault.Kind {
+	case FaultError:
+		closeBody(req)
+		if fault.Err != nil {
+			return nil, fault.Err
+		}
+		return nil, ErrConnectionReset
+
+	case FaultResetBody:
+		if req.Body == nil || req.Body == http.NoBody {
+			return nil, ErrConnectionReset
+		}
+		clone := req.Clone(req.Context())
+		clone.Body = &resetBody{body: req.Body, remaining: fault.Bytes}
+		resp, err := t.base.RoundTrip(clone)
+		if err == nil {
+			// The server answered before reading the whole body, the connection still breaks
+			resp.Body.Close()
+		}
+		return nil, fmt.Errorf("%w after %d request bytes", ErrConnectionReset, fault.Bytes)
+
+	case FaultTruncateResponse:
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = &truncatedBody{body: resp.Body, remaining: fault.Bytes}
+		return resp, nil
+
+	case FaultStatus:
+		closeBody(req)
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", fault.StatusCode, http.StatusText(fault.StatusCode)),
+			StatusCode:    fault.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        make(http.Header),
+			Body:          io.NopCloser(strings.NewReader(fault.Body)),
+			ContentLength: int64(len(fault.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// schedule returns the latency to add to req and the fault deciding its outcome, if any
+// Every rule counts the requests it matches, so Nth refers to the same request whatever other rules do.
+func (t *Transport) schedule(req *http.Request) (time.Duration, *Rule) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stats.Requests++
+
+	var latency time.Duration
+	var fault *Rule
+	for _, rule := range t.rules {
+		if !t.due(rule, req) || (fault != nil && rule.Kind != FaultLatency) {
+			continue
+		}
+
+		rule.injected++
+		t.stats.Faults++
+		if rule.Kind == FaultLatency {
+			latency += rule.Latency
+			continue
+		}
+		copied := *rule
+		fault = &copied
+	}
+	return latency, fault
+}
+
+// due counts req against rule and reports whether the rule's schedule selects it; t.mu must be held
+func (t *Transport) due(rule *Rule, req *http.Request) bool {
+	if rule.Match != nil && !rule.Match(req) {
+		return false
+	}
+	rule.matched++
+
+	if rule.Times > 0 && rule.injected >= rule.Times {
+		return false
+	}
+	if rule.Nth > 0 && rule.matched != rule.Nth {
+		return false
+	}
+	if rule.Probability > 0 && t.rng.Float64() >= rule.Probability {
+		return false
+	}
+	return true
+}
+
+// MatchPath matches requests whose URL path contains substr, e.g. "_stream_load"
+func MatchPath(substr string) func(*http.Request) bool {
+	return func(req *http.Request) bool {
+		return strings.Contains(req.URL.Path, substr)
+	}
+}
+
+// MatchHost matches requests sent to host ("host:port")
+func MatchHost(host string) func(*http.Request) bool {
+	return func(req *http.Request) bool {
+		return req.URL.Host == host
+	}
+}
+
+// closeBody closes the body of a request that will not be sent, as RoundTrip must
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// resetBody lets remaining bytes of a request body through, then fails like a reset connection
+type resetBody struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+// Read implements io.Reader
+func (b *resetBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, ErrConnectionReset
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *resetBody) Close() error {
+	return b.body.Close()
+}
+
+// truncatedBody returns remaining bytes of a response body, then fails with io.ErrUnexpectedEOF
+type truncatedBody struct {
+	body      io.ReadCloser
+	remaining int64
+}
+
+// Read implements io.Reader
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.body.Read(p)
+	b.remaining -= int64(n)
+	if err == io.EOF {
+		// The body was shorter than the cut, nothing is lost
+		return n, io.EOF
+	}
+	return n, err
+}
+
+// Close implements io.Closer
+func (b *truncatedBody) Close() error {
+	return b.body.Close()
+}
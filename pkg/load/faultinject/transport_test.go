@@ -0,0 +1,79 @@
+package faultinject
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTransportSchedule(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"Status": "Success"}`))
+	}))
+	defer backend.Close()
+
+	transport := New(nil,
+		Rule{Kind: FaultError, Nth: 2},
+		Rule{Kind: FaultStatus, StatusCode: http.StatusServiceUnavailable, Nth: 3},
+		Rule{Kind: FaultTruncateResponse, Bytes: 5, Nth: 4},
+	)
+	client := &http.Client{Transport: transport}
+
+	get := func() (*http.Response, error) {
+		return client.Get(backend.URL)
+	}
+
+	if resp, err := get(); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("first request should pass: %v", err)
+	}
+	if _, err := get(); !errors.Is(err, ErrConnectionReset) {
+		t.Errorf("second request should fail with a reset, got %v", err)
+	}
+	if resp, err := get(); err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("third request should get 503, got %v %v", resp, err)
+	}
+
+	resp, err := get()
+	if err != nil {
+		t.Fatalf("fourth request should be forwarded: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if !errors.Is(err, io.ErrUnexpectedEOF) || string(body) != `{"Sta` {
+		t.Errorf("fourth response should be truncated, got %q %v", body, err)
+	}
+
+	if stats := transport.Stats(); stats.Requests != 4 || stats.Faults != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestTransportProbabilityIsReproducible(t *testing.T) {
+	run := func() []bool {
+		transport := New(http.NewFileTransport(http.Dir(".")), Rule{Kind: FaultError, Probability: 0.5})
+		transport.Seed(42)
+
+		var failed []bool
+		for i := 0; i < 20; i++ {
+			req, _ := http.NewRequest(http.MethodGet, "file:///transport.go", nil)
+			_, err := transport.RoundTrip(req)
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	first, second := run(), run()
+	failures := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("same seed gave different schedules at request %d", i)
+		}
+		if first[i] {
+			failures++
+		}
+	}
+	if failures == 0 || failures == len(first) {
+		t.Errorf("expected some but not all requests to fail, got %d", failures)
+	}
+}
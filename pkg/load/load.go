@@ -3,6 +3,8 @@ package load
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"io"
 	"os"
@@ -10,8 +12,12 @@ import (
 
 	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
 	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/ddl"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/deadletter"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/encoder"
 	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
 	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/sqlsource"
 )
 
 // ================================
@@ -35,6 +41,14 @@ type LoadSetting = config.Config
 type BatchMode = config.GroupCommitMode
 type GroupCommitMode = config.GroupCommitMode
 type Retry = config.Retry
+type FieldError = config.FieldError
+type ValidationError = config.ValidationError
+type Option = config.Option
+type LoadOption = client.LoadOption
+
+// Credentials aliases
+type Credentials = config.Credentials
+type CredentialsProvider = config.CredentialsProvider
 
 // Log aliases
 type LogLevel = log.Level
@@ -45,11 +59,124 @@ type ContextLogger = log.ContextLogger
 type LoadResponse = loader.LoadResponse
 type LoadStatus = loader.LoadStatus
 type RespContent = loader.RespContent
+type RejectedRow = loader.RejectedRow
+type RejectedRowsError = loader.RejectedRowsError
+
+// Schema aliases
+type TableSchema = loader.TableSchema
+type Column = loader.Column
+type SchemaError = loader.SchemaError
+
+// Table definition aliases
+type TableDefinition = ddl.Table
+type ColumnDefinition = ddl.Column
+type TableDistribution = ddl.Distribution
+type TablePartition = ddl.Partition
+type KeysType = ddl.KeysType
+type EnsureTableOptions = client.EnsureTableOptions
+
+// Dead-letter aliases
+type DeadLetterSink = deadletter.Sink
+type DeadLetterRecord = deadletter.Record
+type DirDeadLetterSink = deadletter.DirSink
+type ReplayResult = client.ReplayResult
+
+// Async load aliases
+type WorkerPool = config.WorkerPool
+type LoadFuture = client.LoadFuture
+type LoadResult = client.LoadResult
+
+// Throttling aliases
+type RateLimit = config.RateLimit
+type ThrottleMetrics = client.ThrottleMetrics
+
+// Backpressure aliases
+type Backpressure = config.Backpressure
+type BackpressureState = client.BackpressureState
+
+// Multi-table aliases
+type TableOptions = client.TableOptions
+type TableSink = client.TableSink
+type TableMetrics = client.TableMetrics
+type MultiTableWriter = client.MultiTableWriter
+type MultiTableResult = client.MultiTableResult
+type MultiTableRows = client.MultiTableRows
+
+// Spool aliases
+type Spool = client.Spool
+type SpoolOptions = client.SpoolOptions
+type SpoolStats = client.SpoolStats
+
+// Tail loader aliases
+type TailLoader = client.TailLoader
+type TailOptions = client.TailOptions
+type TailStats = client.TailStats
+
+// Directory import aliases
+type ImportOptions = client.ImportOptions
+type ImportChunk = client.ImportChunk
+type ImportFileReport = client.ImportFileReport
+type ImportReport = client.ImportReport
+
+// SQL copy aliases
+type SQLCopyOptions = sqlsource.Options
+type SQLCopyProgress = sqlsource.Progress
+
+// Row encoder aliases
+type Encoder = encoder.Encoder
+type EncoderDate = encoder.Date
 
 // ================================
 // Constants
 // ================================
 
+var (
+	// Async load errors
+	ErrQueueFull      = client.ErrQueueFull
+	ErrClientShutdown = client.ErrClientShutdown
+
+	// Spool errors
+	ErrSpoolFull   = client.ErrSpoolFull
+	ErrSpoolClosed = client.ErrSpoolClosed
+
+	// Tail loader errors
+	ErrTailClosed = client.ErrTailClosed
+
+	// Row encoder errors
+	ErrNeedsEnclose = encoder.ErrNeedsEnclose
+
+	// Config options for NewConfig; the options of the load itself are also accepted per call by Load as LoadOption
+	WithEndpoints          = config.WithEndpoints
+	WithAuth               = config.WithAuth
+	WithCredentials        = config.WithCredentials
+	WithAllowEmptyPassword = config.WithAllowEmptyPassword
+	WithDatabase           = config.WithDatabase
+	WithTable              = config.WithTable
+	WithCSV                = config.WithCSV
+	WithJSON               = config.WithJSON
+	WithFormat             = config.WithFormat
+	WithLabel              = config.WithLabel
+	WithLabelPrefix        = config.WithLabelPrefix
+	WithGroupCommit        = config.WithGroupCommit
+	WithRetry              = config.WithRetry
+	WithOption             = config.WithOption
+	WithOptions            = config.WithOptions
+	WithHeaders            = config.WithHeaders
+	WithPartitions         = config.WithPartitions
+	WithColumns            = config.WithColumns
+	WithErrorLogRows       = config.WithErrorLogRows
+	WithSchemaValidation   = config.WithSchemaValidation
+	WithDeadLetter         = config.WithDeadLetter
+	WithWorkerPool         = config.WithWorkerPool
+	WithRateLimit          = config.WithRateLimit
+	WithBackpressure       = config.WithBackpressure
+	WithTransport          = config.WithTransport
+
+	// Separator helpers
+	EscapeDelimiter   = config.EscapeDelimiter
+	UnescapeDelimiter = config.UnescapeDelimiter
+)
+
 const (
 	// JSON format constants
 	JSONObjectLine = config.JSONObjectLine
@@ -64,6 +191,11 @@ const (
 	SUCCESS = loader.SUCCESS
 	FAILURE = loader.FAILURE
 
+	// Table data models
+	DuplicateKeys = ddl.Duplicate
+	UniqueKeys    = ddl.Unique
+	AggregateKeys = ddl.Aggregate
+
 	// Log level constants
 	LogLevelDebug = log.LevelDebug
 	LogLevelInfo  = log.LevelInfo
@@ -80,6 +212,89 @@ func NewLoadClient(cfg *Config) (*DorisLoadClient, error) {
 	return client.NewDorisClient(cfg)
 }
 
+// NewConfig creates a validated configuration from options with sane defaults
+// All validation errors are reported together in a *ValidationError
+func NewConfig(opts ...Option) (*Config, error) {
+	return config.NewConfig(opts...)
+}
+
+// LoadConfigFile reads a configuration from a YAML or JSON file
+// String values may reference environment variables as ${NAME} or ${NAME:-default}
+func LoadConfigFile(path string) (*Config, error) {
+	return config.LoadConfigFile(path)
+}
+
+// ConfigFromEnv builds a configuration from environment variables such as PREFIX_ENDPOINTS
+// Nested keys are separated by "__", e.g. PREFIX_RETRY__MAX_RETRY_TIMES
+func ConfigFromEnv(prefix string) (*Config, error) {
+	return config.ConfigFromEnv(prefix)
+}
+
+// NewStaticCredentials creates a credentials provider returning fixed credentials
+func NewStaticCredentials(user, password string) CredentialsProvider {
+	return config.NewStaticCredentials(user, password)
+}
+
+// NewEnvCredentials creates a credentials provider reading the given environment variables on every request
+func NewEnvCredentials(userVar, passwordVar string) CredentialsProvider {
+	return config.NewEnvCredentials(userVar, passwordVar)
+}
+
+// NewFileCredentials creates a credentials provider reading "user:password" from a file, reloaded when it changes
+func NewFileCredentials(path string) CredentialsProvider {
+	return config.NewFileCredentials(path)
+}
+
+// NewCallbackCredentials creates a credentials provider backed by fetch, called again after a 401 response
+func NewCallbackCredentials(fetch func() (Credentials, error)) CredentialsProvider {
+	return config.NewCallbackCredentials(fetch)
+}
+
+// NewDirDeadLetterSink creates a dead-letter sink storing failed batches under dir
+// Stored batches can be re-submitted with DorisLoadClient.Replay(dir)
+func NewDirDeadLetterSink(dir string) (*DirDeadLetterSink, error) {
+	return deadletter.NewDirSink(dir)
+}
+
+// NewSpool opens a durable local spool in front of the client
+// Batches written to the spool survive Doris outages and process restarts
+func NewSpool(c *DorisLoadClient, opts SpoolOptions) (*Spool, error) {
+	return client.NewSpool(c, opts)
+}
+
+// NewTailLoader starts following log files and loading their lines through the client
+// Offsets are checkpointed after each commit, so a restarted loader resumes where it left off
+func NewTailLoader(c *DorisLoadClient, opts TailOptions) (*TailLoader, error) {
+	return client.NewTailLoader(c, opts)
+}
+
+// TableFromStruct derives a table definition from the fields of a struct, see ddl.FromStruct for its doris tags
+func TableFromStruct(v any) (*TableDefinition, error) {
+	return ddl.FromStruct(v)
+}
+
+// TableFromJSON infers a table definition from up to maxRows JSON samples read from r
+func TableFromJSON(r io.Reader, maxRows int) (*TableDefinition, error) {
+	return ddl.FromJSON(r, maxRows)
+}
+
+// NewEncoder creates an encoder writing rows in format, keyed by columns for JSON
+func NewEncoder(format Format, columns ...string) (*Encoder, error) {
+	return encoder.New(format, columns...)
+}
+
+// CopyFromSQL runs query on db and loads the rows it returns through the client, in batches
+// encoded in the client format
+func CopyFromSQL(ctx context.Context, db *sql.DB, query string, c *DorisLoadClient, opts SQLCopyOptions) (*SQLCopyProgress, error) {
+	return sqlsource.CopyFromSQL(ctx, db, query, c, opts)
+}
+
+// FetchErrorLog downloads the error log at errorURL, the ErrorURL of a failed load, and parses up to
+// maxRows rejected rows (0 for all). The BE serves error logs without authentication.
+func FetchErrorLog(errorURL string, maxRows int) ([]RejectedRow, error) {
+	return loader.NewStreamLoader().FetchErrorLog(errorURL, maxRows)
+}
+
 // ================================
 // Retry Configuration
 // ================================
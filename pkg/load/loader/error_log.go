@@ -0,0 +1,125 @@
+package load
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/exception"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+const (
+	// maxErrorLogLineSize bounds a single line of the BE error log page
+	maxErrorLogLineSize = 1024 * 1024
+)
+
+// RejectedRow is a single row filtered by Doris, as reported by the ErrorURL page
+type RejectedRow struct {
+	Reason string // Why the row was rejected
+	Row    string // Original row content as seen by the BE
+}
+
+// String returns a compact representation of the rejected row
+func (r RejectedRow) String() string {
+	if r.Row == "" {
+		return r.Reason
+	}
+	return fmt.Sprintf("%s (row: %s)", r.Reason, r.Row)
+}
+
+// RejectedRowsError is returned by a failed load when rejected rows were fetched from ErrorURL
+type RejectedRowsError struct {
+	Status   string        // Status field of the stream load response
+	Message  string        // Message field of the stream load response
+	ErrorURL string        // URL the rows were fetched from
+	Rows     []RejectedRow // First rejected rows from the error log
+}
+
+// Error returns the error message including the fetched rejected rows
+func (e *RejectedRowsError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("load failed with status: %s", e.Status))
+	if e.Message != "" {
+		sb.WriteString(fmt.Sprintf(", message: %s", e.Message))
+	}
+	if len(e.Rows) > 0 {
+		sb.WriteString(fmt.Sprintf(", first %d rejected rows:", len(e.Rows)))
+		for _, row := range e.Rows {
+			sb.WriteString("\n  ")
+			sb.WriteString(row.String())
+		}
+	}
+	return sb.String()
+}
+
+// ParseErrorLog parses a Doris error log page into rejected rows
+// Lines look like "Reason: <reason>. src line [<row>]; ", maxRows <= 0 means no limit
+func ParseErrorLog(r io.Reader, maxRows int) ([]RejectedRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxErrorLogLineSize)
+
+	var rows []RejectedRow
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		rows = append(rows, parseErrorLogLine(line))
+		if maxRows > 0 && len(rows) >= maxRows {
+			return rows, nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return rows, fmt.Errorf("failed to read error log: %w", err)
+	}
+
+	return rows, nil
+}
+
+// parseErrorLogLine splits a single error log line into reason and original row
+func parseErrorLogLine(line string) RejectedRow {
+	line = strings.TrimPrefix(line, "Reason:")
+	line = strings.TrimSpace(line)
+
+	idx := strings.LastIndex(line, "src line [")
+	if idx < 0 {
+		return RejectedRow{Reason: strings.TrimRight(line, "; ")}
+	}
+
+	reason := strings.TrimRight(line[:idx], ".; ")
+	row := line[idx+len("src line ["):]
+	row = strings.TrimRight(row, "; ")
+	row = strings.TrimSuffix(row, "]")
+
+	return RejectedRow{Reason: reason, Row: row}
+}
+
+// FetchErrorLog downloads the error log behind errorURL and parses up to maxRows rejected rows
+func (s *StreamLoader) FetchErrorLog(errorURL string, maxRows int) ([]RejectedRow, error) {
+	if errorURL == "" {
+		return nil, fmt.Errorf("error url is empty")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, errorURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create error log request: %w", err)
+	}
+
+	log.Debugf("Fetching error log from %s", errorURL)
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch error log: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, exception.NewStreamLoadStatusError(resp.StatusCode, fmt.Sprintf("fetch error log error: %s", resp.Status))
+	}
+
+	return ParseErrorLog(resp.Body, maxRows)
+}
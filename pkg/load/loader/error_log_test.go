@@ -0,0 +1,69 @@
+package load
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const sampleErrorLog = `Reason: column(age) value is incorrect while strict mode is true, src value is abc. src line [3,Charlie,abc];
+Reason: actual column number in csv file is less than schema column number.actual number: 1, column separator: [,], line delimiter: [\n], schema column number: 3; . src line [4];
+
+Reason: no src line here;
+`
+
+func TestParseErrorLog(t *testing.T) {
+	rows, err := ParseErrorLog(strings.NewReader(sampleErrorLog), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d: %+v", len(rows), rows)
+	}
+
+	if rows[0].Row != "3,Charlie,abc" {
+		t.Errorf("unexpected row: %q", rows[0].Row)
+	}
+	if rows[0].Reason != "column(age) value is incorrect while strict mode is true, src value is abc" {
+		t.Errorf("unexpected reason: %q", rows[0].Reason)
+	}
+	if rows[1].Row != "4" || !strings.HasPrefix(rows[1].Reason, "actual column number") {
+		t.Errorf("unexpected second row: %+v", rows[1])
+	}
+	if rows[2].Row != "" || rows[2].Reason != "no src line here" {
+		t.Errorf("unexpected third row: %+v", rows[2])
+	}
+
+	limited, err := ParseErrorLog(strings.NewReader(sampleErrorLog), 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(limited) != 1 {
+		t.Errorf("expected 1 row with limit, got %d", len(limited))
+	}
+}
+
+func TestFetchErrorLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("file") != "error_log_1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(sampleErrorLog))
+	}))
+	defer server.Close()
+
+	loader := NewStreamLoader()
+	rows, err := loader.FetchErrorLog(server.URL+"/api/_load_error_log?file=error_log_1", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Errorf("expected 2 rows, got %d", len(rows))
+	}
+
+	if _, err := loader.FetchErrorLog(server.URL+"/api/_load_error_log?file=missing", 0); err == nil {
+		t.Errorf("expected error for missing error log")
+	}
+}
@@ -0,0 +1,56 @@
+package load
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/exception"
+)
+
+// QueryPattern is the FE HTTP SQL API executing a statement in a database
+const QueryPattern = "http://%s/api/query/default_cluster/%s"
+
+// queryResponse is the body returned by the HTTP SQL API
+type queryResponse struct {
+	Msg  string `json:"msg"`
+	Code int    `json:"code"`
+}
+
+// CreateQueryRequest creates the POST request executing stmt in database
+func CreateQueryRequest(cfg *config.Config, database, stmt string) (*http.Request, error) {
+	host, err := getNode(cfg.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(map[string]string{"stmt": stmt})
+	if err != nil {
+		return nil, err
+	}
+	queryURL := fmt.Sprintf(QueryPattern, host, url.PathEscape(database))
+	req, err := http.NewRequest(http.MethodPost, queryURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := setBasicAuth(cfg, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ExecuteQuery sends a request created by CreateQueryRequest, its result set if any is discarded
+func (s *StreamLoader) ExecuteQuery(req *http.Request) error {
+	var result queryResponse
+	if err := s.doJSON(req, "query", &result); err != nil {
+		return err
+	}
+	if result.Code != 0 {
+		return exception.NewStreamLoadError(fmt.Sprintf("query failed: %s", result.Msg))
+	}
+	return nil
+}
@@ -16,6 +16,16 @@ import (
 
 const (
 	StreamLoadPattern = "http://%s/api/%s/%s/_stream_load"
+
+	// MultiTableStreamLoadPattern is the stream load URL without a table, used when every
+	// line of the body starts with its target table as "table|row"
+	MultiTableStreamLoadPattern = "http://%s/api/%s/_stream_load"
+
+	// MultiTableHeader tells Doris the body routes each line to the table named in its prefix
+	MultiTableHeader = "multi_table"
+
+	// MultiTableSeparator separates the table name from the row in a multi-table stream
+	MultiTableSeparator = "|"
 )
 
 // getNode randomly selects an endpoint and returns the parsed host
@@ -47,17 +57,39 @@ func CreateStreamLoadRequest(cfg *config.Config, data io.Reader, attempt int) (*
 
 	// Construct the load URL
 	loadURL := fmt.Sprintf(StreamLoadPattern, host, cfg.Database, cfg.Table)
+	return newStreamLoadRequest(cfg, loadURL, data, attempt)
+}
 
+// CreateMultiTableStreamLoadRequest creates an HTTP PUT request loading one stream into several
+// tables of cfg.Database. cfg.Table is ignored; each line of data must be prefixed with
+// "table|" and the format options of cfg apply to every table.
+func CreateMultiTableStreamLoadRequest(cfg *config.Config, data io.Reader, attempt int) (*http.Request, error) {
+	host, err := getNode(cfg.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	loadURL := fmt.Sprintf(MultiTableStreamLoadPattern, host, cfg.Database)
+	req, err := newStreamLoadRequest(cfg, loadURL, data, attempt)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(MultiTableHeader, "true")
+	return req, nil
+}
+
+// newStreamLoadRequest creates the stream load PUT request for loadURL with auth, options and label headers
+func newStreamLoadRequest(cfg *config.Config, loadURL string, data io.Reader, attempt int) (*http.Request, error) {
 	// Create the HTTP PUT request
 	req, err := http.NewRequest(http.MethodPut, loadURL, data)
 	if err != nil {
 		return nil, err
 	}
 
-	// Add basic authentication
-	authInfo := fmt.Sprintf("%s:%s", cfg.User, cfg.Password)
-	encodedAuth := base64.StdEncoding.EncodeToString([]byte(authInfo))
-	req.Header.Set("Authorization", "Basic "+encodedAuth)
+	// Add basic authentication with the current credentials
+	if err := setBasicAuth(cfg, req); err != nil {
+		return nil, err
+	}
 
 	// Add common headers
 	req.Header.Set("Expect", "100-continue")
@@ -74,6 +106,18 @@ func CreateStreamLoadRequest(cfg *config.Config, data io.Reader, attempt int) (*
 	return req, nil
 }
 
+// setBasicAuth sets the Authorization header of req from the current credentials of cfg
+func setBasicAuth(cfg *config.Config, req *http.Request) error {
+	creds, err := cfg.ResolveCredentials()
+	if err != nil {
+		return fmt.Errorf("failed to get credentials: %w", err)
+	}
+	authInfo := fmt.Sprintf("%s:%s", creds.User, creds.Password)
+	encodedAuth := base64.StdEncoding.EncodeToString([]byte(authInfo))
+	req.Header.Set("Authorization", "Basic "+encodedAuth)
+	return nil
+}
+
 // handleLabelForRequest handles label generation and setting based on group commit configuration
 func handleLabelForRequest(cfg *config.Config, req *http.Request, allOptions map[string]string, attempt int) {
 	// Check if group commit is enabled
@@ -0,0 +1,286 @@
+package load
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/exception"
+)
+
+// SchemaPattern is the FE API returning the columns of a table
+const SchemaPattern = "http://%s/api/%s/%s/_schema"
+
+// Column is a column of a Doris table as reported by the _schema API
+type Column struct {
+	Name        string
+	Type        string // Primitive type, e.g. "INT", "VARCHAR" or "DECIMAL128"
+	Precision   int    // Precision of decimal columns, 0 otherwise
+	Scale       int    // Scale of decimal columns, 0 otherwise
+	Nullable    bool
+	Key         bool   // Part of the table key
+	Aggregation string // Aggregation type of value columns of AGGREGATE and UNIQUE tables, e.g. "SUM" or "REPLACE"
+	Comment     string
+}
+
+// TableSchema describes the columns of a Doris table
+type TableSchema struct {
+	Database string
+	Table    string
+	KeysType string // "DUP_KEYS", "UNIQUE_KEYS" or "AGG_KEYS"
+	Columns  []Column
+}
+
+// SchemaError lists why loaded columns do not match a table
+type SchemaError struct {
+	Database string
+	Table    string
+	Problems []string
+}
+
+// Error returns all problems separated by semicolons
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("columns do not match %s.%s: %s", e.Database, e.Table, strings.Join(e.Problems, "; "))
+}
+
+// schemaResponse is the body returned by _schema
+type schemaResponse struct {
+	Msg  string `json:"msg"`
+	Code int    `json:"code"`
+	Data *struct {
+		KeysType   string             `json:"keysType"`
+		Properties []schemaProperties `json:"properties"`
+	} `json:"data"`
+}
+
+// schemaProperties describes a column in a _schema response, Doris reports every field as a string
+type schemaProperties struct {
+	Name            string `json:"name"`
+	Type            string `json:"type"`
+	Precision       string `json:"precision"`
+	Scale           string `json:"scale"`
+	IsNullable      string `json:"is_nullable"`
+	IsKey           string `json:"is_key"`
+	AggregationType string `json:"aggregation_type"`
+	Comment         string `json:"comment"`
+}
+
+// CreateSchemaRequest creates the GET request asking the columns of database.table
+func CreateSchemaRequest(cfg *config.Config, database, table string) (*http.Request, error) {
+	host, err := getNode(cfg.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaURL := fmt.Sprintf(SchemaPattern, host, url.PathEscape(database), url.PathEscape(table))
+	req, err := http.NewRequest(http.MethodGet, schemaURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := setBasicAuth(cfg, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// GetSchema sends a request created by CreateSchemaRequest and returns the schema of the table
+func (s *StreamLoader) GetSchema(req *http.Request, database, table string) (*TableSchema, error) {
+	var result schemaResponse
+	if err := s.doJSON(req, "get schema", &result); err != nil {
+		return nil, err
+	}
+	if result.Code != 0 || result.Data == nil {
+		return nil, exception.NewStreamLoadError(fmt.Sprintf("get schema of %s.%s failed: %s", database, table, result.Msg))
+	}
+
+	schema := &TableSchema{Database: database, Table: table, KeysType: result.Data.KeysType}
+	for _, p := range result.Data.Properties {
+		column := Column{
+			Name:        p.Name,
+			Type:        p.Type,
+			Nullable:    isYes(p.IsNullable),
+			Key:         isYes(p.IsKey),
+			Aggregation: p.AggregationType,
+			Comment:     p.Comment,
+		}
+		if strings.EqualFold(column.Aggregation, "NONE") {
+			column.Aggregation = ""
+		}
+		// Versions without is_key still tell the keys of aggregate tables apart
+		if p.IsKey == "" && schema.KeysType == "AGG_KEYS" && column.Aggregation == "" {
+			column.Key = true
+		}
+		column.Precision, _ = strconv.Atoi(p.Precision)
+		column.Scale, _ = strconv.Atoi(p.Scale)
+		schema.Columns = append(schema.Columns, column)
+	}
+	return schema, nil
+}
+
+// isYes parses the "Yes"/"No" flags of _schema
+func isYes(flag string) bool {
+	return strings.EqualFold(flag, "yes") || strings.EqualFold(flag, "true")
+}
+
+// Column returns the column named name, compared case-insensitively like Doris does
+func (s *TableSchema) Column(name string) (Column, bool) {
+	name = strings.Trim(strings.TrimSpace(name), "`")
+	for _, column := range s.Columns {
+		if strings.EqualFold(column.Name, name) {
+			return column, true
+		}
+	}
+	return Column{}, false
+}
+
+// ValidateColumns checks a columns mapping, as set by config.WithColumns or the columns header,
+// against the table
+// Targets of expressions like "v=tmp*2" must be columns of the table; other names must be columns
+// too unless an expression uses them as temporary columns.
+func (s *TableSchema) ValidateColumns(columns ...string) error {
+	var names, expressions, problems []string
+	for _, entry := range splitColumns(strings.Join(columns, ",")) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		target, expression, isExpression := strings.Cut(entry, "=")
+		if !isExpression {
+			names = append(names, entry)
+			continue
+		}
+		expressions = append(expressions, expression)
+		target = strings.Trim(strings.TrimSpace(target), "`")
+		if _, ok := s.Column(target); !ok {
+			problems = append(problems, fmt.Sprintf("unknown column %q", target))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.Trim(name, "`")
+		key := strings.ToLower(name)
+		if seen[key] {
+			problems = append(problems, fmt.Sprintf("duplicate column %q", name))
+		}
+		seen[key] = true
+		if _, ok := s.Column(name); !ok && !usedBy(name, expressions) {
+			problems = append(problems, fmt.Sprintf("unknown column %q", name))
+		}
+	}
+	return s.schemaError(problems)
+}
+
+// ValidateHeader checks a CSV header line, its names separated by separator, against the table
+func (s *TableSchema) ValidateHeader(header, separator string) error {
+	header = strings.TrimRight(header, "\r\n")
+	if header == "" {
+		return s.schemaError([]string{"empty header"})
+	}
+	return s.ValidateColumns(strings.Split(header, separator)...)
+}
+
+// ValidateStruct checks that the fields of a struct, named by their json tags like JSON loads do,
+// are columns of the table; v may be a struct, a pointer to one or a slice of them
+func (s *TableSchema) ValidateStruct(v any) error {
+	t := reflect.TypeOf(v)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return fmt.Errorf("expected a struct, got %T", v)
+	}
+	return s.ValidateColumns(jsonFieldNames(t)...)
+}
+
+// jsonFieldNames returns the keys encoding/json writes for the fields of struct type t
+func jsonFieldNames(t reflect.Type) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				names = append(names, jsonFieldNames(embedded)...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// splitColumns splits a columns header at the commas outside parentheses and quotes
+func splitColumns(columns string) []string {
+	var entries []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(columns); i++ {
+		switch c := columns[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			entries = append(entries, columns[start:i])
+			start = i + 1
+		}
+	}
+	return append(entries, columns[start:])
+}
+
+// usedBy reports whether name appears as an identifier in one of expressions
+func usedBy(name string, expressions []string) bool {
+	isIdent := func(b byte) bool {
+		return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+	}
+	lower := strings.ToLower(name)
+	for _, expression := range expressions {
+		expression = strings.ToLower(expression)
+		for start := 0; ; {
+			i := strings.Index(expression[start:], lower)
+			if i < 0 {
+				break
+			}
+			i += start
+			end := i + len(lower)
+			if (i == 0 || !isIdent(expression[i-1])) && (end == len(expression) || !isIdent(expression[end])) {
+				return true
+			}
+			start = i + 1
+		}
+	}
+	return false
+}
+
+// schemaError returns a SchemaError for problems, or nil when there are none
+func (s *TableSchema) schemaError(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return &SchemaError{Database: s.Database, Table: s.Table, Problems: problems}
+}
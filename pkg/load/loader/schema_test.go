@@ -0,0 +1,27 @@
+package load
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateColumns(t *testing.T) {
+	schema := &TableSchema{Database: "db", Table: "t", Columns: []Column{{Name: "id"}, {Name: "name"}, {Name: "day"}}}
+
+	valid := [][]string{
+		{"id", "Name"},
+		{"`id`", "tmp_day", "day=str_to_date(tmp_day, '%Y,%m,%d')"},
+		{"id,name,x,day=if(x = 1, now(), null)"},
+	}
+	for _, columns := range valid {
+		if err := schema.ValidateColumns(columns...); err != nil {
+			t.Errorf("unexpected error for %q: %v", columns, err)
+		}
+	}
+
+	err := schema.ValidateColumns("id", "id", "tmp", "age=tmp2+1")
+	schemaErr, ok := err.(*SchemaError)
+	if !ok || strings.Join(schemaErr.Problems, "; ") != `unknown column "age"; duplicate column "id"; unknown column "tmp"` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -22,8 +22,13 @@ type StreamLoader struct {
 
 // NewStreamLoader creates a new StreamLoader
 func NewStreamLoader() *StreamLoader {
+	return NewStreamLoaderWithClient(util.GetHttpClient())
+}
+
+// NewStreamLoaderWithClient creates a StreamLoader sending requests through httpClient
+func NewStreamLoaderWithClient(httpClient *http.Client) *StreamLoader {
 	return &StreamLoader{
-		httpClient: util.GetHttpClient(),
+		httpClient: httpClient,
 		json:       jsoniter.ConfigCompatibleWithStandardLibrary,
 	}
 }
@@ -99,7 +104,7 @@ func (s *StreamLoader) handleResponse(resp *http.Response) (*LoadResponse, error
 	// For non-200 status codes, return an error that can be retried
 	log.Errorf("Stream load failed with HTTP status: %s", resp.Status)
 
-	return nil, exception.NewStreamLoadError(fmt.Sprintf("stream load error: %s", resp.Status))
+	return nil, exception.NewStreamLoadStatusError(statusCode, fmt.Sprintf("stream load error: %s", resp.Status))
 }
 
 // isSuccessStatus checks if the status indicates success
@@ -0,0 +1,130 @@
+package load
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/exception"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+const (
+	// LoadStatePattern is the FE API returning the state of the load with a label
+	LoadStatePattern = "http://%s/api/%s/get_load_state?label=%s"
+
+	// TwoPhaseCommitPattern is the FE API committing or aborting a precommitted stream load
+	TwoPhaseCommitPattern = "http://%s/api/%s/_stream_load_2pc"
+)
+
+// TxnOperation is the operation applied to a two-phase commit transaction
+type TxnOperation string
+
+const (
+	TxnCommit TxnOperation = "commit"
+	TxnAbort  TxnOperation = "abort"
+)
+
+// loadStateResponse is the body returned by get_load_state
+type loadStateResponse struct {
+	Msg  string `json:"msg"`
+	Code int    `json:"code"`
+	Data string `json:"data"`
+}
+
+// twoPhaseCommitResponse is the body returned by _stream_load_2pc
+type twoPhaseCommitResponse struct {
+	Status string `json:"status"`
+	Msg    string `json:"msg"`
+}
+
+// CreateLoadStateRequest creates the GET request asking the state of the load labeled label in cfg.Database
+func CreateLoadStateRequest(cfg *config.Config, label string) (*http.Request, error) {
+	host, err := getNode(cfg.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	stateURL := fmt.Sprintf(LoadStatePattern, host, url.PathEscape(cfg.Database), url.QueryEscape(label))
+	req, err := http.NewRequest(http.MethodGet, stateURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := setBasicAuth(cfg, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// CreateTwoPhaseCommitRequest creates the PUT request applying operation to transaction txnID of cfg.Database
+func CreateTwoPhaseCommitRequest(cfg *config.Config, txnID int64, operation TxnOperation) (*http.Request, error) {
+	host, err := getNode(cfg.Endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	commitURL := fmt.Sprintf(TwoPhaseCommitPattern, host, url.PathEscape(cfg.Database))
+	req, err := http.NewRequest(http.MethodPut, commitURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := setBasicAuth(cfg, req); err != nil {
+		return nil, err
+	}
+	req.Header.Set("txn_id", strconv.FormatInt(txnID, 10))
+	req.Header.Set("txn_operation", string(operation))
+	return req, nil
+}
+
+// GetLoadState sends a request created by CreateLoadStateRequest and returns the reported state,
+// e.g. "VISIBLE", "ABORTED" or "UNKNOWN" for labels Doris does not know
+func (s *StreamLoader) GetLoadState(req *http.Request) (string, error) {
+	var state loadStateResponse
+	if err := s.doJSON(req, "get load state", &state); err != nil {
+		return "", err
+	}
+	if state.Code != 0 {
+		return "", exception.NewStreamLoadError(fmt.Sprintf("get load state failed: %s", state.Msg))
+	}
+	return state.Data, nil
+}
+
+// TwoPhaseCommit sends a request created by CreateTwoPhaseCommitRequest and returns the message of Doris
+func (s *StreamLoader) TwoPhaseCommit(req *http.Request) (string, error) {
+	var result twoPhaseCommitResponse
+	if err := s.doJSON(req, "two-phase commit", &result); err != nil {
+		return "", err
+	}
+	if !isSuccessStatus(result.Status) {
+		return "", exception.NewStreamLoadError(fmt.Sprintf("%s transaction failed: %s", req.Header.Get("txn_operation"), result.Msg))
+	}
+	return result.Msg, nil
+}
+
+// doJSON executes req and decodes its JSON response into v
+func (s *StreamLoader) doJSON(req *http.Request, operation string, v interface{}) error {
+	log.Debugf("Sending %s request to %s", operation, req.URL.Redacted())
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute %s request: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return fmt.Errorf("failed to read %s response: %w", operation, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return exception.NewStreamLoadStatusError(resp.StatusCode,
+			fmt.Sprintf("%s error: %s %s", operation, resp.Status, strings.TrimSpace(string(body))))
+	}
+
+	if err := s.json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal %s response: %w", operation, err)
+	}
+	return nil
+}
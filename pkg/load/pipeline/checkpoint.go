@@ -0,0 +1,89 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/util"
+)
+
+// Checkpoint is the progress of a pipeline
+type Checkpoint struct {
+	Offset  string      `json:"offset"`            // Offset of the last record committed in Doris
+	Pending *PendingTxn `json:"pending,omitempty"` // Transaction prepared but maybe not committed
+}
+
+// PendingTxn is a prepared two-phase commit transaction carrying the records up to Offset
+type PendingTxn struct {
+	Offset string `json:"offset"`
+	Label  string `json:"label"`
+	TxnID  int64  `json:"txn_id"`
+}
+
+// Checkpointer persists the checkpoint of a pipeline
+type Checkpointer interface {
+	// Load returns the saved checkpoint, or a zero Checkpoint if none was saved
+	Load(ctx context.Context) (Checkpoint, error)
+	// Save durably replaces the checkpoint
+	Save(ctx context.Context, checkpoint Checkpoint) error
+}
+
+// FileCheckpointer keeps the checkpoint in a JSON file
+type FileCheckpointer struct {
+	path string
+}
+
+// NewFileCheckpointer creates a checkpointer writing to path
+func NewFileCheckpointer(path string) *FileCheckpointer {
+	return &FileCheckpointer{path: path}
+}
+
+// Load reads the checkpoint file
+func (f *FileCheckpointer) Load(ctx context.Context) (Checkpoint, error) {
+	var checkpoint Checkpoint
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return checkpoint, fmt.Errorf("invalid checkpoint file %s: %w", f.path, err)
+	}
+	return checkpoint, nil
+}
+
+// Save atomically replaces the checkpoint file through a synced temporary file
+func (f *FileCheckpointer) Save(ctx context.Context, checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	return util.WriteFileAtomic(f.path, data)
+}
+
+// MemoryCheckpointer keeps the checkpoint in memory, for tests and sources that track their own position
+type MemoryCheckpointer struct {
+	mu         sync.Mutex
+	checkpoint Checkpoint
+}
+
+// Load returns the last saved checkpoint
+func (m *MemoryCheckpointer) Load(ctx context.Context) (Checkpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.checkpoint, nil
+}
+
+// Save replaces the checkpoint
+func (m *MemoryCheckpointer) Save(ctx context.Context, checkpoint Checkpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoint = checkpoint
+	return nil
+}
@@ -0,0 +1,74 @@
+package pipeline
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+)
+
+// FileSource reads the lines of a file, its offsets being byte positions
+type FileSource struct {
+	path   string
+	file   *os.File
+	reader *bufio.Reader
+	offset int64
+}
+
+// NewFileSource creates a source reading the lines of the file at path
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+// Open opens the file and seeks to offset
+func (f *FileSource) Open(ctx context.Context, offset string) error {
+	if offset != "" {
+		position, err := strconv.ParseInt(offset, 10, 64)
+		if err != nil || position < 0 {
+			return fmt.Errorf("invalid file offset %q", offset)
+		}
+		f.offset = position
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		file.Close()
+		return err
+	}
+	f.file = file
+	f.reader = bufio.NewReaderSize(file, 64*1024)
+	return nil
+}
+
+// Next returns the next line without its "\n" (and "\r"), or io.EOF at the end of the file
+func (f *FileSource) Next(ctx context.Context) (Record, error) {
+	line, err := f.reader.ReadBytes('\n')
+	if len(line) == 0 {
+		if err == nil {
+			err = io.EOF
+		}
+		return Record{}, err
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Record{}, err
+	}
+
+	f.offset += int64(len(line))
+	line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+	return Record{Data: line, Offset: strconv.FormatInt(f.offset, 10)}, nil
+}
+
+// Close closes the file
+func (f *FileSource) Close() error {
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close()
+}
@@ -0,0 +1,227 @@
+// Package pipeline runs ingestion jobs reading records from a Source, transforming them and
+// loading them into Doris in batches, with the source offsets saved by a Checkpointer
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+// Record is one row read from a source
+type Record struct {
+	Data   []byte // The row in the client format, without line delimiter
+	Offset string // Position in the source after this record, as understood by the source
+}
+
+// Source yields records in order
+// A Source is used by a single goroutine.
+type Source interface {
+	// Open positions the source after offset, or at its start if offset is ""
+	Open(ctx context.Context, offset string) error
+	// Next blocks until a record is available or ctx ends; it returns io.EOF at the end of a finite source
+	Next(ctx context.Context) (Record, error)
+	// Close releases the source
+	Close() error
+}
+
+// Transform rewrites a record, or drops it by returning false
+// The Offset of the returned record is ignored, the record keeps its position in the source.
+type Transform func(Record) (Record, bool, error)
+
+// Stats is a snapshot of a pipeline run
+type Stats struct {
+	Records      int64  // Records read from the source
+	Dropped      int64  // Records dropped by transforms
+	Batches      int64  // Batches committed
+	LoadedRows   int64  // Rows Doris loaded
+	FilteredRows int64  // Rows Doris filtered out
+	Offset       string // Offset of the last committed record
+}
+
+// Pipeline moves records from a Source through transforms into a DorisSink
+// The offset of the last record of a batch is saved by the Checkpointer only after the batch
+// is committed in Doris, and a restarted pipeline resumes from the saved offset: records are
+// loaded at least once. With SinkOptions.TwoPhaseCommit the batch is prepared first, its
+// transaction is saved with the offset and only then committed, so a restart finishes or
+// discards it and records are loaded exactly once.
+type Pipeline struct {
+	source       Source
+	transforms   []Transform
+	sink         *DorisSink
+	checkpointer Checkpointer
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// item is a record or the error ending the source
+type item struct {
+	record Record
+	err    error
+}
+
+// New creates a pipeline; transforms are applied in order
+func New(source Source, sink *DorisSink, checkpointer Checkpointer, transforms ...Transform) *Pipeline {
+	return &Pipeline{source: source, transforms: transforms, sink: sink, checkpointer: checkpointer}
+}
+
+// Run resumes from the checkpoint and moves records until the source ends, a load fails or ctx ends
+// The records of a finite source are all committed when Run returns nil.
+func (p *Pipeline) Run(ctx context.Context) error {
+	checkpoint, err := p.checkpointer.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	offset, err := p.sink.recover(ctx, p.checkpointer, checkpoint)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.stats.Offset = offset
+	p.mu.Unlock()
+
+	if err := p.source.Open(ctx, offset); err != nil {
+		return fmt.Errorf("failed to open source: %w", err)
+	}
+	defer p.source.Close()
+
+	readCtx, cancel := context.WithCancel(ctx)
+	items := make(chan item, 1024)
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		p.read(readCtx, items)
+	}()
+	defer func() {
+		// The reader may be inside Source.Next, it must return before the source is closed
+		cancel()
+		<-readerDone
+	}()
+
+	flushInterval := time.Duration(p.sink.opts.FlushIntervalMs) * time.Millisecond
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := p.sink.newBatch(offset)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-ticker.C:
+			// A batch whose records were all dropped still moves the checkpoint
+			if batch.offset != batch.committed && (batch.rows == 0 || time.Since(batch.started) >= flushInterval) {
+				if err := p.commit(ctx, batch); err != nil {
+					return err
+				}
+				batch = p.sink.newBatch(batch.offset)
+			}
+
+		case it := <-items:
+			if it.err != nil {
+				if err := p.commit(ctx, batch); err != nil {
+					return err
+				}
+				if errors.Is(it.err, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("failed to read source: %w", it.err)
+			}
+
+			record, keep, err := p.transform(it.record)
+			if err != nil {
+				return fmt.Errorf("failed to transform record at %s: %w", it.record.Offset, err)
+			}
+			if keep {
+				batch.add(record.Data)
+			}
+			batch.offset = it.record.Offset
+
+			if batch.full() {
+				if err := p.commit(ctx, batch); err != nil {
+					return err
+				}
+				batch = p.sink.newBatch(batch.offset)
+			}
+		}
+	}
+}
+
+// Stats returns a snapshot of the pipeline progress
+func (p *Pipeline) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+// read feeds the records of the source to items until it fails or ctx ends
+func (p *Pipeline) read(ctx context.Context, items chan<- item) {
+	for {
+		record, err := p.source.Next(ctx)
+		select {
+		case items <- item{record: record, err: err}:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// transform applies the transforms to a record
+func (p *Pipeline) transform(record Record) (Record, bool, error) {
+	p.mu.Lock()
+	p.stats.Records++
+	p.mu.Unlock()
+
+	offset := record.Offset
+	for _, t := range p.transforms {
+		var keep bool
+		var err error
+		if record, keep, err = t(record); err != nil || !keep {
+			if err == nil {
+				p.mu.Lock()
+				p.stats.Dropped++
+				p.mu.Unlock()
+			}
+			return record, false, err
+		}
+	}
+	record.Offset = offset
+	return record, true, nil
+}
+
+// commit loads a batch and saves its offset
+// A batch whose records were all dropped only moves the offset.
+func (p *Pipeline) commit(ctx context.Context, b *batch) error {
+	if b.offset == b.committed {
+		return nil
+	}
+
+	if b.rows > 0 {
+		response, err := p.sink.load(ctx, p.checkpointer, b)
+		if err != nil {
+			return err
+		}
+		p.mu.Lock()
+		p.stats.Batches++
+		p.stats.LoadedRows += response.Resp.NumberLoadedRows
+		p.stats.FilteredRows += int64(response.Resp.NumberFilteredRows)
+		p.mu.Unlock()
+	} else if err := p.checkpointer.Save(ctx, Checkpoint{Offset: b.offset}); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	p.mu.Lock()
+	p.stats.Offset = b.offset
+	p.mu.Unlock()
+	log.Debugf("Pipeline committed %d rows up to offset %s", b.rows, b.offset)
+	return nil
+}
@@ -0,0 +1,213 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+// writeLines writes lines to a new file in a temporary directory
+func writeLines(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "input.csv")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// chanSource yields the records sent on its channel until the context ends
+type chanSource struct {
+	records      chan Record
+	inNext       int32
+	closedInNext bool
+}
+
+func (s *chanSource) Open(ctx context.Context, offset string) error { return nil }
+
+func (s *chanSource) Next(ctx context.Context) (Record, error) {
+	atomic.StoreInt32(&s.inNext, 1)
+	defer atomic.StoreInt32(&s.inNext, 0)
+	select {
+	case record := <-s.records:
+		return record, nil
+	case <-ctx.Done():
+		// A slow source, Close must still wait for it
+		time.Sleep(50 * time.Millisecond)
+		return Record{}, ctx.Err()
+	}
+}
+
+func (s *chanSource) Close() error {
+	s.closedInNext = atomic.LoadInt32(&s.inNext) == 1
+	return nil
+}
+
+func TestPipelineResumesFromCheckpoint(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t)
+
+	path := writeLines(t, "1,alice", "#comment", "2,bob", "3,carol")
+	checkpointer := NewFileCheckpointer(filepath.Join(t.TempDir(), "checkpoint.json"))
+	sink, err := NewDorisSink(c, SinkOptions{BatchRows: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropComments := func(r Record) (Record, bool, error) { return r, !bytes.HasPrefix(r.Data, []byte("#")), nil }
+	upper := func(r Record) (Record, bool, error) {
+		r.Data = bytes.ToUpper(r.Data)
+		return r, true, nil
+	}
+
+	p := New(NewFileSource(path), sink, checkpointer, dropComments, upper)
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stats := p.Stats()
+	if stats.Records != 4 || stats.Dropped != 1 || stats.Batches != 2 || stats.LoadedRows != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if rows := server.Rows("test_db", "users"); strings.Join(rows, " ") != "1,ALICE 2,BOB 3,CAROL" {
+		t.Errorf("unexpected rows: %q", rows)
+	}
+
+	// A second run only loads what was appended
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("4,dave\n")
+	f.Close()
+
+	p = New(NewFileSource(path), sink, checkpointer)
+	if err := p.Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows := server.Rows("test_db", "users"); len(rows) != 4 || rows[3] != "4,dave" {
+		t.Errorf("unexpected rows after resume: %q", rows)
+	}
+	checkpoint, _ := checkpointer.Load(context.Background())
+	if checkpoint.Offset != "38" || checkpoint.Pending != nil {
+		t.Errorf("unexpected checkpoint %+v", checkpoint)
+	}
+}
+
+func TestPipelineSettlesPendingTransactions(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t)
+	path := writeLines(t, "1,alice", "2,bob", "3,carol")
+
+	// A previous run prepared the first two rows and stopped before committing them
+	response, err := c.Load(strings.NewReader("1,alice\n2,bob\n"), config.WithOption("two_phase_commit", "true"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpointer := &MemoryCheckpointer{}
+	checkpointer.Save(context.Background(), Checkpoint{
+		Pending: &PendingTxn{Offset: "14", Label: response.Resp.Label, TxnID: response.Resp.TxnID},
+	})
+
+	sink, err := NewDorisSink(c, SinkOptions{TwoPhaseCommit: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := New(NewFileSource(path), sink, checkpointer).Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows := server.Rows("test_db", "users"); strings.Join(rows, " ") != "1,alice 2,bob 3,carol" {
+		t.Errorf("expected each row once, got %q", rows)
+	}
+	for _, load := range server.Loads() {
+		if state := server.LoadState("test_db", load.Label); state != dorismock.StateVisible {
+			t.Errorf("load %s left %s", load.Label, state)
+		}
+	}
+
+	// An aborted transaction is loaded again from the committed offset
+	server.Reset()
+	response, err = c.Load(strings.NewReader("2,bob\n"), config.WithOption("two_phase_commit", "true"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AbortTransaction(response.Resp.TxnID); err != nil {
+		t.Fatal(err)
+	}
+	checkpointer.Save(context.Background(), Checkpoint{
+		Offset:  "8",
+		Pending: &PendingTxn{Offset: "14", Label: response.Resp.Label, TxnID: response.Resp.TxnID},
+	})
+	if err := New(NewFileSource(path), sink, checkpointer).Run(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rows := server.Rows("test_db", "users"); strings.Join(rows, " ") != "2,bob 3,carol" {
+		t.Errorf("expected the aborted rows to be reloaded, got %q", rows)
+	}
+}
+
+func TestPipelineStopsOnTransformError(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	sink, err := NewDorisSink(server.NewClient(t), SinkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failing := func(r Record) (Record, bool, error) { return r, false, errors.New("bad record") }
+	err = New(NewFileSource(writeLines(t, "1,alice")), sink, &MemoryCheckpointer{}, failing).Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "bad record") {
+		t.Errorf("expected the transform error, got %v", err)
+	}
+	if len(server.Loads()) != 0 {
+		t.Errorf("nothing should be loaded")
+	}
+}
+
+func TestPipelineCheckpointsDroppedRecords(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	sink, err := NewDorisSink(server.NewClient(t), SinkOptions{FlushIntervalMs: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := &chanSource{records: make(chan Record)}
+	checkpointer := &MemoryCheckpointer{}
+	dropAll := func(r Record) (Record, bool, error) { return r, false, nil }
+	p := New(source, sink, checkpointer, dropAll)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Run(ctx) }()
+
+	// The offset of dropped records is saved on the next tick, not only at the end of the source
+	source.records <- Record{Data: []byte("#comment"), Offset: "9"}
+	deadline := time.Now().Add(5 * time.Second)
+	for p.Stats().Offset != "9" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if checkpoint, err := checkpointer.Load(ctx); err != nil || checkpoint.Offset != "9" {
+		t.Errorf("expected the checkpoint at offset 9, got %+v (%v)", checkpoint, err)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the run to be canceled, got %v", err)
+	}
+	if source.closedInNext {
+		t.Error("the source was closed while the reader was still in Next")
+	}
+	if len(server.Loads()) != 0 {
+		t.Errorf("nothing should be loaded")
+	}
+}
@@ -0,0 +1,197 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	loader "github.com/bingquanzhao/go-doris-sdk/pkg/load/loader"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+const (
+	defaultBatchRows       = 100000
+	defaultBatchBytes      = 16 * 1024 * 1024
+	defaultFlushIntervalMs = 5000
+
+	// Load states reported by Doris for a label
+	stateCommitted    = "COMMITTED"
+	stateVisible      = "VISIBLE"
+	statePrecommitted = "PRECOMMITTED"
+)
+
+// SinkOptions configures a DorisSink
+type SinkOptions struct {
+	BatchRows       int                 // Rows at which a batch is loaded (default 100000)
+	BatchBytes      int64               // Size at which a batch is loaded (default 16MB)
+	FlushIntervalMs int64               // Maximum age of a batch before it is loaded (default 5000)
+	TwoPhaseCommit  bool                // Prepare batches and commit them after the checkpoint for exactly-once loads
+	LoadOpts        []client.LoadOption // Options applied to every batch
+}
+
+// DorisSink loads batches of records through a DorisLoadClient
+type DorisSink struct {
+	client    *client.DorisLoadClient
+	opts      SinkOptions
+	delimiter []byte
+	jsonArray bool
+}
+
+// batch collects the records loaded together
+type batch struct {
+	buf       bytes.Buffer
+	rows      int
+	started   time.Time
+	committed string // Offset saved before the batch
+	offset    string // Offset of the last record of the batch
+	maxRows   int
+	maxBytes  int64
+	delimiter []byte
+	jsonArray bool
+}
+
+// NewDorisSink creates a sink loading through c
+// Records are joined with the line delimiter of the client format, or into an array for JSON arrays.
+func NewDorisSink(c *client.DorisLoadClient, opts SinkOptions) (*DorisSink, error) {
+	cfg := c.Config()
+	if opts.TwoPhaseCommit && cfg.GroupCommit != config.OFF {
+		return nil, fmt.Errorf("two-phase commit requires group commit OFF")
+	}
+	if opts.BatchRows <= 0 {
+		opts.BatchRows = defaultBatchRows
+	}
+	if opts.BatchBytes <= 0 {
+		opts.BatchBytes = defaultBatchBytes
+	}
+	if opts.FlushIntervalMs <= 0 {
+		opts.FlushIntervalMs = defaultFlushIntervalMs
+	}
+
+	s := &DorisSink{client: c, opts: opts, delimiter: []byte("\n")}
+	switch format := cfg.Format.(type) {
+	case *config.CSVFormat:
+		s.delimiter = []byte(config.UnescapeDelimiter(format.LineDelimiter))
+	case *config.JSONFormat:
+		s.jsonArray = format.Type == config.JSONArray
+		if s.jsonArray {
+			s.delimiter = []byte(",")
+		}
+	}
+	return s, nil
+}
+
+// newBatch starts a batch after offset
+func (s *DorisSink) newBatch(offset string) *batch {
+	return &batch{
+		committed: offset,
+		offset:    offset,
+		maxRows:   s.opts.BatchRows,
+		maxBytes:  s.opts.BatchBytes,
+		delimiter: s.delimiter,
+		jsonArray: s.jsonArray,
+	}
+}
+
+// add appends a row
+func (b *batch) add(row []byte) {
+	if b.rows == 0 {
+		b.started = time.Now()
+		if b.jsonArray {
+			b.buf.WriteByte('[')
+		}
+	} else if b.jsonArray {
+		b.buf.Write(b.delimiter)
+	}
+	b.buf.Write(row)
+	if !b.jsonArray {
+		b.buf.Write(b.delimiter)
+	}
+	b.rows++
+}
+
+// full reports whether the batch reached its size
+func (b *batch) full() bool {
+	return b.rows >= b.maxRows || int64(b.buf.Len()) >= b.maxBytes
+}
+
+// payload returns the body of the load
+func (b *batch) payload() []byte {
+	if b.jsonArray {
+		return append(b.buf.Bytes(), ']')
+	}
+	return b.buf.Bytes()
+}
+
+// load commits a batch in Doris and saves its offset with cp
+func (s *DorisSink) load(ctx context.Context, cp Checkpointer, b *batch) (*loader.LoadResponse, error) {
+	if !s.opts.TwoPhaseCommit {
+		response, err := s.client.LoadContext(ctx, bytes.NewReader(b.payload()), s.opts.LoadOpts...)
+		if err != nil {
+			return response, fmt.Errorf("failed to load batch ending at %s: %w", b.offset, err)
+		}
+		if err := cp.Save(ctx, Checkpoint{Offset: b.offset}); err != nil {
+			// The batch is loaded again after a restart, which at-least-once allows
+			return response, fmt.Errorf("failed to save checkpoint: %w", err)
+		}
+		return response, nil
+	}
+
+	opts := append([]client.LoadOption{config.WithOption("two_phase_commit", "true")}, s.opts.LoadOpts...)
+	response, err := s.client.LoadContext(ctx, bytes.NewReader(b.payload()), opts...)
+	if err != nil {
+		return response, fmt.Errorf("failed to prepare batch ending at %s: %w", b.offset, err)
+	}
+
+	// The prepared transaction is saved first so a restart knows whether to commit or replay it
+	pending := &PendingTxn{Offset: b.offset, Label: response.Resp.Label, TxnID: response.Resp.TxnID}
+	if err := cp.Save(ctx, Checkpoint{Offset: b.committed, Pending: pending}); err != nil {
+		if abortErr := s.client.AbortTransactionContext(ctx, pending.TxnID); abortErr != nil {
+			log.Warnf("Failed to abort transaction %d: %v", pending.TxnID, abortErr)
+		}
+		return response, fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := s.client.CommitTransactionContext(ctx, pending.TxnID); err != nil {
+		return response, fmt.Errorf("failed to commit transaction %d: %w", pending.TxnID, err)
+	}
+	if err := cp.Save(ctx, Checkpoint{Offset: b.offset}); err != nil {
+		// The restart finds the transaction visible and moves on
+		return response, fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return response, nil
+}
+
+// recover settles a transaction left pending by a previous run and returns the offset to resume from
+func (s *DorisSink) recover(ctx context.Context, cp Checkpointer, checkpoint Checkpoint) (string, error) {
+	pending := checkpoint.Pending
+	if pending == nil {
+		return checkpoint.Offset, nil
+	}
+
+	state, err := s.client.GetLoadStateContext(ctx, pending.Label)
+	if err != nil {
+		return "", fmt.Errorf("failed to get state of pending transaction %d: %w", pending.TxnID, err)
+	}
+
+	offset := checkpoint.Offset
+	switch state {
+	case statePrecommitted:
+		if err := s.client.CommitTransactionContext(ctx, pending.TxnID); err != nil {
+			return "", fmt.Errorf("failed to commit pending transaction %d: %w", pending.TxnID, err)
+		}
+		log.Infof("Committed transaction %d left pre-committed by the previous run", pending.TxnID)
+		offset = pending.Offset
+	case stateCommitted, stateVisible:
+		offset = pending.Offset
+	default:
+		// Aborted, e.g. by a timeout, or never prepared: its records are loaded again
+		log.Infof("Pending transaction %d is %s, reloading from offset %q", pending.TxnID, state, offset)
+	}
+
+	if err := cp.Save(ctx, Checkpoint{Offset: offset}); err != nil {
+		return "", fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return offset, nil
+}
@@ -0,0 +1,194 @@
+// Package sqlsource copies the result of a database/sql query into Doris through a DorisLoadClient
+package sqlsource
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/client"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/encoder"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/log"
+)
+
+const defaultBatchBytes = 16 * 1024 * 1024
+
+// Options configures CopyFromSQL
+type Options struct {
+	Args       []any               // Arguments of the query placeholders
+	BatchBytes int64               // Encoded size at which a batch is loaded (default 16MB)
+	OnProgress func(Progress)      // Called after each batch is loaded
+	LoadOpts   []client.LoadOption // Options applied to every batch, e.g. config.WithPartitions
+}
+
+// Progress reports how far a copy got
+type Progress struct {
+	Rows         int64         // Rows read from the query
+	Bytes        int64         // Encoded bytes sent to Doris
+	Batches      int64         // Batches loaded
+	LoadedRows   int64         // Rows Doris loaded
+	FilteredRows int64         // Rows Doris filtered out
+	Elapsed      time.Duration // Time since the copy started
+}
+
+// CopyFromSQL runs query on db and loads the rows it returns through c
+// Rows are encoded in the client format and loaded in batches of about opts.BatchBytes.
+// CSV columns are mapped to the table by the names the query returns, so the query decides
+// the column order, e.g. "SELECT id, name FROM users"; JSON rows are keyed by the same names.
+// Values are written by encoder.Encoder: NULL as \N in CSV and null in JSON, times as
+// "2006-01-02 15:04:05.999999" (dates without time for DATE columns), binary columns as hex
+// and numbers and decimals as the text the driver returns. Set CSVFormat.Enclose to copy text
+// containing the separator or line delimiter as CSV.
+func CopyFromSQL(ctx context.Context, db *sql.DB, query string, c *client.DorisLoadClient, opts Options) (*Progress, error) {
+	if opts.BatchBytes <= 0 {
+		opts.BatchBytes = defaultBatchBytes
+	}
+
+	rows, err := db.QueryContext(ctx, query, opts.Args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result columns: %w", err)
+	}
+	names := make([]string, len(columnTypes))
+	for i, col := range columnTypes {
+		names[i] = col.Name()
+	}
+	format := c.Config().Format
+	enc, err := encoder.New(format, names...)
+	if err != nil {
+		return nil, err
+	}
+	defer enc.Release()
+
+	loadOpts := opts.LoadOpts
+	if _, ok := format.(*config.CSVFormat); ok {
+		// The query decides the column order, columns set by the caller take precedence
+		quoted := make([]string, len(names))
+		for i, name := range names {
+			quoted[i] = "`" + name + "`"
+		}
+		loadOpts = append([]client.LoadOption{config.WithColumns(quoted...)}, loadOpts...)
+	}
+
+	kinds := columnKinds(columnTypes)
+	copier := &copier{client: c, opts: opts, loadOpts: loadOpts, enc: enc, start: time.Now()}
+	values := make([]any, len(columnTypes))
+	pointers := make([]any, len(columnTypes))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return &copier.progress, fmt.Errorf("failed to read row %d: %w", copier.progress.Rows+1, err)
+		}
+		for i, value := range values {
+			values[i] = kinds[i].convert(value)
+		}
+		if err := enc.WriteRow(values...); err != nil {
+			return &copier.progress, fmt.Errorf("failed to encode row %d: %w", copier.progress.Rows+1, err)
+		}
+		copier.progress.Rows++
+
+		if int64(enc.Len()) >= opts.BatchBytes {
+			if err := copier.flush(ctx); err != nil {
+				return &copier.progress, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return &copier.progress, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	if err := copier.flush(ctx); err != nil {
+		return &copier.progress, err
+	}
+	log.Infof("Copied %d rows in %d batches: %d loaded, %d filtered in %v", copier.progress.Rows,
+		copier.progress.Batches, copier.progress.LoadedRows, copier.progress.FilteredRows, copier.progress.Elapsed)
+	return &copier.progress, nil
+}
+
+// copier loads the rows of an encoder in batches
+type copier struct {
+	client   *client.DorisLoadClient
+	opts     Options
+	loadOpts []client.LoadOption
+	enc      *encoder.Encoder
+
+	progress Progress
+	start    time.Time
+}
+
+// flush loads the encoded rows, if any
+func (c *copier) flush(ctx context.Context) error {
+	if c.enc.Rows() == 0 {
+		return nil
+	}
+
+	data := c.enc.Bytes()
+	response, err := c.client.LoadContext(ctx, c.enc.Reader(), c.loadOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to load batch %d: %w", c.progress.Batches+1, err)
+	}
+
+	c.progress.Batches++
+	c.progress.Bytes += int64(len(data))
+	c.progress.LoadedRows += response.Resp.NumberLoadedRows
+	c.progress.FilteredRows += int64(response.Resp.NumberFilteredRows)
+	c.progress.Elapsed = time.Since(c.start)
+	c.enc.Reset()
+
+	if c.opts.OnProgress != nil {
+		c.opts.OnProgress(c.progress)
+	}
+	return nil
+}
+
+// columnKind selects how the values a driver returns for a column are passed to the encoder
+type columnKind int
+
+const (
+	kindText columnKind = iota
+	kindDate
+	kindBinary
+)
+
+// columnKinds derives the kind of each result column from its database type
+func columnKinds(columns []*sql.ColumnType) []columnKind {
+	kinds := make([]columnKind, len(columns))
+	for i, col := range columns {
+		typeName := strings.ToUpper(col.DatabaseTypeName())
+		switch {
+		case typeName == "DATE":
+			kinds[i] = kindDate
+		case strings.Contains(typeName, "BLOB") || strings.Contains(typeName, "BINARY") || typeName == "BYTEA":
+			kinds[i] = kindBinary
+		}
+	}
+	return kinds
+}
+
+// convert maps a scanned value to the type the encoder writes for the column
+// Drivers return text, decimals included, as []byte, which the encoder would write as hex.
+func (k columnKind) convert(value any) any {
+	switch v := value.(type) {
+	case []byte:
+		if k == kindBinary {
+			return v
+		}
+		return string(v)
+	case time.Time:
+		if k == kindDate {
+			return encoder.Date(v)
+		}
+	}
+	return value
+}
@@ -0,0 +1,131 @@
+package sqlsource
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/config"
+	"github.com/bingquanzhao/go-doris-sdk/pkg/load/dorismock"
+)
+
+// fakeDriver serves the rows of fakeTable to any query
+type fakeDriver struct{}
+
+type fakeConn struct{}
+
+type fakeRows struct {
+	next int
+}
+
+var fakeColumns = []struct{ name, typeName string }{
+	{"id", "BIGINT"}, {"name", "VARCHAR"}, {"price", "DECIMAL"}, {"created", "DATETIME"},
+	{"day", "DATE"}, {"avatar", "BLOB"}, {"active", "BOOLEAN"},
+}
+
+var fakeTable = [][]driver.Value{
+	{int64(1), "Alice", []byte("10.50"), time.Date(2024, 3, 1, 12, 30, 0, 123000000, time.UTC),
+		time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), []byte{0xca, 0xfe}, true},
+	{int64(2), nil, nil, nil, nil, nil, false},
+	{int64(3), `Bob "B"`, []byte("0.01"), time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), []byte{}, true},
+}
+
+func init() {
+	sql.Register("sqlsource_fake", fakeDriver{})
+}
+
+func (fakeDriver) Open(string) (driver.Conn, error)         { return fakeConn{}, nil }
+func (fakeConn) Prepare(string) (driver.Stmt, error)        { return fakeConn{}, nil }
+func (fakeConn) Close() error                               { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                  { return nil, driver.ErrSkip }
+func (fakeConn) NumInput() int                              { return -1 }
+func (fakeConn) Exec([]driver.Value) (driver.Result, error) { return nil, driver.ErrSkip }
+func (fakeConn) Query([]driver.Value) (driver.Rows, error)  { return &fakeRows{}, nil }
+
+func (r *fakeRows) Columns() []string {
+	names := make([]string, len(fakeColumns))
+	for i, col := range fakeColumns {
+		names[i] = col.name
+	}
+	return names
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(index int) string { return fakeColumns[index].typeName }
+func (r *fakeRows) Close() error                                { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next == len(fakeTable) {
+		return io.EOF
+	}
+	copy(dest, fakeTable[r.next])
+	r.next++
+	return nil
+}
+
+func TestCopyFromSQLEncodesCSV(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithFormat(&config.CSVFormat{ColumnSeparator: "|", LineDelimiter: "\\n"}))
+
+	db, err := sql.Open("sqlsource_fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var updates []Progress
+	progress, err := CopyFromSQL(context.Background(), db, "SELECT * FROM users", c, Options{
+		BatchBytes: 1,
+		OnProgress: func(p Progress) { updates = append(updates, p) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if progress.Rows != 3 || progress.Batches != 3 || progress.LoadedRows != 3 || len(updates) != 3 {
+		t.Errorf("unexpected progress %+v after %d updates", progress, len(updates))
+	}
+
+	expected := []string{
+		"1|Alice|10.50|2024-03-01 12:30:00.123|2024-03-01|cafe|1",
+		`2|\N|\N|\N|\N|\N|0`,
+		`3|Bob "B"|0.01|2024-03-02 08:00:00|2024-03-02||1`,
+	}
+	if rows := server.Rows("test_db", "users"); strings.Join(rows, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected rows:\n%s", strings.Join(rows, "\n"))
+	}
+	if columns := server.Loads()[0].Headers.Get("columns"); columns != "`id`,`name`,`price`,`created`,`day`,`avatar`,`active`" {
+		t.Errorf("unexpected columns header %q", columns)
+	}
+}
+
+func TestCopyFromSQLEncodesJSON(t *testing.T) {
+	server := dorismock.NewServer()
+	defer server.Close()
+	c := server.NewClient(t, config.WithFormat(&config.JSONFormat{Type: config.JSONObjectLine}))
+
+	db, err := sql.Open("sqlsource_fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	progress, err := CopyFromSQL(context.Background(), db, "SELECT * FROM users", c, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if progress.Batches != 1 || progress.LoadedRows != 3 {
+		t.Errorf("unexpected progress %+v", progress)
+	}
+
+	rows := server.Rows("test_db", "users")
+	if len(rows) != 3 || !strings.Contains(rows[0], `"created":"2024-03-01 12:30:00.123"`) ||
+		!strings.Contains(rows[0], `"active":true`) || !strings.Contains(rows[1], `"name":null`) ||
+		!strings.Contains(rows[2], `"name":"Bob \"B\""`) {
+		t.Errorf("unexpected rows:\n%s", strings.Join(rows, "\n"))
+	}
+}
@@ -32,10 +32,13 @@ func buildHttpClient() *http.Client {
 		},
 	}
 
-	client := &http.Client{
+	return NewHttpClient(transport)
+}
+
+// NewHttpClient creates an HTTP client using transport with the SDK request timeout
+func NewHttpClient(transport http.RoundTripper) *http.Client {
+	return &http.Client{
 		Transport: transport,
 		Timeout:   120 * time.Second, // Total request timeout
 	}
-
-	return client
 }